@@ -2,4 +2,7 @@ module github.com/nicholas-boyson/wordsearch
 
 go 1.16
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/mattn/go-sqlite3 v1.14.17
+	github.com/stretchr/testify v1.7.0
+)
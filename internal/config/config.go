@@ -0,0 +1,190 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// default data directory, looked up next to the executable then in the working directory, and file names of each
+// entity within a data directory
+const (
+	DefaultDataDir        = "internal/source_data"
+	usersFileName         = "users.json"
+	ticketsFileName       = "tickets.json"
+	organizationsFileName = "organizations.json"
+)
+
+// environment variables read by FromEnv
+const (
+	EnvConfig        = "WORDSEARCH_CONFIG"
+	EnvDataDir       = "WORDSEARCH_DATA_DIR"
+	EnvUsers         = "WORDSEARCH_USERS"
+	EnvTickets       = "WORDSEARCH_TICKETS"
+	EnvOrganizations = "WORDSEARCH_ORGANIZATIONS"
+	EnvDatabase      = "WORDSEARCH_DATABASE"
+	EnvSchema        = "WORDSEARCH_SCHEMA"
+)
+
+// Config locations of the source data, an entity path may be a file or a directory holding the entity file.
+// When Database is set the data is searched in that SQLite database, imported from the source data when empty.
+// Schema is a JSON file describing more entities to load and search from their own data files
+type Config struct {
+	DataDir       string `json:"data_dir"`
+	Users         string `json:"users"`
+	Tickets       string `json:"tickets"`
+	Organizations string `json:"organizations"`
+	Database      string `json:"database"`
+	Schema        string `json:"schema"`
+}
+
+// Load reads a JSON config file, an empty path returns an empty config
+func Load(path string) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+	configFilePtr, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer configFilePtr.Close()
+
+	decoder := json.NewDecoder(configFilePtr)
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&cfg); err != nil && err != io.EOF {
+		// return error when the JSON is invalid and not empty
+		return Config{}, err
+	}
+	// relative paths in the config file are relative to the config file
+	dir := filepath.Dir(path)
+	cfg.DataDir = relativeTo(dir, cfg.DataDir)
+	cfg.Users = relativeTo(dir, cfg.Users)
+	cfg.Tickets = relativeTo(dir, cfg.Tickets)
+	cfg.Organizations = relativeTo(dir, cfg.Organizations)
+	cfg.Database = relativeTo(dir, cfg.Database)
+	cfg.Schema = relativeTo(dir, cfg.Schema)
+	return cfg, nil
+}
+
+// FromEnv returns the config set through environment variables
+func FromEnv(getenv func(string) string) Config {
+	return Config{
+		DataDir:       getenv(EnvDataDir),
+		Users:         getenv(EnvUsers),
+		Tickets:       getenv(EnvTickets),
+		Organizations: getenv(EnvOrganizations),
+		Database:      getenv(EnvDatabase),
+		Schema:        getenv(EnvSchema),
+	}
+}
+
+// Resolve combines the config sources, flags override environment variables which override the config file.
+// The config file is taken from configPath or when empty the WORDSEARCH_CONFIG environment variable. When a data
+// file is not located by any source the default data directory is found, so the binary runs from any directory
+func Resolve(flags Config, configPath string, getenv func(string) string) (Config, error) {
+	if configPath == "" {
+		configPath = getenv(EnvConfig)
+	}
+	file, err := Load(configPath)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := file.Merge(FromEnv(getenv)).Merge(flags)
+	if cfg.DataDir == "" && (cfg.Users == "" || cfg.Tickets == "" || cfg.Organizations == "") {
+		if cfg.DataDir, err = defaultDataDir(); err != nil {
+			return Config{}, err
+		}
+	}
+	return cfg, nil
+}
+
+// executable returns the path of the running binary
+var executable = os.Executable
+
+// defaultDataDir returns the default data directory next to the executable, or else in the working directory as
+// when started with go run from the repository. The error names the directories tried and how to set another
+func defaultDataDir() (string, error) {
+	var tried []string
+	if exe, err := executable(); err == nil {
+		// a binary started through a symlink finds the data directory next to the binary itself
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		tried = append(tried, filepath.Join(filepath.Dir(exe), DefaultDataDir))
+	}
+	if dir, err := filepath.Abs(DefaultDataDir); err == nil {
+		tried = append(tried, dir)
+	}
+	for _, dir := range tried {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no data directory at %s, set one with --data-dir, $%s or data_dir in the config file",
+		strings.Join(tried, " or "), EnvDataDir)
+}
+
+// Merge returns c with every value set in o taking precedence
+func (c Config) Merge(o Config) Config {
+	if o.DataDir != "" {
+		c.DataDir = o.DataDir
+	}
+	if o.Users != "" {
+		c.Users = o.Users
+	}
+	if o.Tickets != "" {
+		c.Tickets = o.Tickets
+	}
+	if o.Organizations != "" {
+		c.Organizations = o.Organizations
+	}
+	if o.Database != "" {
+		c.Database = o.Database
+	}
+	if o.Schema != "" {
+		c.Schema = o.Schema
+	}
+	return c
+}
+
+// UsersPath returns the users file to load
+func (c Config) UsersPath() string {
+	return c.entityPath(c.Users, usersFileName)
+}
+
+// TicketsPath returns the tickets file to load
+func (c Config) TicketsPath() string {
+	return c.entityPath(c.Tickets, ticketsFileName)
+}
+
+// OrganizationsPath returns the organizations file to load
+func (c Config) OrganizationsPath() string {
+	return c.entityPath(c.Organizations, organizationsFileName)
+}
+
+// entityPath resolves an entity path, falling back to the data directory when not set
+func (c Config) entityPath(path string, fileName string) string {
+	if path == "" {
+		dataDir := c.DataDir
+		if dataDir == "" {
+			dataDir = DefaultDataDir
+		}
+		return filepath.Join(dataDir, fileName)
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, fileName)
+	}
+	return path
+}
+
+// relativeTo joins a relative path onto dir
+func relativeTo(dir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// envMap returns a getenv func reading from the provided values
+func envMap(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		test   string
+		path   string
+		result Config
+		err    bool
+	}{
+		{
+			test:   "NoConfigFile",
+			path:   "",
+			result: Config{},
+		},
+		{
+			test: "GoodConfigFile",
+			path: "test_files/good_config.json",
+			result: Config{
+				DataDir: filepath.Join("test_files", "../../source_data"),
+				Users:   "/exports/users.json",
+			},
+		},
+		{
+			test: "UnknownField",
+			path: "test_files/invalid_config.json",
+			err:  true,
+		},
+		{
+			test: "MissingConfigFile",
+			path: "test_files/missing.json",
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := Load(tt.path)
+		assert.Equal(t, tt.err, err != nil, tt.test)
+		assert.Equal(t, tt.result, result, tt.test)
+	}
+}
+
+// useExecutable makes the executable a binary in a new directory, holding the default data directory when withData
+// is set, and returns that directory
+func useExecutable(t *testing.T, withData bool) string {
+	dir := t.TempDir()
+	if withData {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, DefaultDataDir), 0755))
+	}
+	executable = func() (string, error) {
+		return filepath.Join(dir, "wordsearch"), nil
+	}
+	t.Cleanup(func() {
+		executable = os.Executable
+	})
+	return dir
+}
+
+func TestResolve(t *testing.T) {
+	exeDir := useExecutable(t, true)
+	tests := []struct {
+		test       string
+		flags      Config
+		configPath string
+		env        map[string]string
+		result     Config
+	}{
+		{
+			test:   "Defaults",
+			result: Config{DataDir: filepath.Join(exeDir, DefaultDataDir)},
+		},
+		{
+			test:   "EveryFileSet",
+			env:    map[string]string{EnvUsers: "/env/users.json", EnvTickets: "/env/tickets.json", EnvOrganizations: "/env/organizations.json"},
+			result: Config{Users: "/env/users.json", Tickets: "/env/tickets.json", Organizations: "/env/organizations.json"},
+		},
+		{
+			test: "EnvOverridesConfigFile",
+			env: map[string]string{
+				EnvConfig:  "test_files/good_config.json",
+				EnvUsers:   "/env/users.json",
+				EnvTickets: "/env/tickets.json",
+			},
+			result: Config{
+				DataDir: filepath.Join("test_files", "../../source_data"),
+				Users:   "/env/users.json",
+				Tickets: "/env/tickets.json",
+			},
+		},
+		{
+			test:       "FlagsOverrideEnv",
+			flags:      Config{Tickets: "/flag/tickets.json", DataDir: "/flag", Database: "/flag/wordsearch.db"},
+			configPath: "test_files/good_config.json",
+			env: map[string]string{
+				EnvTickets:       "/env/tickets.json",
+				EnvOrganizations: "/env/organizations.json",
+				EnvDatabase:      "/env/wordsearch.db",
+				EnvSchema:        "/env/schema.json",
+			},
+			result: Config{
+				DataDir:       "/flag",
+				Users:         "/exports/users.json",
+				Tickets:       "/flag/tickets.json",
+				Organizations: "/env/organizations.json",
+				Database:      "/flag/wordsearch.db",
+				Schema:        "/env/schema.json",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := Resolve(tt.flags, tt.configPath, envMap(tt.env))
+		assert.Nil(t, err, tt.test)
+		assert.Equal(t, tt.result, result, tt.test)
+	}
+}
+
+func TestResolveMissingDataDir(t *testing.T) {
+	exeDir := useExecutable(t, false)
+	_, err := Resolve(Config{}, "", envMap(nil))
+	if assert.NotNil(t, err) {
+		// the working directory of the tests holds no data directory either
+		wd, _ := os.Getwd()
+		assert.Equal(t, "no data directory at "+filepath.Join(exeDir, DefaultDataDir)+" or "+filepath.Join(wd, DefaultDataDir)+
+			", set one with --data-dir, $WORDSEARCH_DATA_DIR or data_dir in the config file", err.Error())
+	}
+
+	// a data directory set by any source is not looked up
+	cfg, err := Resolve(Config{}, "", envMap(map[string]string{EnvDataDir: "/exports"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "/exports", cfg.DataDir)
+}
+
+func TestEntityPaths(t *testing.T) {
+	tests := []struct {
+		test          string
+		cfg           Config
+		users         string
+		tickets       string
+		organizations string
+	}{
+		{
+			test:          "Default",
+			cfg:           Config{},
+			users:         "internal/source_data/users.json",
+			tickets:       "internal/source_data/tickets.json",
+			organizations: "internal/source_data/organizations.json",
+		},
+		{
+			test:          "DataDir",
+			cfg:           Config{DataDir: "/exports"},
+			users:         "/exports/users.json",
+			tickets:       "/exports/tickets.json",
+			organizations: "/exports/organizations.json",
+		},
+		{
+			test:          "EntityFileOverridesDataDir",
+			cfg:           Config{DataDir: "/exports", Users: "/other/people.json"},
+			users:         "/other/people.json",
+			tickets:       "/exports/tickets.json",
+			organizations: "/exports/organizations.json",
+		},
+		{
+			test:          "EntityDirectory",
+			cfg:           Config{Organizations: "test_files"},
+			users:         "internal/source_data/users.json",
+			tickets:       "internal/source_data/tickets.json",
+			organizations: "test_files/organizations.json",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.users, tt.cfg.UsersPath(), tt.test)
+		assert.Equal(t, tt.tickets, tt.cfg.TicketsPath(), tt.test)
+		assert.Equal(t, tt.organizations, tt.cfg.OrganizationsPath(), tt.test)
+	}
+}
@@ -0,0 +1,60 @@
+package display
+
+import (
+	"fmt"
+
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+)
+
+// FieldMatch the records of a group holding a searched value in one of its fields, one summary line per record
+type FieldMatch struct {
+	Group   string
+	Field   string
+	Records []string
+}
+
+// DisplayFieldMatches generate the display of a search across every group, the fields holding the value are listed
+// under their group with the records holding it
+func DisplayFieldMatches(matches []FieldMatch) {
+	if len(matches) > 0 {
+		fmt.Println(displayFieldMatches(matches))
+	} else {
+		NoResultFound()
+	}
+}
+func displayFieldMatches(matches []FieldMatch) string {
+	result := ""
+	group := ""
+	for _, m := range matches {
+		if m.Group != group {
+			group = m.Group
+			result = result + group + "\n"
+		}
+		records := "records"
+		if len(m.Records) == 1 {
+			records = "record"
+		}
+		result = result + fmt.Sprintf("  %s: %d %s\n", m.Field, len(m.Records), records)
+		for _, record := range m.Records {
+			result = result + fmt.Sprintf("    %s\n", record)
+		}
+	}
+	return result
+}
+
+// OrganizationSummary the id and name of an organization on one line
+func OrganizationSummary(org organizations.Organization) string {
+	return fmt.Sprintf("Organization Id: %d | Organization Name: %s", org.Id, org.Name)
+}
+
+// TicketSummary the id, subject and status of a ticket on one line
+func TicketSummary(ticket tickets.Ticket) string {
+	return fmt.Sprintf("Ticket Id: %s | Ticket Subject %s | Ticket Status %s", ticket.Id, ticket.Subject, ticket.Status)
+}
+
+// UserSummary the id, name and email of a user on one line
+func UserSummary(user users.User) string {
+	return fmt.Sprintf("User Id: %d | User Name: %s | User Email: %s", user.Id, user.Name, user.Email)
+}
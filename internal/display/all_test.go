@@ -0,0 +1,34 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayFieldMatches(t *testing.T) {
+	matches := []FieldMatch{
+		{Group: "Users", Field: "_id", Records: []string{UserSummary(users.User{Id: 2, Name: "Cross Barlow", Email: "jennifergaines@flotonic.com"})}},
+		{Group: "Tickets", Field: "submitter_id", Records: []string{
+			TicketSummary(tickets.Ticket{Id: "436bf9b0", Subject: "A Catastrophe in Korea", Status: "pending"}),
+			TicketSummary(tickets.Ticket{Id: "1a227508", Subject: "A Catastrophe in Micronesia", Status: "hold"}),
+		}},
+		{Group: "Tickets", Field: "assignee_id", Records: []string{TicketSummary(tickets.Ticket{Id: "2217c7dc", Subject: "A Drama in Indonesia", Status: "open"})}},
+		{Group: "Organizations", Field: "_id", Records: []string{OrganizationSummary(organizations.Organization{Id: 2, Name: "Enthaze"})}},
+	}
+	assert.Equal(t, "Users\n"+
+		"  _id: 1 record\n"+
+		"    User Id: 2 | User Name: Cross Barlow | User Email: jennifergaines@flotonic.com\n"+
+		"Tickets\n"+
+		"  submitter_id: 2 records\n"+
+		"    Ticket Id: 436bf9b0 | Ticket Subject A Catastrophe in Korea | Ticket Status pending\n"+
+		"    Ticket Id: 1a227508 | Ticket Subject A Catastrophe in Micronesia | Ticket Status hold\n"+
+		"  assignee_id: 1 record\n"+
+		"    Ticket Id: 2217c7dc | Ticket Subject A Drama in Indonesia | Ticket Status open\n"+
+		"Organizations\n"+
+		"  _id: 1 record\n"+
+		"    Organization Id: 2 | Organization Name: Enthaze\n", displayFieldMatches(matches))
+}
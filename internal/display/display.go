@@ -2,8 +2,11 @@ package display
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
 	"github.com/nicholas-boyson/wordsearch/internal/organizations"
 	"github.com/nicholas-boyson/wordsearch/internal/tickets"
 	"github.com/nicholas-boyson/wordsearch/internal/users"
@@ -22,7 +25,7 @@ func SelectSearchOptions() {
 	fmt.Println(selectSearchOptions())
 }
 func selectSearchOptions() string {
-	return "          Select search options:\n          * Press 1 to search Zendesk\n          * Press 2 to view a list of searchable fields\n          * Type 'quit' to exit"
+	return "          Select search options:\n          * Press 1 to search Zendesk\n          * Press 2 to view a list of searchable fields\n          * Press 3 to export the last search results\n          * Press 4 to search combining fields with AND, OR and NOT\n          * Type 'quit' to exit"
 }
 
 // ListSearchableFields function to display the searchable fields
@@ -30,37 +33,64 @@ func ListSearchableFields() {
 	fmt.Println(listSearchableFields())
 }
 func listSearchableFields() string {
-	searchFields := "Searchable fields\n"
-	searchFields = searchFields + "| Users           | Tickets         | Organizations  |\n"
-	searchFields = searchFields + "|-----------------|-----------------|----------------|\n"
-	searchFields = searchFields + "| _id             | _id             | _id            |\n"
-	searchFields = searchFields + "| url             | url             | url            |\n"
-	searchFields = searchFields + "| external_id     | external_id     | external_id    |\n"
-	searchFields = searchFields + "| name            | created_at      | name           |\n"
-	searchFields = searchFields + "| alias           | type            | domain_names   |\n"
-	searchFields = searchFields + "| created_at      | subject         | created_at     |\n"
-	searchFields = searchFields + "| active          | description     | details        |\n"
-	searchFields = searchFields + "| verified        | priority        | shared_tickets |\n"
-	searchFields = searchFields + "| shared          | status          | tags           |\n"
-	searchFields = searchFields + "| locale          | submitter_id    |                |\n"
-	searchFields = searchFields + "| timezone        | assignee_id     |                |\n"
-	searchFields = searchFields + "| last_login_at   | organization_id |                |\n"
-	searchFields = searchFields + "| email           | tags            |                |\n"
-	searchFields = searchFields + "| phone           | has_incidents   |                |\n"
-	searchFields = searchFields + "| signature       | due_at          |                |\n"
-	searchFields = searchFields + "| organization_id | via             |                |\n"
-	searchFields = searchFields + "| tags            |                 |                |\n"
-	searchFields = searchFields + "| suspended       |                 |                |\n"
-	searchFields = searchFields + "| role            |                 |                |\n"
+	columns := []struct {
+		header string
+		names  []string
+	}{
+		{header: "Users", names: users.Fields().Names()},
+		{header: "Tickets", names: tickets.Fields().Names()},
+		{header: "Organizations", names: organizations.Fields().Names()},
+	}
+	for _, t := range entity.Types() {
+		columns = append(columns, struct {
+			header string
+			names  []string
+		}{header: t.Label(), names: t.Fields().Names()})
+	}
+	// each column is as wide as its longest field name or header
+	widths := make([]int, len(columns))
+	rowCount := 0
+	for i, c := range columns {
+		widths[i] = len(c.header)
+		for _, name := range c.names {
+			if len(name) > widths[i] {
+				widths[i] = len(name)
+			}
+		}
+		if len(c.names) > rowCount {
+			rowCount = len(c.names)
+		}
+	}
+	header, separator := "", ""
+	for i, c := range columns {
+		header = header + fmt.Sprintf("| %-*s ", widths[i], c.header)
+		separator = separator + "|" + strings.Repeat("-", widths[i]+2)
+	}
+	searchFields := "Searchable fields\n" + header + "|\n" + separator + "|\n"
+	for row := 0; row < rowCount; row++ {
+		for i, c := range columns {
+			name := ""
+			if row < len(c.names) {
+				name = c.names[row]
+			}
+			searchFields = searchFields + fmt.Sprintf("| %-*s ", widths[i], name)
+		}
+		searchFields = searchFields + "|\n"
+	}
 	return searchFields
 }
 
-// SelectGroupOptions display group search options to user
+// SelectGroupOptions display group search options to user, the schema entities follow the built in groups and
+// All searches every group
 func SelectGroupOptions() {
 	fmt.Println(selectGroupOptions())
 }
 func selectGroupOptions() string {
-	return "Select 1) Users or 2) Tickets or 3) Organizations"
+	options := "Select 1) Users or 2) Tickets or 3) Organizations"
+	for i, t := range entity.Types() {
+		options = options + fmt.Sprintf(" or %d) %s", i+4, t.Label())
+	}
+	return options + " or 0) All"
 }
 
 // EnterSearchTerm display enter search term to user
@@ -68,7 +98,47 @@ func EnterSearchTerm() {
 	fmt.Println(enterSearchTerm())
 }
 func enterSearchTerm() string {
-	return "Enter search term"
+	return "Enter search term or * to search every field"
+}
+
+// EnterMatchMode display enter match mode to user
+func EnterMatchMode() {
+	fmt.Println(enterMatchMode())
+}
+func enterMatchMode() string {
+	return "Enter match mode exact, ignorecase, prefix, substring, glob, text, regex or range (blank for exact)"
+}
+
+// InvalidMatchMode display invalid match mode to user
+func InvalidMatchMode() {
+	fmt.Println(invalidMatchMode())
+}
+func invalidMatchMode() string {
+	return "Invalid match mode"
+}
+
+// InvalidSearchValue display the reason a regular expression or range search value is invalid to user
+func InvalidSearchValue(err error) {
+	fmt.Println(invalidSearchValue(err))
+}
+func invalidSearchValue(err error) string {
+	return fmt.Sprintf("Invalid search value: %s", err)
+}
+
+// EnterQuery display enter query to user
+func EnterQuery() {
+	fmt.Println(enterQuery())
+}
+func enterQuery() string {
+	return "Enter query e.g. status:pending AND (priority:high OR priority:urgent) NOT via:chat"
+}
+
+// InvalidQuery display the reason a query is invalid to user
+func InvalidQuery(err error) {
+	fmt.Println(invalidQuery(err))
+}
+func invalidQuery(err error) string {
+	return fmt.Sprintf("Invalid query: %s", err)
 }
 
 // EnterSearchValue display enter search value to user
@@ -95,6 +165,62 @@ func invalidSearchTerm() string {
 	return "Invalid search term"
 }
 
+// EnterExportFormat display enter export format to user
+func EnterExportFormat() {
+	fmt.Println(enterExportFormat())
+}
+func enterExportFormat() string {
+	return "Enter export format csv, tsv or json"
+}
+
+// EnterExportFile display enter export file to user
+func EnterExportFile() {
+	fmt.Println(enterExportFile())
+}
+func enterExportFile() string {
+	return "Enter export file, leave blank to display the export"
+}
+
+// InvalidExportFormat display invalid export format to user
+func InvalidExportFormat() {
+	fmt.Println(invalidExportFormat())
+}
+func invalidExportFormat() string {
+	return "Invalid export format"
+}
+
+// NoSearchToExport display no search to export to user
+func NoSearchToExport() {
+	fmt.Println(noSearchToExport())
+}
+func noSearchToExport() string {
+	return "No search results to export, run a search first"
+}
+
+// ExportComplete display the export file to user
+func ExportComplete(path string) {
+	fmt.Println(exportComplete(path))
+}
+func exportComplete(path string) string {
+	return fmt.Sprintf("Search results exported to %s", path)
+}
+
+// ExportFailed display the export error to user
+func ExportFailed(err error) {
+	fmt.Println(exportFailed(err))
+}
+func exportFailed(err error) string {
+	return fmt.Sprintf("Export failed: %s", err.Error())
+}
+
+// SearchFailed display the error the data failed to be searched with, the results are not shown
+func SearchFailed(err error) {
+	fmt.Println(searchFailed(err))
+}
+func searchFailed(err error) string {
+	return fmt.Sprintf("Search failed: %s", err.Error())
+}
+
 // DisplayOrganizations generate organization search result display
 func DisplayOrganizations(orgList []organizations.Organization, ticketList []tickets.Ticket, userList []users.User) {
 	if len(orgList) > 0 {
@@ -119,17 +245,7 @@ func displayOrganizationList(orgList []organizations.Organization) string {
 func displayOrganizationDetails(org organizations.Organization, ticketList []tickets.Ticket, userList []users.User) string {
 	result := fmt.Sprintf("Organization %s (Id %d)\n", org.Name, org.Id)
 	result = result + "Details:\n"
-	result = result + fmt.Sprintf("%-16s%s\n", "URL:", org.URL)
-	result = result + fmt.Sprintf("%-16s%s\n", "External Id:", org.ExternalId)
-	result = result + fmt.Sprintf("%-16s%s\n", "Created At:", org.CreatedAt)
-	result = result + fmt.Sprintf("%-16s%v\n", "Shared Tickets:", org.SharedTickets)
-	result = result + fmt.Sprintf("%-16s%s\n", "Details:", org.Details)
-	for i, dm := range org.DomainNames {
-		result = result + fmt.Sprintf("Domain Name %d: %s\n", i+1, dm)
-	}
-	for i, tag := range org.Tags {
-		result = result + fmt.Sprintf("Tag %d: %s\n", i+1, tag)
-	}
+	result = result + displayFields(organizations.Fields(), org, nil, "name")
 	for i, user := range userList {
 		result = result + fmt.Sprintf("User %d: User Id: %-4d | User Name: %-30s | User Phone: %-15s | User Email: %-20s\n", i+1, user.Id, user.Name, user.Phone, user.Email)
 	}
@@ -139,102 +255,180 @@ func displayOrganizationDetails(org organizations.Organization, ticketList []tic
 	return result
 }
 
-// DisplayTickets generate tickets search result display
-func DisplayTickets(ticketList []tickets.Ticket, org organizations.Organization) {
+// DisplayTickets generate tickets search result display, a single ticket is shown with its linked submitter and assignee
+// and multiple tickets are grouped by their organization
+func DisplayTickets(ticketList []tickets.Ticket, orgList []organizations.Organization, userList []users.User) {
 	if len(ticketList) > 0 {
 		if len(ticketList) == 1 {
-			fmt.Println(displayTicketDetails(ticketList[0], org))
+			fmt.Println(displayTicketDetails(ticketList[0], linkedOrganization(orgList, ticketList[0].OrganizationId), userList))
 		} else {
-			fmt.Println(displayTicketsList(ticketList, org))
+			fmt.Println(displayTicketsList(ticketList, orgList))
 		}
 	} else {
 		NoResultFound()
 	}
 }
-func displayTicketsList(ticketList []tickets.Ticket, org organizations.Organization) string {
+func displayTicketsList(ticketList []tickets.Ticket, orgList []organizations.Organization) string {
 	result := "Multipe tickets found\n"
-	result = result + fmt.Sprintf("%-50s|%-50s|%-100s\n", "Ticket Id", "Ticket subject", "Ticket Description")
-	result = result + fmt.Sprintf("%-50s|%-50s|%-100s\n", strings.Repeat("-", 50), strings.Repeat("-", 50), strings.Repeat("-", 100))
+	separator := fmt.Sprintf("%-50s|%-50s|%-100s\n", strings.Repeat("-", 50), strings.Repeat("-", 50), strings.Repeat("-", 100))
+	header := fmt.Sprintf("%-50s|%-50s|%-100s\n", "Ticket Id", "Ticket subject", "Ticket Description") + separator
+	orgIds := make([]int, 0, len(ticketList))
+	rows := make([]string, 0, len(ticketList))
 	for _, ticket := range ticketList {
-		result = result + fmt.Sprintf("%-50s|%-50s|%-100s\n", ticket.Id, ticket.Subject, ticket.Description)
+		orgIds = append(orgIds, ticket.OrganizationId)
+		rows = append(rows, fmt.Sprintf("%-50s|%-50s|%-100s\n", ticket.Id, ticket.Subject, ticket.Description))
 	}
-	result = result + fmt.Sprintf("%-50s|%-50s|%-100s\n", strings.Repeat("-", 50), strings.Repeat("-", 50), strings.Repeat("-", 100))
+	return result + displayByOrganization(orgList, orgIds, header, rows, separator)
+}
+func displayTicketDetails(ticket tickets.Ticket, org organizations.Organization, userList []users.User) string {
+	result := fmt.Sprintf("Ticket %s (Id %s)\n", ticket.Subject, ticket.Id)
+	result = result + "Details:\n"
+	result = result + displayFields(tickets.Fields(), ticket, userList, "subject")
 	if org.Id != 0 {
 		result = result + displayOrganizationDetails(org, nil, nil)
 	}
 	return result
 }
-func displayTicketDetails(ticket tickets.Ticket, org organizations.Organization) string {
-	result := fmt.Sprintf("Ticket %s (Id %s)\n", ticket.Subject, ticket.Id)
-	result = result + "Details:\n"
-	result = result + fmt.Sprintf("%-16s%s\n", "Description:", ticket.Description)
-	result = result + fmt.Sprintf("%-16s%s\n", "URL:", ticket.URL)
-	result = result + fmt.Sprintf("%-16s%s\n", "External Id:", ticket.ExternalId)
-	result = result + fmt.Sprintf("%-16s%s\n", "Created At:", ticket.CreatedAt)
-	result = result + fmt.Sprintf("%-16s%v\n", "Organization Id:", ticket.OrganizationId)
-	result = result + fmt.Sprintf("%-16s%s\n", "Via:", ticket.Via)
-	result = result + fmt.Sprintf("%-16s%s\n", "Type:", ticket.Type)
-	result = result + fmt.Sprintf("%-16s%s\n", "Priority:", ticket.Priority)
-	result = result + fmt.Sprintf("%-16s%s\n", "Status:", ticket.Status)
-	result = result + fmt.Sprintf("%-16s%d\n", "Submitter Id:", ticket.SubmitterId)
-	result = result + fmt.Sprintf("%-16s%d\n", "Assignee Id:", ticket.AssigneeId)
-	result = result + fmt.Sprintf("%-16s%v\n", "Has Incidents:", ticket.HasIncidents)
-	result = result + fmt.Sprintf("%-16s%s\n", "Due At:", ticket.DueAt)
-	for i, tag := range ticket.Tags {
-		result = result + fmt.Sprintf("Tag %d: %s\n", i+1, tag)
+
+// displayFields the fields of a record in the order of its registry, except the id and the fields already in the heading.
+// A list shows a line per value, and a field referring to a user is followed by the linked user from userList
+func displayFields(registry *fields.Registry, record interface{}, userList []users.User, heading ...string) string {
+	result := ""
+	for _, f := range registry.Fields() {
+		if f.Name == "_id" || contains(heading, f.Name) {
+			continue
+		}
+		label := fieldLabel(f.Name)
+		values := registry.Values(record, f.Name)
+		if f.Kind == fields.List {
+			for i, value := range values {
+				result = result + fmt.Sprintf("%s %d: %s\n", strings.TrimSuffix(label, "s"), i+1, value)
+			}
+			continue
+		}
+		result = result + fmt.Sprintf("%-16s%s\n", label+":", values[0])
+		if f.Ref == entityUsers {
+			id, _ := strconv.Atoi(values[0])
+			if user := findUser(userList, id); user != nil {
+				result = result + fmt.Sprintf("%-16s%s\n", fieldLabel(relationName(f.Name))+":", displayLinkedUser(*user))
+			}
+		}
 	}
-	if org.Id != 0 {
-		result = result + displayOrganizationDetails(org, nil, nil)
+	return result
+}
+
+// fieldLabel the title of a field name, external_id is shown as External Id
+func fieldLabel(name string) string {
+	words := strings.Split(name, "_")
+	for i, word := range words {
+		if word == "url" {
+			words[i] = "URL"
+		} else {
+			words[i] = strings.Title(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// contains returns if names holds name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// linkedOrganization returns the organization with the id, an empty organization when it was not linked
+func linkedOrganization(orgList []organizations.Organization, id int) organizations.Organization {
+	if org := findOrganization(orgList, id); org != nil {
+		return *org
+	}
+	return organizations.Organization{}
+}
+
+// displayByOrganization displays the rows in a grid per organization, orgIds holds the organization of each row.
+// Rows without a linked organization are listed last, and when every row has the same organization its details follow the grid
+func displayByOrganization(orgList []organizations.Organization, orgIds []int, header string, rows []string, separator string) string {
+	var groups []organizations.Organization
+	for _, org := range orgList {
+		for _, id := range orgIds {
+			if id == org.Id {
+				groups = append(groups, org)
+				break
+			}
+		}
+	}
+	for _, id := range orgIds {
+		if findOrganization(orgList, id) == nil {
+			groups = append(groups, organizations.Organization{})
+			break
+		}
+	}
+	result := ""
+	for _, org := range groups {
+		if len(groups) > 1 {
+			if org.Id != 0 {
+				result = result + fmt.Sprintf("Organization %s (Id %d)\n", org.Name, org.Id)
+			} else {
+				result = result + "No linked organization\n"
+			}
+		}
+		result = result + header
+		for i, id := range orgIds {
+			if (org.Id != 0 && id == org.Id) || (org.Id == 0 && findOrganization(orgList, id) == nil) {
+				result = result + rows[i]
+			}
+		}
+		result = result + separator
+	}
+	if len(groups) == 1 && groups[0].Id != 0 {
+		result = result + displayOrganizationDetails(groups[0], nil, nil)
 	}
 	return result
 }
 
-// DisplayUsers generate users search result display
-func DisplayUsers(userList []users.User, org organizations.Organization) {
+// displayLinkedUser the name, email and role of a user linked to a ticket
+func displayLinkedUser(user users.User) string {
+	return fmt.Sprintf("%s | Email: %s | Role: %s", user.Name, user.Email, user.Role)
+}
+
+// DisplayUsers generate users search result display, a single user is shown with the tickets they submitted and are assigned
+// and multiple users are grouped by their organization
+func DisplayUsers(userList []users.User, orgList []organizations.Organization, ticketList []tickets.Ticket) {
 	if len(userList) > 0 {
 		if len(userList) == 1 {
-			fmt.Println(displayUserDetails(userList[0], org))
+			fmt.Println(displayUserDetails(userList[0], linkedOrganization(orgList, userList[0].OrganizationId), ticketList))
 		} else {
-			fmt.Println(displayUsersList(userList, org))
+			fmt.Println(displayUsersList(userList, orgList))
 		}
 	} else {
 		NoResultFound()
 	}
 }
-func displayUsersList(userList []users.User, org organizations.Organization) string {
+func displayUsersList(userList []users.User, orgList []organizations.Organization) string {
 	result := "Multipe users found\n"
-	result = result + fmt.Sprintf("%-20s|%-20s|%-20s\n", "User Id", "User Name", "User Active")
-	result = result + fmt.Sprintf("%-20s|%-20s|%-20s\n", strings.Repeat("-", 20), strings.Repeat("-", 20), strings.Repeat("-", 20))
+	separator := fmt.Sprintf("%-20s|%-20s|%-20s\n", strings.Repeat("-", 20), strings.Repeat("-", 20), strings.Repeat("-", 20))
+	header := fmt.Sprintf("%-20s|%-20s|%-20s\n", "User Id", "User Name", "User Active") + separator
+	orgIds := make([]int, 0, len(userList))
+	rows := make([]string, 0, len(userList))
 	for _, user := range userList {
-		result = result + fmt.Sprintf("%-20d|%-20s|%-20v\n", user.Id, user.Name, user.Active)
+		orgIds = append(orgIds, user.OrganizationId)
+		rows = append(rows, fmt.Sprintf("%-20d|%-20s|%-20v\n", user.Id, user.Name, user.Active))
 	}
-	result = result + fmt.Sprintf("%-20s|%-20s|%-20s\n", strings.Repeat("-", 20), strings.Repeat("-", 20), strings.Repeat("-", 20))
-	if org.Id != 0 {
-		result = result + displayOrganizationDetails(org, nil, nil)
-	}
-	return result
+	return result + displayByOrganization(orgList, orgIds, header, rows, separator)
 }
-func displayUserDetails(user users.User, org organizations.Organization) string {
+func displayUserDetails(user users.User, org organizations.Organization, ticketList []tickets.Ticket) string {
 	result := fmt.Sprintf("User %s (Alias %s) (Id %d)\n", user.Name, user.Alias, user.Id)
 	result = result + "Details:\n"
-	result = result + fmt.Sprintf("%-16s%s\n", "URL:", user.URL)
-	result = result + fmt.Sprintf("%-16s%s\n", "External Id:", user.ExternalId)
-	result = result + fmt.Sprintf("%-16s%s\n", "Email:", user.Email)
-	result = result + fmt.Sprintf("%-16s%s\n", "Phone:", user.Phone)
-	result = result + fmt.Sprintf("%-16s%s\n", "Signature:", user.Signature)
-	result = result + fmt.Sprintf("%-16s%s\n", "Created At:", user.CreatedAt)
-	result = result + fmt.Sprintf("%-16s%d\n", "Organization Id:", user.OrganizationId)
-	result = result + fmt.Sprintf("%-16s%v\n", "Active:", user.Active)
-	result = result + fmt.Sprintf("%-16s%s\n", "Role:", user.Role)
-	result = result + fmt.Sprintf("%-16s%v\n", "Verified:", user.Verified)
-	result = result + fmt.Sprintf("%-16s%v\n", "Shared:", user.Shared)
-	result = result + fmt.Sprintf("%-16s%s\n", "Local:", user.Locale)
-	result = result + fmt.Sprintf("%-16s%s\n", "Timezone:", user.Timezone)
-	result = result + fmt.Sprintf("%-16s%s\n", "Last Login At:", user.LastLoginAt)
-	result = result + fmt.Sprintf("%-16s%v\n", "Suspended:", user.Suspended)
-
-	for i, tag := range user.Tags {
-		result = result + fmt.Sprintf("Tag %d: %s\n", i+1, tag)
+	result = result + displayFields(users.Fields(), user, nil, "name", "alias")
+	submitted, assigned := userTickets(user, ticketList)
+	for i, ticket := range submitted {
+		result = result + fmt.Sprintf("Submitted Ticket %d: Ticket Id: %s | Ticket Subject %s | Ticket Status %s\n", i+1, ticket.Id, ticket.Subject, ticket.Status)
+	}
+	for i, ticket := range assigned {
+		result = result + fmt.Sprintf("Assigned Ticket %d: Ticket Id: %s | Ticket Subject %s | Ticket Status %s\n", i+1, ticket.Id, ticket.Subject, ticket.Status)
 	}
 	if org.Id != 0 {
 		result = result + displayOrganizationDetails(org, nil, nil)
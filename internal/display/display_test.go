@@ -1,8 +1,13 @@
 package display
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,7 +18,7 @@ func TestWelcome(t *testing.T) {
 
 func TestSelectSearchOptions(t *testing.T) {
 	selectSearchOptions := selectSearchOptions()
-	assert.Equal(t, "          Select search options:\n          * Press 1 to search Zendesk\n          * Press 2 to view a list of searchable fields\n          * Type 'quit' to exit", selectSearchOptions)
+	assert.Equal(t, "          Select search options:\n          * Press 1 to search Zendesk\n          * Press 2 to view a list of searchable fields\n          * Press 3 to export the last search results\n          * Press 4 to search combining fields with AND, OR and NOT\n          * Type 'quit' to exit", selectSearchOptions)
 }
 
 func TestListSearchableFields(t *testing.T) {
@@ -46,12 +51,32 @@ func TestListSearchableFields(t *testing.T) {
 
 func TestSelectGroupOptions(t *testing.T) {
 	selectGroupOptions := selectGroupOptions()
-	assert.Equal(t, "Select 1) Users or 2) Tickets or 3) Organizations", selectGroupOptions)
+	assert.Equal(t, "Select 1) Users or 2) Tickets or 3) Organizations or 0) All", selectGroupOptions)
 }
 
 func TestEnterSearchTerm(t *testing.T) {
 	enterTerm := enterSearchTerm()
-	assert.Equal(t, "Enter search term", enterTerm)
+	assert.Equal(t, "Enter search term or * to search every field", enterTerm)
+}
+
+func TestEnterMatchMode(t *testing.T) {
+	assert.Equal(t, "Enter match mode exact, ignorecase, prefix, substring, glob, text, regex or range (blank for exact)", enterMatchMode())
+}
+
+func TestInvalidMatchMode(t *testing.T) {
+	assert.Equal(t, "Invalid match mode", invalidMatchMode())
+}
+
+func TestInvalidSearchValue(t *testing.T) {
+	assert.Equal(t, "Invalid search value: missing closing )", invalidSearchValue(errors.New("missing closing )")))
+}
+
+func TestEnterQuery(t *testing.T) {
+	assert.Equal(t, "Enter query e.g. status:pending AND (priority:high OR priority:urgent) NOT via:chat", enterQuery())
+}
+
+func TestInvalidQuery(t *testing.T) {
+	assert.Equal(t, "Invalid query: missing closing parenthesis", invalidQuery(errors.New("missing closing parenthesis")))
 }
 
 func TestEnterSearchValue(t *testing.T) {
@@ -68,3 +93,103 @@ func TestInvalidSearchTerm(t *testing.T) {
 	msg := invalidSearchTerm()
 	assert.Equal(t, "Invalid search term", msg)
 }
+
+func TestEnterExportFormat(t *testing.T) {
+	assert.Equal(t, "Enter export format csv, tsv or json", enterExportFormat())
+}
+
+func TestEnterExportFile(t *testing.T) {
+	assert.Equal(t, "Enter export file, leave blank to display the export", enterExportFile())
+}
+
+func TestInvalidExportFormat(t *testing.T) {
+	assert.Equal(t, "Invalid export format", invalidExportFormat())
+}
+
+func TestNoSearchToExport(t *testing.T) {
+	assert.Equal(t, "No search results to export, run a search first", noSearchToExport())
+}
+
+func TestExportComplete(t *testing.T) {
+	assert.Equal(t, "Search results exported to out.csv", exportComplete("out.csv"))
+}
+
+func TestExportFailed(t *testing.T) {
+	assert.Equal(t, "Export failed: disk full", exportFailed(errors.New("disk full")))
+}
+
+func TestSearchFailed(t *testing.T) {
+	assert.Equal(t, "Search failed: database is locked", searchFailed(errors.New("database is locked")))
+}
+
+func TestDisplayTicketDetailsLinkedUsers(t *testing.T) {
+	ticket := tickets.Ticket{Id: "a", Subject: "A Catastrophe in Korea (North)", SubmitterId: 38, AssigneeId: 24}
+	userList := []users.User{
+		{Id: 38, Name: "Elma Castro", Email: "elmacastro@flotonic.com", Role: "agent"},
+		{Id: 24, Name: "Harris Côpeland", Email: "harriscopeland@flotonic.com", Role: "admin"},
+	}
+	result := displayTicketDetails(ticket, organizations.Organization{}, userList)
+	assert.Contains(t, result, "Submitter Id:   38\nSubmitter:      Elma Castro | Email: elmacastro@flotonic.com | Role: agent\n")
+	assert.Contains(t, result, "Assignee Id:    24\nAssignee:       Harris Côpeland | Email: harriscopeland@flotonic.com | Role: admin\n")
+
+	result = displayTicketDetails(ticket, organizations.Organization{}, nil)
+	assert.NotContains(t, result, "Submitter:")
+	assert.NotContains(t, result, "Assignee:")
+}
+
+func TestDisplayUserDetailsLinkedTickets(t *testing.T) {
+	user := users.User{Id: 24, Name: "Harris Côpeland"}
+	ticketList := []tickets.Ticket{
+		{Id: "a", Subject: "A Catastrophe in Korea (North)", Status: "pending", SubmitterId: 38, AssigneeId: 24},
+		{Id: "b", Subject: "A Drama in Portugal", Status: "open", SubmitterId: 24, AssigneeId: 24},
+	}
+	result := displayUserDetails(user, organizations.Organization{}, ticketList)
+	assert.Contains(t, result, "Submitted Ticket 1: Ticket Id: b | Ticket Subject A Drama in Portugal | Ticket Status open\n")
+	assert.Contains(t, result, "Assigned Ticket 1: Ticket Id: a | Ticket Subject A Catastrophe in Korea (North) | Ticket Status pending\n")
+	assert.Contains(t, result, "Assigned Ticket 2: Ticket Id: b | Ticket Subject A Drama in Portugal | Ticket Status open\n")
+}
+
+func TestDisplayUsersListByOrganization(t *testing.T) {
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}}
+	userList := []users.User{
+		{Id: 1, Name: "Francisca Rasmussen", OrganizationId: 102},
+		{Id: 2, Name: "Cross Barlow", OrganizationId: 101},
+		{Id: 3, Name: "Ingrid Wagner", OrganizationId: 103},
+		{Id: 4, Name: "Rose Newton", OrganizationId: 102},
+	}
+	result := displayUsersList(userList, orgList)
+	enthaze := strings.Index(result, "Organization Enthaze (Id 101)\n")
+	nutralab := strings.Index(result, "Organization Nutralab (Id 102)\n")
+	unlinked := strings.Index(result, "No linked organization\n")
+	assert.True(t, enthaze >= 0 && enthaze < nutralab && nutralab < unlinked)
+	assert.True(t, strings.Index(result, "Cross Barlow") < nutralab)
+	assert.True(t, strings.Index(result, "Rose Newton") > nutralab && strings.Index(result, "Rose Newton") < unlinked)
+	assert.True(t, strings.Index(result, "Ingrid Wagner") > unlinked)
+
+	result = displayUsersList(userList[1:2], orgList)
+	assert.True(t, strings.HasPrefix(result, "Multipe users found\nUser Id"))
+	assert.Contains(t, result, "Organization Enthaze (Id 101)\nDetails:")
+}
+
+func TestDisplayDetailsFromRegistry(t *testing.T) {
+	org := organizations.Organization{Id: 101, Name: "Enthaze", URL: "http://enthaze", DomainNames: []string{"kage.com", "ecratic.com"}, SharedTickets: true}
+	assert.Equal(t, "Organization Enthaze (Id 101)\n"+
+		"Details:\n"+
+		"URL:            http://enthaze\n"+
+		"External Id:    \n"+
+		"Domain Name 1: kage.com\n"+
+		"Domain Name 2: ecratic.com\n"+
+		"Created At:     \n"+
+		"Details:        \n"+
+		"Shared Tickets: true\n", displayOrganizationDetails(org, nil, nil))
+
+	// every tagged field of a user is shown, not only the ones listed by hand before
+	result := displayUserDetails(users.User{Id: 1, Name: "Rose Newton", Locale: "en-AU", OrganizationId: 102}, organizations.Organization{}, nil)
+	for _, name := range users.Fields().Names() {
+		if name != "_id" && name != "name" && name != "alias" && name != "tags" {
+			assert.Contains(t, result, fieldLabel(name)+":", name)
+		}
+	}
+	assert.Contains(t, result, "Locale:         en-AU\n")
+	assert.Contains(t, result, "Organization Id:102\n")
+}
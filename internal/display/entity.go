@@ -0,0 +1,227 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+)
+
+// the entity names of the built in entities a schema entity may refer to
+const (
+	entityOrganizations = "organizations"
+	entityUsers         = "users"
+)
+
+// summaryFields number of fields after the id shown for a schema record in a list or a link
+const summaryFields = 2
+
+// DisplayRecords generate schema entity search result display, a single record is shown with the records its
+// relations refer to and the records referring to it
+func DisplayRecords(t *entity.Type, records []entity.Record, orgList []organizations.Organization, userList []users.User, linked map[string][]entity.Record) {
+	if len(records) > 0 {
+		if len(records) == 1 {
+			fmt.Println(displayRecordDetails(t, records[0], orgList, userList, linked))
+		} else {
+			fmt.Println(displayRecordList(t, records))
+		}
+	} else {
+		NoResultFound()
+	}
+}
+func displayRecordList(t *entity.Type, records []entity.Record) string {
+	columns := append([]string{"_id"}, summaryNames(t)...)
+	result := fmt.Sprintf("Multiple %s found\n", strings.ToLower(t.Label()))
+	header, separator := "", ""
+	for i, name := range columns {
+		header = header + fmt.Sprintf("%-*s|", columnWidth(i), name)
+		separator = separator + strings.Repeat("-", columnWidth(i)) + "|"
+	}
+	result = result + strings.TrimSuffix(header, "|") + "\n" + strings.TrimSuffix(separator, "|") + "\n"
+	for _, rec := range records {
+		row := ""
+		for i, name := range columns {
+			row = row + fmt.Sprintf("%-*s|", columnWidth(i), strings.Join(t.Fields().Values(rec, name), ", "))
+		}
+		result = result + strings.TrimSuffix(row, "|") + "\n"
+	}
+	return result
+}
+
+// columnWidth the width of a column of a schema record list, the id column is narrower
+func columnWidth(column int) int {
+	if column == 0 {
+		return 20
+	}
+	return 50
+}
+
+func displayRecordDetails(t *entity.Type, rec entity.Record, orgList []organizations.Organization, userList []users.User, linked map[string][]entity.Record) string {
+	result := fmt.Sprintf("%s (Id %d)\n", t.Label(), rec.Id())
+	result = result + "Details:\n"
+	width := 16
+	for _, name := range t.Fields().Names() {
+		if len(name)+2 > width {
+			width = len(name) + 2
+		}
+	}
+	for _, f := range t.Fields().Fields() {
+		if f.Name == "_id" {
+			continue
+		}
+		values := t.Fields().Values(rec, f.Name)
+		if f.Kind == fields.List {
+			for i, value := range values {
+				result = result + fmt.Sprintf("%s %d: %s\n", f.Name, i+1, value)
+			}
+			continue
+		}
+		result = result + fmt.Sprintf("%-*s%s\n", width, f.Name+":", values[0])
+		if f.Ref != "" {
+			id, _ := rec[f.Name].(int)
+			if link := displayReferred(f.Ref, id, orgList, userList, linked); link != "" {
+				result = result + fmt.Sprintf("%-*s%s\n", width, relationName(f.Name)+":", link)
+			}
+		}
+	}
+	return result + displayReferring(t.Name, rec.Id(), linked)
+}
+
+// displayReferred the record of the entity with the id a relation refers to, empty when it was not linked
+func displayReferred(name string, id int, orgList []organizations.Organization, userList []users.User, linked map[string][]entity.Record) string {
+	switch name {
+	case entityOrganizations:
+		if org := findOrganization(orgList, id); org != nil {
+			return fmt.Sprintf("%s (Id %d)", org.Name, org.Id)
+		}
+	case entityUsers:
+		if user := findUser(userList, id); user != nil {
+			return displayLinkedUser(*user)
+		}
+	default:
+		if t, ok := entity.Lookup(name); ok {
+			if rec := findRecord(linked[name], id); rec != nil {
+				return RecordSummary(t, rec)
+			}
+		}
+	}
+	return ""
+}
+
+// DisplayReferring display the linked schema records referring to the record of the entity with the id
+func DisplayReferring(name string, id int, linked map[string][]entity.Record) {
+	if result := displayReferring(name, id, linked); result != "" {
+		fmt.Print(result)
+	}
+}
+func displayReferring(name string, id int, linked map[string][]entity.Record) string {
+	result := ""
+	for _, t := range entity.Types() {
+		for i, rec := range referring(t, name, id, linked[t.Name]) {
+			result = result + fmt.Sprintf("%s %d: %s\n", t.Label(), i+1, RecordSummary(t, rec))
+		}
+	}
+	return result
+}
+
+// referring returns the records of the schema entity with a relation to the record of the entity name with the id
+func referring(t *entity.Type, name string, id int, records []entity.Record) (refs []entity.Record) {
+	for _, rec := range records {
+		for _, f := range t.Fields().Fields() {
+			if f.Ref == name && rec[f.Name] == id {
+				refs = append(refs, rec)
+				break
+			}
+		}
+	}
+	return
+}
+
+// RecordSummary the id and the first fields of a schema record on one line
+func RecordSummary(t *entity.Type, rec entity.Record) string {
+	result := fmt.Sprintf("Id %d", rec.Id())
+	for _, name := range summaryNames(t) {
+		result = result + fmt.Sprintf(" | %s: %s", name, strings.Join(t.Fields().Values(rec, name), ", "))
+	}
+	return result
+}
+
+// summaryNames the names of the first fields after the id that are not lists
+func summaryNames(t *entity.Type) (names []string) {
+	for _, f := range t.Fields().Fields() {
+		if len(names) == summaryFields {
+			break
+		}
+		if f.Name != "_id" && f.Kind != fields.List {
+			names = append(names, f.Name)
+		}
+	}
+	return
+}
+
+// relationName the name a relation field is linked under, the field name without its _id suffix
+func relationName(field string) string {
+	return strings.TrimSuffix(field, "_id")
+}
+
+// findRecord returns the schema record with the id, nil when it was not linked
+func findRecord(records []entity.Record, id int) entity.Record {
+	for _, rec := range records {
+		if rec.Id() == id {
+			return rec
+		}
+	}
+	return nil
+}
+
+// EntityRecords nests under each schema record the records its relations refer to, named by the relation, and the
+// schema records referring to it, named by their entity
+func EntityRecords(t *entity.Type, records []entity.Record, orgList []organizations.Organization, userList []users.User, linked map[string][]entity.Record) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(records))
+	for _, rec := range records {
+		nested := make(map[string]interface{}, len(rec))
+		for name, value := range rec {
+			nested[name] = value
+		}
+		for _, f := range t.Fields().Fields() {
+			if f.Ref == "" || relationName(f.Name) == f.Name {
+				continue
+			}
+			id, _ := rec[f.Name].(int)
+			switch f.Ref {
+			case entityOrganizations:
+				if org := findOrganization(orgList, id); org != nil {
+					nested[relationName(f.Name)] = org
+				}
+			case entityUsers:
+				if user := findUser(userList, id); user != nil {
+					nested[relationName(f.Name)] = user
+				}
+			default:
+				if ref := findRecord(linked[f.Ref], id); ref != nil {
+					nested[relationName(f.Name)] = ref
+				}
+			}
+		}
+		for _, other := range entity.Types() {
+			if refs := referring(other, t.Name, rec.Id(), linked[other.Name]); len(refs) > 0 {
+				nested[other.Name] = refs
+			}
+		}
+		result = append(result, nested)
+	}
+	return result
+}
+
+// WriteRecordsDelimited writes schema records as CSV or TSV rows with every field of the entity as a column
+func WriteRecordsDelimited(w io.Writer, format string, t *entity.Type, records []entity.Record) error {
+	rows := [][]string{t.Fields().Names()}
+	for _, rec := range records {
+		rows = append(rows, t.Fields().Strings(rec, multiValueSeparator))
+	}
+	return WriteDelimited(w, format, rows)
+}
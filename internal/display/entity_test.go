@@ -0,0 +1,101 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+// useTestSchema displays the groups and macros of the entity test schema until the test ends
+func useTestSchema(t *testing.T) (groups *entity.Type, macros *entity.Type) {
+	schema, err := entity.LoadSchema("../entity/test_files/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entity.Use(schema)
+	t.Cleanup(func() {
+		entity.Use(nil)
+	})
+	return schema.Types[0], schema.Types[1]
+}
+
+func TestSchemaGroupOptions(t *testing.T) {
+	useTestSchema(t)
+	assert.Equal(t, "Select 1) Users or 2) Tickets or 3) Organizations or 4) Groups or 5) Macros or 0) All", selectGroupOptions())
+	searchFields := listSearchableFields()
+	assert.Contains(t, searchFields, "| Organizations  | Groups          | Macros    |\n")
+	assert.Contains(t, searchFields, "| url             | url             | url            | name            | title     |\n")
+}
+
+func TestDisplayRecordDetails(t *testing.T) {
+	groups, macros := useTestSchema(t)
+	support := entity.Record{"_id": 1, "name": "Support", "created_at": "2016-04-15T05:19:46 -10:00", "organization_id": 101}
+	macro := entity.Record{"_id": 10, "title": "Close and thank the customer", "active": true, "group_id": 1, "author_id": 1, "tags": []string{"close", "thanks"}}
+	userList := []users.User{{Id: 1, Name: "Francisca Rasmussen", Email: "coffeyrasmussen@flotonic.com", Role: "admin"}}
+	linked := map[string][]entity.Record{"groups": {support}}
+
+	result := displayRecordDetails(macros, macro, nil, userList, linked)
+	assert.Equal(t, "Macros (Id 10)\n"+
+		"Details:\n"+
+		"title:          Close and thank the customer\n"+
+		"active:         true\n"+
+		"group_id:       1\n"+
+		"group:          Id 1 | name: Support | created_at: 2016-04-15T05:19:46 -10:00\n"+
+		"author_id:      1\n"+
+		"author:         Francisca Rasmussen | Email: coffeyrasmussen@flotonic.com | Role: admin\n"+
+		"tags 1: close\n"+
+		"tags 2: thanks\n", result)
+
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}}
+	result = displayRecordDetails(groups, support, orgList, nil, map[string][]entity.Record{"macros": {macro}})
+	assert.Contains(t, result, "organization_id: 101\norganization:    Enthaze (Id 101)\n")
+	assert.Contains(t, result, "Macros 1: Id 10 | title: Close and thank the customer | active: true\n")
+}
+
+func TestDisplayRecordList(t *testing.T) {
+	_, macros := useTestSchema(t)
+	result := displayRecordList(macros, []entity.Record{
+		{"_id": 10, "title": "Close and thank the customer", "active": true},
+		{"_id": 11, "title": "Ask for a refund reason", "active": false},
+	})
+	lines := bytes.Split([]byte(result), []byte("\n"))
+	assert.Equal(t, "Multiple macros found", string(lines[0]))
+	assert.Equal(t, "_id                 |title                                             |active", string(bytes.TrimRight(lines[1], " ")))
+	assert.Equal(t, "11                  |Ask for a refund reason                           |false", string(bytes.TrimRight(lines[4], " ")))
+}
+
+func TestDisplayReferring(t *testing.T) {
+	useTestSchema(t)
+	linked := map[string][]entity.Record{"macros": {
+		{"_id": 11, "title": "Ask for a refund reason", "active": false, "author_id": 2},
+		{"_id": 12, "title": "Escalate to billing", "active": true, "author_id": 3},
+	}}
+	assert.Equal(t, "Macros 1: Id 11 | title: Ask for a refund reason | active: false\n", displayReferring("users", 2, linked))
+	assert.Equal(t, "", displayReferring("organizations", 2, linked))
+}
+
+func TestEntityRecords(t *testing.T) {
+	groups, macros := useTestSchema(t)
+	support := entity.Record{"_id": 1, "name": "Support", "organization_id": 101}
+	macro := entity.Record{"_id": 10, "title": "Close", "group_id": 1, "author_id": 7}
+
+	records := EntityRecords(macros, []entity.Record{macro}, nil, nil, map[string][]entity.Record{"groups": {support}})
+	assert.Equal(t, []map[string]interface{}{{"_id": 10, "title": "Close", "group_id": 1, "author_id": 7, "group": support}}, records)
+
+	org := organizations.Organization{Id: 101, Name: "Enthaze"}
+	records = EntityRecords(groups, []entity.Record{support}, []organizations.Organization{org}, nil, map[string][]entity.Record{"macros": {macro}})
+	assert.Equal(t, &org, records[0]["organization"])
+	assert.Equal(t, []entity.Record{macro}, records[0]["macros"])
+}
+
+func TestWriteRecordsDelimited(t *testing.T) {
+	groups, _ := useTestSchema(t)
+	var buf bytes.Buffer
+	err := WriteRecordsDelimited(&buf, FormatTSV, groups, []entity.Record{{"_id": 1, "name": "Support", "created_at": "2016-04-15T05:19:46 -10:00", "organization_id": 101}})
+	assert.Nil(t, err)
+	assert.Equal(t, "_id\tname\tcreated_at\torganization_id\n1\tSupport\t2016-04-15T05:19:46 -10:00\t101\n", buf.String())
+}
@@ -0,0 +1,77 @@
+package display
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+)
+
+// multiValueSeparator joins the tags and domain names into a single column
+const multiValueSeparator = ";"
+
+// tsvEscaper escapes the characters TSV gives a meaning to, as TSV has no quoting a tab or line break within a value
+// is written as \t, \n or \r and a backslash as \\
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// WriteUsersDelimited writes users as CSV or TSV rows with every user field as a column
+func WriteUsersDelimited(w io.Writer, format string, userList []users.User) error {
+	rows := [][]string{users.Fields().Names()}
+	for _, user := range userList {
+		rows = append(rows, users.Fields().Strings(user, multiValueSeparator))
+	}
+	return WriteDelimited(w, format, rows)
+}
+
+// WriteTicketsDelimited writes tickets as CSV or TSV rows with every ticket field as a column
+func WriteTicketsDelimited(w io.Writer, format string, ticketList []tickets.Ticket) error {
+	rows := [][]string{tickets.Fields().Names()}
+	for _, ticket := range ticketList {
+		rows = append(rows, tickets.Fields().Strings(ticket, multiValueSeparator))
+	}
+	return WriteDelimited(w, format, rows)
+}
+
+// WriteOrganizationsDelimited writes organizations as CSV or TSV rows with every organization field as a column
+func WriteOrganizationsDelimited(w io.Writer, format string, orgList []organizations.Organization) error {
+	rows := [][]string{organizations.Fields().Names()}
+	for _, org := range orgList {
+		rows = append(rows, organizations.Fields().Strings(org, multiValueSeparator))
+	}
+	return WriteDelimited(w, format, rows)
+}
+
+// WriteJSON writes any result as indented JSON
+func WriteJSON(w io.Writer, records interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// WriteDelimited writes rows as CSV, quoted where needed, or as TSV with the tabs and line breaks of values escaped
+func WriteDelimited(w io.Writer, format string, rows [][]string) error {
+	if format == FormatTSV {
+		return writeTSV(w, rows)
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// writeTSV writes rows as tab separated values, one row per line
+func writeTSV(w io.Writer, rows [][]string) error {
+	writer := bufio.NewWriter(w)
+	for _, row := range rows {
+		for i, value := range row {
+			if i > 0 {
+				writer.WriteByte('\t')
+			}
+			writer.WriteString(tsvEscaper.Replace(value))
+		}
+		writer.WriteByte('\n')
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,108 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteUsersDelimited(t *testing.T) {
+	userList := []users.User{
+		{
+			Id:             1,
+			Name:           "Francisca Rasmussen",
+			Active:         true,
+			Signature:      "Don't Worry, Be Happy!",
+			OrganizationId: 119,
+			Tags:           []string{"Springville", "Sutton"},
+			Role:           "admin",
+		},
+	}
+	tests := []struct {
+		test   string
+		format string
+		result string
+	}{
+		{
+			test:   "CSV",
+			format: FormatCSV,
+			result: "_id,url,external_id,name,alias,created_at,active,verified,shared,locale,timezone,last_login_at,email,phone,signature,organization_id,tags,suspended,role\n" +
+				"1,,,Francisca Rasmussen,,,true,false,false,,,,,,\"Don't Worry, Be Happy!\",119,Springville;Sutton,false,admin\n",
+		},
+		{
+			test:   "TSV",
+			format: FormatTSV,
+			result: "_id\turl\texternal_id\tname\talias\tcreated_at\tactive\tverified\tshared\tlocale\ttimezone\tlast_login_at\temail\tphone\tsignature\torganization_id\ttags\tsuspended\trole\n" +
+				"1\t\t\tFrancisca Rasmussen\t\t\ttrue\tfalse\tfalse\t\t\t\t\t\tDon't Worry, Be Happy!\t119\tSpringville;Sutton\tfalse\tadmin\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		err := WriteUsersDelimited(&buf, tt.format, userList)
+		assert.Nil(t, err, tt.test)
+		assert.Equal(t, tt.result, buf.String(), tt.test)
+	}
+}
+
+func TestWriteTicketsDelimited(t *testing.T) {
+	ticketList := []tickets.Ticket{
+		{
+			Id:             "436bf9b0-1147-4c0a-8439-6f79833bff5b",
+			Subject:        "A Catastrophe in Korea (North)",
+			Status:         "pending",
+			SubmitterId:    38,
+			AssigneeId:     24,
+			OrganizationId: 116,
+			Tags:           []string{"Ohio", "American Samoa"},
+			Via:            "web",
+		},
+	}
+	var buf bytes.Buffer
+	err := WriteTicketsDelimited(&buf, FormatCSV, ticketList)
+	assert.Nil(t, err)
+	assert.Equal(t, "_id,url,external_id,created_at,type,subject,description,priority,status,submitter_id,assignee_id,organization_id,tags,has_incidents,due_at,via\n"+
+		"436bf9b0-1147-4c0a-8439-6f79833bff5b,,,,,A Catastrophe in Korea (North),,,pending,38,24,116,Ohio;American Samoa,false,,web\n", buf.String())
+}
+
+func TestWriteOrganizationsDelimited(t *testing.T) {
+	orgList := []organizations.Organization{
+		{
+			Id:          101,
+			Name:        "Enthaze",
+			DomainNames: []string{"kage.com", "ecratic.com"},
+			Details:     "MegaCorp",
+			Tags:        []string{"Fulton", "West"},
+		},
+	}
+	var buf bytes.Buffer
+	err := WriteOrganizationsDelimited(&buf, FormatTSV, orgList)
+	assert.Nil(t, err)
+	assert.Equal(t, "_id\turl\texternal_id\tname\tdomain_names\tcreated_at\tdetails\tshared_tickets\ttags\n"+
+		"101\t\t\tEnthaze\tkage.com;ecratic.com\t\tMegaCorp\tfalse\tFulton;West\n", buf.String())
+}
+
+func TestWriteDelimitedQuoting(t *testing.T) {
+	rows := [][]string{{"subject", "description"}, {`A "Drama", in Portugal`, "line one\nline\ttwo \\ end"}}
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteDelimited(&buf, FormatCSV, rows))
+	assert.Equal(t, "subject,description\n\"A \"\"Drama\"\", in Portugal\",\"line one\nline\ttwo \\ end\"\n", buf.String())
+
+	// tsv has no quoting, quotes and commas are written as they are and tabs and line breaks escaped
+	buf.Reset()
+	assert.Nil(t, WriteDelimited(&buf, FormatTSV, rows))
+	assert.Equal(t, "subject\tdescription\nA \"Drama\", in Portugal\tline one\\nline\\ttwo \\\\ end\n", buf.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteJSON(&buf, OrganizationRecords([]organizations.Organization{{Id: 101, Name: "Enthaze"}}, nil, nil))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[{"_id": 101, "url": "", "external_id": "", "name": "Enthaze", "domain_names": null,
+		"created_at": "", "details": "", "shared_tickets": false, "tags": null}]`, buf.String())
+}
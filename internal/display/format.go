@@ -0,0 +1,29 @@
+package display
+
+// output formats for the search results
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+)
+
+// ValidFormat checks a format against the supported output formats
+func ValidFormat(format string) bool {
+	switch format {
+	case FormatText, FormatJSON, FormatCSV, FormatTSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExportFormat checks a format can be written to a file
+func ExportFormat(format string) bool {
+	switch format {
+	case FormatJSON, FormatCSV, FormatTSV:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,52 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidFormat(t *testing.T) {
+	tests := []struct {
+		test   string
+		format string
+		valid  bool
+		export bool
+	}{
+		{
+			test:   "Text",
+			format: FormatText,
+			valid:  true,
+			export: false,
+		},
+		{
+			test:   "JSON",
+			format: FormatJSON,
+			valid:  true,
+			export: true,
+		},
+		{
+			test:   "CSV",
+			format: FormatCSV,
+			valid:  true,
+			export: true,
+		},
+		{
+			test:   "TSV",
+			format: FormatTSV,
+			valid:  true,
+			export: true,
+		},
+		{
+			test:   "Unknown",
+			format: "xml",
+			valid:  false,
+			export: false,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, ValidFormat(tt.format), tt.test)
+		assert.Equal(t, tt.export, ExportFormat(tt.format), tt.test)
+	}
+}
@@ -0,0 +1,124 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+)
+
+// UserRecord a user search result with its linked organization and tickets nested
+type UserRecord struct {
+	users.User
+	Organization     *organizations.Organization `json:"organization,omitempty"`
+	SubmittedTickets []tickets.Ticket            `json:"submitted_tickets,omitempty"`
+	AssignedTickets  []tickets.Ticket            `json:"assigned_tickets,omitempty"`
+}
+
+// TicketRecord a ticket search result with its linked organization, submitter and assignee nested
+type TicketRecord struct {
+	tickets.Ticket
+	Organization *organizations.Organization `json:"organization,omitempty"`
+	Submitter    *users.User                 `json:"submitter,omitempty"`
+	Assignee     *users.User                 `json:"assignee,omitempty"`
+}
+
+// OrganizationRecord an organization search result with its linked tickets and users nested
+type OrganizationRecord struct {
+	organizations.Organization
+	Tickets []tickets.Ticket `json:"tickets,omitempty"`
+	Users   []users.User     `json:"users,omitempty"`
+}
+
+// UserRecords nests the linked organization and the linked tickets submitted by or assigned to each user
+func UserRecords(userList []users.User, orgList []organizations.Organization, ticketList []tickets.Ticket) []UserRecord {
+	records := make([]UserRecord, 0, len(userList))
+	for _, user := range userList {
+		record := UserRecord{User: user, Organization: findOrganization(orgList, user.OrganizationId)}
+		record.SubmittedTickets, record.AssignedTickets = userTickets(user, ticketList)
+		records = append(records, record)
+	}
+	return records
+}
+
+// TicketRecords nests the linked organization, submitter and assignee under each ticket
+func TicketRecords(ticketList []tickets.Ticket, orgList []organizations.Organization, userList []users.User) []TicketRecord {
+	records := make([]TicketRecord, 0, len(ticketList))
+	for _, ticket := range ticketList {
+		records = append(records, TicketRecord{
+			Ticket:       ticket,
+			Organization: findOrganization(orgList, ticket.OrganizationId),
+			Submitter:    findUser(userList, ticket.SubmitterId),
+			Assignee:     findUser(userList, ticket.AssigneeId),
+		})
+	}
+	return records
+}
+
+// OrganizationRecords nests the linked tickets and users under each organization
+func OrganizationRecords(orgList []organizations.Organization, ticketList []tickets.Ticket, userList []users.User) []OrganizationRecord {
+	records := make([]OrganizationRecord, 0, len(orgList))
+	for _, org := range orgList {
+		record := OrganizationRecord{Organization: org}
+		for _, ticket := range ticketList {
+			if ticket.OrganizationId == org.Id {
+				record.Tickets = append(record.Tickets, ticket)
+			}
+		}
+		for _, user := range userList {
+			if user.OrganizationId == org.Id {
+				record.Users = append(record.Users, user)
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// findOrganization returns the organization with the id, nil when it was not linked
+func findOrganization(orgList []organizations.Organization, id int) *organizations.Organization {
+	for i := range orgList {
+		if orgList[i].Id == id {
+			return &orgList[i]
+		}
+	}
+	return nil
+}
+
+// findUser returns the user with the id, nil when it was not linked
+func findUser(userList []users.User, id int) *users.User {
+	for i := range userList {
+		if userList[i].Id == id {
+			return &userList[i]
+		}
+	}
+	return nil
+}
+
+// userTickets splits the linked tickets into those submitted by and those assigned to the user
+func userTickets(user users.User, ticketList []tickets.Ticket) (submitted []tickets.Ticket, assigned []tickets.Ticket) {
+	for _, ticket := range ticketList {
+		if ticket.SubmitterId == user.Id {
+			submitted = append(submitted, ticket)
+		}
+		if ticket.AssigneeId == user.Id {
+			assigned = append(assigned, ticket)
+		}
+	}
+	return
+}
+
+// DisplayJSON display any result as indented JSON
+func DisplayJSON(records interface{}) {
+	fmt.Println(displayJSON(records))
+}
+
+func displayJSON(records interface{}) string {
+	result, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("{\"error\": %q}", err.Error())
+	}
+	return string(result)
+}
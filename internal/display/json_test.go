@@ -0,0 +1,73 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRecords(t *testing.T) {
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}}
+	userList := []users.User{{Id: 1, OrganizationId: 102}, {Id: 2, OrganizationId: 999}}
+	records := UserRecords(userList, orgList, nil)
+	assert.Equal(t, []UserRecord{
+		{User: userList[0], Organization: &orgList[1]},
+		{User: userList[1]},
+	}, records)
+	assert.Equal(t, []UserRecord{}, UserRecords(nil, orgList, nil))
+
+	ticketList := []tickets.Ticket{{Id: "a", SubmitterId: 1, AssigneeId: 1}, {Id: "b", SubmitterId: 1, AssigneeId: 2}, {Id: "c", SubmitterId: 2, AssigneeId: 1}}
+	records = UserRecords(userList[:1], orgList, ticketList)
+	assert.Equal(t, []UserRecord{
+		{User: userList[0], Organization: &orgList[1], SubmittedTickets: ticketList[:2], AssignedTickets: []tickets.Ticket{ticketList[0], ticketList[2]}},
+	}, records)
+}
+
+func TestTicketRecords(t *testing.T) {
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}}
+	ticketList := []tickets.Ticket{{Id: "a", OrganizationId: 101}, {Id: "b"}}
+	records := TicketRecords(ticketList, orgList, nil)
+	assert.Equal(t, []TicketRecord{
+		{Ticket: ticketList[0], Organization: &orgList[0]},
+		{Ticket: ticketList[1]},
+	}, records)
+
+	userList := []users.User{{Id: 38, Name: "Elma Castro"}, {Id: 24, Name: "Harris Côpeland"}}
+	ticketList = []tickets.Ticket{{Id: "a", SubmitterId: 38, AssigneeId: 24}, {Id: "b", SubmitterId: 38, AssigneeId: 38}, {Id: "c", SubmitterId: 5}}
+	records = TicketRecords(ticketList, nil, userList)
+	assert.Equal(t, []TicketRecord{
+		{Ticket: ticketList[0], Submitter: &userList[0], Assignee: &userList[1]},
+		{Ticket: ticketList[1], Submitter: &userList[0], Assignee: &userList[0]},
+		{Ticket: ticketList[2]},
+	}, records)
+}
+
+func TestOrganizationRecords(t *testing.T) {
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}}
+	ticketList := []tickets.Ticket{{Id: "a", OrganizationId: 101}, {Id: "b", OrganizationId: 103}}
+	userList := []users.User{{Id: 1, OrganizationId: 101}, {Id: 2, OrganizationId: 101}}
+	records := OrganizationRecords(orgList, ticketList, userList)
+	assert.Equal(t, []OrganizationRecord{
+		{Organization: orgList[0], Tickets: ticketList[:1], Users: userList},
+		{Organization: orgList[1]},
+	}, records)
+}
+
+func TestDisplayJSON(t *testing.T) {
+	userList := []users.User{{Id: 1, Name: "Francisca Rasmussen", OrganizationId: 101, Tags: []string{"Sutton"}}}
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}}
+	result := displayJSON(UserRecords(userList, orgList, nil))
+	assert.JSONEq(t, `[{
+		"_id": 1, "url": "", "external_id": "", "name": "Francisca Rasmussen", "alias": "", "created_at": "",
+		"active": false, "verified": false, "shared": false, "locale": "", "timezone": "", "last_login_at": "",
+		"email": "", "phone": "", "signature": "", "organization_id": 101, "tags": ["Sutton"], "suspended": false, "role": "",
+		"organization": {
+			"_id": 101, "url": "", "external_id": "", "name": "Enthaze", "domain_names": null,
+			"created_at": "", "details": "", "shared_tickets": false, "tags": null
+		}
+	}]`, result)
+	assert.Equal(t, "[]", displayJSON(UserRecords(nil, nil, nil)))
+}
@@ -0,0 +1,42 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RecordMatch a record holding a searched value in the fields of a wildcard search, the record summarised on one line
+type RecordMatch struct {
+	Id     string
+	Record string
+	Fields []string
+}
+
+// DisplayRecordMatches generate the display of a wildcard search, every record holding the value is followed by the
+// fields holding it
+func DisplayRecordMatches(matches []RecordMatch) {
+	if len(matches) > 0 {
+		fmt.Println(displayRecordMatches(matches))
+	} else {
+		NoResultFound()
+	}
+}
+func displayRecordMatches(matches []RecordMatch) string {
+	result := ""
+	for _, m := range matches {
+		result = result + m.Record + "\n"
+		result = result + fmt.Sprintf("  matched in: %s\n", strings.Join(m.Fields, ", "))
+	}
+	return result
+}
+
+// WriteRecordMatchesDelimited writes the records of a wildcard search as CSV or TSV rows of the record id and the
+// fields holding the value
+func WriteRecordMatchesDelimited(w io.Writer, format string, matches []RecordMatch) error {
+	rows := [][]string{{"_id", "fields"}}
+	for _, m := range matches {
+		rows = append(rows, []string{m.Id, strings.Join(m.Fields, multiValueSeparator)})
+	}
+	return WriteDelimited(w, format, rows)
+}
@@ -0,0 +1,23 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayRecordMatches(t *testing.T) {
+	matches := []RecordMatch{
+		{Id: "1", Record: "User Id: 1 | User Name: Francisca Rasmussen | User Email: coffeyrasmussen@flotonic.com", Fields: []string{"name", "signature"}},
+		{Id: "5", Record: "User Id: 5 | User Name: Loraine Pittman | User Email: olapittman@flotonic.com", Fields: []string{"alias"}},
+	}
+	assert.Equal(t, "User Id: 1 | User Name: Francisca Rasmussen | User Email: coffeyrasmussen@flotonic.com\n"+
+		"  matched in: name, signature\n"+
+		"User Id: 5 | User Name: Loraine Pittman | User Email: olapittman@flotonic.com\n"+
+		"  matched in: alias\n", displayRecordMatches(matches))
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteRecordMatchesDelimited(&buf, FormatTSV, matches))
+	assert.Equal(t, "_id\tfields\n1\tname;signature\n5\talias\n", buf.String())
+}
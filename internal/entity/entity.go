@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+)
+
+// idField the field identifying a record, every schema entity has one holding a whole number
+const idField = "_id"
+
+// Type an entity type described by a schema file rather than a Go type
+type Type struct {
+	Name   string // name of the entity e.g. satisfaction_ratings, used as its search group
+	File   string // data file holding the records
+	fields *fields.Registry
+}
+
+// Record a record of a schema entity keyed by field name. Every field of the entity is held, as an int, bool,
+// string or []string following the field type, a field missing from the data file holds the zero value
+type Record map[string]interface{}
+
+// Id returns the id of the record
+func (rec Record) Id() int {
+	id, _ := rec[idField].(int)
+	return id
+}
+
+// Fields returns the searchable fields of the entity
+func (t *Type) Fields() *fields.Registry {
+	return t.fields
+}
+
+// Label returns the name of the entity as shown to the user e.g. Satisfaction ratings
+func (t *Type) Label() string {
+	label := strings.ReplaceAll(t.Name, "_", " ")
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// Stream reads the data file of the entity one record at a time, calling fn with every record, opts reports
+// progress and rejects invalid records. The file holds a JSON array or one JSON record per line and may be gzip
+// compressed, as the files of the built in entities
+func (t *Type) Stream(opts jsonstream.Options, fn func(Record) error) error {
+	return jsonstream.ReadFile(t.File, opts, func(decoder *json.Decoder) error {
+		rec, err := t.Decode(decoder)
+		if err != nil {
+			return err
+		}
+		return fn(rec)
+	})
+}
+
+// Decode reads the next record from decoder. Fields the entity does not define are ignored as a Go type ignores
+// them, a value of the wrong type is returned as a *json.UnmarshalTypeError naming the entity and the field
+func (t *Type) Decode(decoder *json.Decoder) (Record, error) {
+	var raw map[string]json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	rec := make(Record, len(raw))
+	for _, f := range t.fields.Fields() {
+		value, err := decodeValue(f, raw[f.Name])
+		if err != nil {
+			if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+				typeErr.Struct, typeErr.Field = t.Name, f.Name
+			}
+			return nil, err
+		}
+		rec[f.Name] = value
+	}
+	return rec, nil
+}
+
+// decodeValue decodes the value of a field, a missing or null value is the zero value of the field kind
+func decodeValue(f fields.Field, raw json.RawMessage) (interface{}, error) {
+	var err error
+	switch f.Kind {
+	case fields.Int:
+		var n int
+		if raw != nil {
+			err = json.Unmarshal(raw, &n)
+		}
+		return n, err
+	case fields.Bool:
+		var b bool
+		if raw != nil {
+			err = json.Unmarshal(raw, &b)
+		}
+		return b, err
+	case fields.List:
+		var list []string
+		if raw != nil {
+			err = json.Unmarshal(raw, &list)
+		}
+		return list, err
+	default:
+		var s string
+		if raw != nil {
+			err = json.Unmarshal(raw, &s)
+		}
+		return s, err
+	}
+}
+
+// Match reports whether any value of the record for ident is accepted by match, lists are matched value by value
+func (t *Type) Match(rec Record, ident string, match func(string) bool) bool {
+	return t.fields.Match(rec, ident, match)
+}
@@ -0,0 +1,213 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+)
+
+// field types of a schema field, text is a string ranked by relevance by text searches
+const (
+	TypeString = "string"
+	TypeText   = "text"
+	TypeInt    = "int"
+	TypeBool   = "bool"
+	TypeTime   = "time"
+	TypeList   = "list"
+)
+
+// Builtin the entities held in Go types, a schema entity may refer to them but not redefine them. Tickets are
+// identified by a string and cannot be referred to
+var Builtin = []string{"users", "tickets", "organizations"}
+
+// reserved names no entity may take, all is the group searching every entity
+var reserved = map[string]bool{"all": true}
+
+// referable the built in entities identified by a whole number
+var referable = map[string]bool{"users": true, "organizations": true}
+
+// kinds the field kind of each schema field type
+var kinds = map[string]fields.Kind{
+	TypeString: fields.Text,
+	TypeText:   fields.Text,
+	TypeInt:    fields.Int,
+	TypeBool:   fields.Bool,
+	TypeTime:   fields.Time,
+	TypeList:   fields.List,
+}
+
+// validName entity names are lower case words joined by underscores, so they can be typed as a group and used in a
+// relation such as group.name
+var validName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Schema the entity types described by a schema file
+type Schema struct {
+	Types []*Type
+}
+
+// schemaFile the JSON schema file e.g.
+//
+//	{"entities": [{
+//	  "name": "macros",
+//	  "file": "macros.json",
+//	  "fields": [{"name": "_id", "type": "int"}, {"name": "title", "type": "text"}, {"name": "group_id", "type": "int"}],
+//	  "relations": {"group_id": "groups"}
+//	}]}
+type schemaFile struct {
+	Entities []entityDefinition `json:"entities"`
+}
+
+// entityDefinition an entity of the schema file, relations map an int field to the entity whose id it holds
+type entityDefinition struct {
+	Name      string            `json:"name"`
+	File      string            `json:"file"`
+	Fields    []fieldDefinition `json:"fields"`
+	Relations map[string]string `json:"relations"`
+}
+
+// fieldDefinition a field of an entity of the schema file
+type fieldDefinition struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// LoadSchema reads and checks a JSON schema file, an empty path returns an empty schema. Relative data files are
+// relative to the schema file
+func LoadSchema(path string) (*Schema, error) {
+	schema := &Schema{}
+	if path == "" {
+		return schema, nil
+	}
+	schemaFilePtr, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer schemaFilePtr.Close()
+
+	var file schemaFile
+	decoder := json.NewDecoder(schemaFilePtr)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("reading schema %s: %s", path, err)
+	}
+	names := make(map[string]bool)
+	for _, name := range Builtin {
+		names[name] = true
+	}
+	for _, def := range file.Entities {
+		if !validName.MatchString(def.Name) {
+			return nil, fmt.Errorf("entity name %q must be lower case letters, digits and underscores", def.Name)
+		}
+		if reserved[def.Name] {
+			return nil, fmt.Errorf("entity name %q is reserved", def.Name)
+		}
+		if names[def.Name] {
+			return nil, fmt.Errorf("entity %s is defined twice", def.Name)
+		}
+		names[def.Name] = true
+	}
+	dir := filepath.Dir(path)
+	for _, def := range file.Entities {
+		t, err := newType(def, names)
+		if err != nil {
+			return nil, fmt.Errorf("entity %s: %s", def.Name, err)
+		}
+		if t.File != "" && !filepath.IsAbs(t.File) {
+			t.File = filepath.Join(dir, t.File)
+		}
+		schema.Types = append(schema.Types, t)
+	}
+	return schema, nil
+}
+
+// newType checks the definition of an entity, names holds every entity a relation may refer to
+func newType(def entityDefinition, names map[string]bool) (*Type, error) {
+	if def.File == "" {
+		return nil, fmt.Errorf("no data file")
+	}
+	defined := make([]fields.Field, 0, len(def.Fields))
+	seen := make(map[string]bool, len(def.Fields))
+	for _, fd := range def.Fields {
+		kind, ok := kinds[fd.Type]
+		if !ok {
+			return nil, fmt.Errorf("field %s has unknown type %q, expected string, text, int, bool, time or list", fd.Name, fd.Type)
+		}
+		if fd.Name == "" || seen[fd.Name] {
+			return nil, fmt.Errorf("field %q is empty or defined twice", fd.Name)
+		}
+		if fd.Name == idField && kind != fields.Int {
+			return nil, fmt.Errorf("field %s must be an int", idField)
+		}
+		seen[fd.Name] = true
+		defined = append(defined, fields.Field{Name: fd.Name, Kind: kind, FullText: fd.Type == TypeText})
+	}
+	if !seen[idField] {
+		return nil, fmt.Errorf("no %s field", idField)
+	}
+	// relations are checked in name order so the first error is always the same
+	relationFields := make([]string, 0, len(def.Relations))
+	for field := range def.Relations {
+		relationFields = append(relationFields, field)
+	}
+	sort.Strings(relationFields)
+	for _, field := range relationFields {
+		target := def.Relations[field]
+		if !seen[field] {
+			return nil, fmt.Errorf("relation %s -> %s is not on a field", field, target)
+		}
+		if !names[target] || (!referable[target] && isBuiltin(target)) {
+			return nil, fmt.Errorf("relation %s -> %s refers to an unknown entity or one without whole number ids", field, target)
+		}
+		for i := range defined {
+			if defined[i].Name == field {
+				if defined[i].Kind != fields.Int {
+					return nil, fmt.Errorf("relation %s -> %s is not on an int field", field, target)
+				}
+				defined[i].Ref = target
+			}
+		}
+	}
+	return &Type{Name: def.Name, File: def.File, fields: fields.Define(defined)}, nil
+}
+
+// isBuiltin reports whether name is an entity held in a Go type
+func isBuiltin(name string) bool {
+	for _, builtin := range Builtin {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
+
+// current the schema entity types searched, set once on start up by Use
+var current []*Type
+
+// Use makes the entity types of the schema the types searched and displayed, a nil schema removes them
+func Use(schema *Schema) {
+	if schema == nil {
+		current = nil
+		return
+	}
+	current = schema.Types
+}
+
+// Types returns the entity types searched in schema order
+func Types() []*Type {
+	return current
+}
+
+// Lookup returns the entity type searched with the name, ok is false when the schema has no such entity
+func Lookup(name string) (*Type, bool) {
+	for _, t := range current {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,141 @@
+package entity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSchema(t *testing.T) {
+	schema, err := LoadSchema("test_files/schema.json")
+	assert.Nil(t, err)
+	assert.Len(t, schema.Types, 2)
+
+	groups := schema.Types[0]
+	assert.Equal(t, "groups", groups.Name)
+	assert.Equal(t, filepath.Join("test_files", "groups.json"), groups.File)
+	assert.Equal(t, "Groups", groups.Label())
+	assert.Equal(t, []fields.Field{
+		{Name: "_id", Kind: fields.Int, Index: 0},
+		{Name: "name", Kind: fields.Text, Index: 1},
+		{Name: "created_at", Kind: fields.Time, Index: 2},
+		{Name: "organization_id", Kind: fields.Int, Ref: "organizations", Index: 3},
+	}, groups.Fields().Fields())
+
+	macros := schema.Types[1]
+	assert.Equal(t, []string{"title"}, macros.Fields().FullTextNames())
+	assert.Equal(t, []string{"tags"}, macros.Fields().NamesOf(fields.List))
+	group, _ := macros.Fields().Field("group_id")
+	assert.Equal(t, "groups", group.Ref)
+	author, _ := macros.Fields().Field("author_id")
+	assert.Equal(t, "users", author.Ref)
+
+	empty, err := LoadSchema("")
+	assert.Nil(t, err)
+	assert.Empty(t, empty.Types)
+}
+
+func TestLoadSchemaInvalid(t *testing.T) {
+	tests := []struct {
+		test   string
+		schema string
+		err    string
+	}{
+		{
+			test:   "UnknownEntity",
+			schema: `{"entities": [{"name": "macros", "file": "m.json", "fields": [{"name": "_id", "type": "int"}, {"name": "group_id", "type": "int"}], "relations": {"group_id": "groups"}}]}`,
+			err:    "entity macros: relation group_id -> groups refers to an unknown entity or one without whole number ids",
+		},
+		{
+			test:   "RelationToTickets",
+			schema: `{"entities": [{"name": "macros", "file": "m.json", "fields": [{"name": "_id", "type": "int"}, {"name": "ticket_id", "type": "int"}], "relations": {"ticket_id": "tickets"}}]}`,
+			err:    "entity macros: relation ticket_id -> tickets refers to an unknown entity or one without whole number ids",
+		},
+		{
+			test:   "RelationOnText",
+			schema: `{"entities": [{"name": "macros", "file": "m.json", "fields": [{"name": "_id", "type": "int"}, {"name": "user", "type": "string"}], "relations": {"user": "users"}}]}`,
+			err:    "entity macros: relation user -> users is not on an int field",
+		},
+		{
+			test:   "RelationWithoutField",
+			schema: `{"entities": [{"name": "macros", "file": "m.json", "fields": [{"name": "_id", "type": "int"}], "relations": {"user_id": "users"}}]}`,
+			err:    "entity macros: relation user_id -> users is not on a field",
+		},
+		{
+			test:   "UnknownType",
+			schema: `{"entities": [{"name": "macros", "file": "m.json", "fields": [{"name": "_id", "type": "int"}, {"name": "score", "type": "float"}]}]}`,
+			err:    `entity macros: field score has unknown type "float", expected string, text, int, bool, time or list`,
+		},
+		{
+			test:   "MissingId",
+			schema: `{"entities": [{"name": "macros", "file": "m.json", "fields": [{"name": "title", "type": "text"}]}]}`,
+			err:    "entity macros: no _id field",
+		},
+		{
+			test:   "StringId",
+			schema: `{"entities": [{"name": "macros", "file": "m.json", "fields": [{"name": "_id", "type": "string"}]}]}`,
+			err:    "entity macros: field _id must be an int",
+		},
+		{
+			test:   "DuplicateField",
+			schema: `{"entities": [{"name": "macros", "file": "m.json", "fields": [{"name": "_id", "type": "int"}, {"name": "_id", "type": "int"}]}]}`,
+			err:    `entity macros: field "_id" is empty or defined twice`,
+		},
+		{
+			test:   "NoFile",
+			schema: `{"entities": [{"name": "macros", "fields": [{"name": "_id", "type": "int"}]}]}`,
+			err:    "entity macros: no data file",
+		},
+		{
+			test:   "BuiltinName",
+			schema: `{"entities": [{"name": "users", "file": "u.json", "fields": [{"name": "_id", "type": "int"}]}]}`,
+			err:    "entity users is defined twice",
+		},
+		{
+			test:   "InvalidName",
+			schema: `{"entities": [{"name": "Satisfaction Ratings", "file": "s.json", "fields": [{"name": "_id", "type": "int"}]}]}`,
+			err:    `entity name "Satisfaction Ratings" must be lower case letters, digits and underscores`,
+		},
+		{
+			test:   "ReservedName",
+			schema: `{"entities": [{"name": "all", "file": "a.json", "fields": [{"name": "_id", "type": "int"}]}]}`,
+			err:    `entity name "all" is reserved`,
+		},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "schema.json")
+		assert.Nil(t, os.WriteFile(path, []byte(tt.schema), 0644), tt.test)
+		_, err := LoadSchema(path)
+		if assert.NotNil(t, err, tt.test) {
+			assert.Equal(t, tt.err, err.Error(), tt.test)
+		}
+	}
+
+	_, err := LoadSchema("test_files/invalid_schema.json")
+	assert.NotNil(t, err)
+	_, err = LoadSchema("test_files/missing.json")
+	assert.NotNil(t, err)
+}
+
+func TestUse(t *testing.T) {
+	schema, err := LoadSchema("test_files/schema.json")
+	assert.Nil(t, err)
+	Use(schema)
+	defer Use(nil)
+
+	assert.Len(t, Types(), 2)
+	macros, ok := Lookup("macros")
+	assert.True(t, ok)
+	assert.Equal(t, "macros", macros.Name)
+	_, ok = Lookup("users")
+	assert.False(t, ok)
+
+	Use(nil)
+	assert.Empty(t, Types())
+	_, ok = Lookup("macros")
+	assert.False(t, ok)
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+)
+
+// LoadStore streams the data file of the entity into an indexed in-memory store, opts reports progress and rejects
+// invalid records
+func (t *Type) LoadStore(opts jsonstream.Options) (*store.Index, error) {
+	var records []Record
+	err := t.Stream(opts, func(rec Record) error {
+		records = append(records, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.BuildIndex(records), nil
+}
+
+// Records returns the records of the entity as the records of a store, in order
+func Records(records []Record) (recs []interface{}) {
+	for _, rec := range records {
+		recs = append(recs, rec)
+	}
+	return
+}
+
+// BuildIndex builds an index over every searchable field of the records
+func (t *Type) BuildIndex(records []Record) *store.Index {
+	return store.BuildIndex(t.fields, Records(records))
+}
@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTypes returns the groups and macros of the test schema
+func testTypes(t *testing.T) (groups *Type, macros *Type) {
+	schema, err := LoadSchema("test_files/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema.Types[0], schema.Types[1]
+}
+
+func TestLoadStore(t *testing.T) {
+	groups, macros := testTypes(t)
+
+	groupStore, err := groups.LoadStore(jsonstream.Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, groupStore.Len())
+	billing, ok := groupStore.Get("2")
+	assert.True(t, ok)
+	assert.Equal(t, Record{"_id": 2, "name": "Billing", "created_at": "2016-05-21T11:10:28 -10:00", "organization_id": 102}, billing)
+	_, ok = groupStore.Get("3")
+	assert.False(t, ok)
+
+	// newline delimited records, missing fields hold the zero value
+	macroStore, err := macros.LoadStore(jsonstream.Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, macroStore.Len())
+	refund, _ := macroStore.Get("11")
+	assert.Equal(t, Record{"_id": 11, "title": "Ask for a refund reason", "active": false, "group_id": 2, "author_id": 2, "tags": []string(nil)}, refund)
+	escalate, _ := macroStore.Get("12")
+	assert.Equal(t, 0, escalate.(Record)["author_id"])
+
+	var ids []int
+	for _, rec := range macroStore.Search("group_id", "2") {
+		ids = append(ids, rec.(Record).Id())
+	}
+	assert.Equal(t, []int{11, 12}, ids)
+	assert.Len(t, macroStore.Search("tags", "thanks"), 1)
+	assert.Len(t, macroStore.Search("active", "true"), 2)
+	assert.Empty(t, macroStore.Search("unknown", "1"))
+	assert.Empty(t, macroStore.SearchText("billing refund"))
+	text := macroStore.SearchText("refund reasons")
+	assert.Len(t, text, 1)
+
+	var each []int
+	macroStore.Each(1, 3, func(rec interface{}) {
+		each = append(each, rec.(Record).Id())
+	})
+	assert.Equal(t, []int{11, 12}, each)
+}
+
+func TestLoadStoreInvalid(t *testing.T) {
+	_, macros := testTypes(t)
+	macros.File = "test_files/invalid_macros.json"
+
+	_, err := macros.LoadStore(jsonstream.Options{})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "record 1 field group_id: json: cannot unmarshal string into Go struct field macros.group_id of type int", err.Error())
+	}
+
+	var rejected []jsonstream.RecordError
+	store, err := macros.LoadStore(jsonstream.Options{Reject: func(err jsonstream.RecordError) {
+		rejected = append(rejected, err)
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, store.Len())
+	if assert.Len(t, rejected, 1) {
+		assert.Equal(t, "group_id", rejected[0].Field)
+	}
+
+	macros.File = "test_files/missing.json"
+	_, err = macros.LoadStore(jsonstream.Options{})
+	assert.NotNil(t, err)
+}
+
+func TestMatch(t *testing.T) {
+	_, macros := testTypes(t)
+	rec := Record{"_id": 10, "title": "Close", "tags": []string{"close", "thanks"}}
+	assert.True(t, macros.Match(rec, "tags", func(v string) bool { return v == "thanks" }))
+	assert.False(t, macros.Match(rec, "title", func(v string) bool { return v == "close" }))
+	assert.False(t, macros.Match(rec, "unknown", func(v string) bool { return true }))
+}
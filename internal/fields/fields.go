@@ -0,0 +1,270 @@
+package fields
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Kind how the values of a field are held and compared
+type Kind int
+
+// kinds of field, following the Go type of the struct field and its search tag
+const (
+	Text Kind = iota // string
+	Int              // whole number, compared numerically by range searches
+	Bool             // true or false
+	Time             // timestamp held in a string, compared in time order by range searches
+	List             // list of strings e.g. tags, matched value by value
+)
+
+// Field a searchable field of an entity, named by the json tag of its struct field
+type Field struct {
+	Name     string
+	Kind     Kind
+	FullText bool   // free text ranked by relevance by text searches
+	Ref      string // entity whose id the field holds, empty when it holds no reference
+	Inverse  string // name of the relation of the referred entity back to the records holding the field, empty names it after the entity
+	Index    int    // position of the struct field within the struct
+}
+
+// Registry the searchable fields of an entity type in the order of its struct fields, or of a record map when
+// the fields are defined by Define
+type Registry struct {
+	typ    reflect.Type
+	fields []Field
+	byName map[string]int
+}
+
+// New returns the registry of the struct type of record, read once when the entity package is initialised.
+// Every exported struct field with a json tag is a searchable field named by the tag, its kind follows its Go type.
+// A search tag adds what the type does not say, a comma separated list of:
+//
+//	time             the string holds a timestamp
+//	text             the string is free text ranked by text searches
+//	ref=<entity>     the whole number is the id of a record of the entity
+//	inverse=<name>   the relation of the referred entity back to the record, when its entity name is taken or unclear
+//
+// New panics on a struct field of any other type, as the entity type cannot be searched
+func New(record interface{}) *Registry {
+	typ := reflect.TypeOf(record)
+	r := &Registry{typ: typ, byName: make(map[string]int)}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if sf.PkgPath != "" || name == "" || name == "-" {
+			continue
+		}
+		f := Field{Name: name, Index: i}
+		switch {
+		case sf.Type.Kind() == reflect.String:
+			f.Kind = Text
+		case sf.Type.Kind() == reflect.Int:
+			f.Kind = Int
+		case sf.Type.Kind() == reflect.Bool:
+			f.Kind = Bool
+		case sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.String:
+			f.Kind = List
+		default:
+			panic(fmt.Sprintf("fields: %s.%s of type %s cannot be searched", typ.Name(), sf.Name, sf.Type))
+		}
+		for _, option := range strings.Split(sf.Tag.Get("search"), ",") {
+			switch {
+			case option == "time" && f.Kind == Text:
+				f.Kind = Time
+			case option == "text" && f.Kind == Text:
+				f.FullText = true
+			case strings.HasPrefix(option, "ref=") && f.Kind == Int:
+				f.Ref = strings.TrimPrefix(option, "ref=")
+			case strings.HasPrefix(option, "inverse=") && f.Kind == Int:
+				f.Inverse = strings.TrimPrefix(option, "inverse=")
+			case option != "":
+				panic(fmt.Sprintf("fields: search option %q does not apply to %s.%s", option, typ.Name(), sf.Name))
+			}
+		}
+		if f.Inverse != "" && f.Ref == "" {
+			panic(fmt.Sprintf("fields: %s.%s names an inverse relation without a ref", typ.Name(), sf.Name))
+		}
+		r.byName[name] = len(r.fields)
+		r.fields = append(r.fields, f)
+	}
+	return r
+}
+
+// Define returns the registry of records held in a map keyed by field name, for entity types described at run time
+// rather than by a struct. The values of a record are an int, bool, string or []string following the field kind,
+// Index is ignored. Define panics on a field defined twice
+func Define(defined []Field) *Registry {
+	r := &Registry{byName: make(map[string]int)}
+	for _, f := range defined {
+		if _, ok := r.byName[f.Name]; ok {
+			panic(fmt.Sprintf("fields: field %s is defined twice", f.Name))
+		}
+		f.Index = len(r.fields)
+		r.byName[f.Name] = len(r.fields)
+		r.fields = append(r.fields, f)
+	}
+	return r
+}
+
+// Type returns the struct type of the records, nil for a registry returned by Define
+func (r *Registry) Type() reflect.Type {
+	return r.typ
+}
+
+// Fields returns the searchable fields in order
+func (r *Registry) Fields() []Field {
+	return append([]Field(nil), r.fields...)
+}
+
+// Names returns the names of the searchable fields in order
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.fields))
+	for i, f := range r.fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Field returns the field with the name, ok is false when the entity has no such field
+func (r *Registry) Field(name string) (f Field, ok bool) {
+	i, ok := r.byName[name]
+	if !ok {
+		return Field{}, false
+	}
+	return r.fields[i], true
+}
+
+// Has reports whether name is a searchable field
+func (r *Registry) Has(name string) bool {
+	_, ok := r.byName[name]
+	return ok
+}
+
+// Is reports whether name is a searchable field of the kind
+func (r *Registry) Is(name string, kind Kind) bool {
+	f, ok := r.Field(name)
+	return ok && f.Kind == kind
+}
+
+// FullText reports whether name is a free text field
+func (r *Registry) FullText(name string) bool {
+	f, ok := r.Field(name)
+	return ok && f.FullText
+}
+
+// NamesOf returns the names of the fields of the kind in order
+func (r *Registry) NamesOf(kind Kind) (names []string) {
+	for _, f := range r.fields {
+		if f.Kind == kind {
+			names = append(names, f.Name)
+		}
+	}
+	return
+}
+
+// FullTextNames returns the names of the free text fields in order
+func (r *Registry) FullTextNames() (names []string) {
+	for _, f := range r.fields {
+		if f.FullText {
+			names = append(names, f.Name)
+		}
+	}
+	return
+}
+
+// Values returns the searchable string values of the field of record, one per value of a list and nil when the
+// entity has no such field. record is a value or a pointer of the registry type, or a record map for a registry
+// returned by Define
+func (r *Registry) Values(record interface{}, name string) []string {
+	i, ok := r.byName[name]
+	if !ok {
+		return nil
+	}
+	f := r.fields[i]
+	v := r.field(r.value(record), f)
+	if f.Kind == List {
+		return v.Interface().([]string)
+	}
+	return []string{format(f, v)}
+}
+
+// Match reports whether any value of the field of record is accepted by match, lists are matched value by value
+func (r *Registry) Match(record interface{}, name string, match func(string) bool) bool {
+	for _, value := range r.Values(record, name) {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Strings returns the values of every field of record in order, the values of a list joined by separator
+func (r *Registry) Strings(record interface{}, separator string) []string {
+	v := r.value(record)
+	row := make([]string, len(r.fields))
+	for i, f := range r.fields {
+		if f.Kind == List {
+			row[i] = strings.Join(r.field(v, f).Interface().([]string), separator)
+		} else {
+			row[i] = format(f, r.field(v, f))
+		}
+	}
+	return row
+}
+
+// value returns the struct value of record, or the map of a registry returned by Define
+func (r *Registry) value(record interface{}) reflect.Value {
+	v := reflect.ValueOf(record)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if r.typ == nil {
+		if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+			panic(fmt.Sprintf("fields: %s is not a record map", v.Type()))
+		}
+		return v
+	}
+	if v.Type() != r.typ {
+		panic(fmt.Sprintf("fields: %s is not a %s", v.Type(), r.typ))
+	}
+	return v
+}
+
+// field returns the value of the field within the struct or record map v, a field missing from a record map holds
+// the zero value of its kind
+func (r *Registry) field(v reflect.Value, f Field) reflect.Value {
+	if r.typ != nil {
+		return v.Field(f.Index)
+	}
+	value := v.MapIndex(reflect.ValueOf(f.Name).Convert(v.Type().Key()))
+	if value.IsValid() && value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return reflect.Zero(kindTypes[f.Kind])
+	}
+	return value
+}
+
+// kindTypes the Go type holding the values of each kind of field
+var kindTypes = map[Kind]reflect.Type{
+	Text: reflect.TypeOf(""),
+	Int:  reflect.TypeOf(0),
+	Bool: reflect.TypeOf(false),
+	Time: reflect.TypeOf(""),
+	List: reflect.TypeOf([]string(nil)),
+}
+
+// format returns the single value of a field that is not a list as a string
+func format(f Field, v reflect.Value) string {
+	switch f.Kind {
+	case Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case Bool:
+		return strconv.FormatBool(v.Bool())
+	default:
+		return v.String()
+	}
+}
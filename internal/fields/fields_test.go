@@ -0,0 +1,110 @@
+package fields
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// record a record of every kind of field
+type record struct {
+	Id        int      `json:"_id"`
+	Name      string   `json:"name,omitempty"`
+	CreatedAt string   `json:"created_at" search:"time"`
+	Details   string   `json:"details" search:"text"`
+	Active    bool     `json:"active"`
+	OwnerId   int      `json:"owner_id" search:"ref=users,inverse=owned"`
+	Tags      []string `json:"tags"`
+	Internal  string   `json:"-"`
+	Untagged  string
+	hidden    string
+}
+
+func TestNew(t *testing.T) {
+	r := New(record{})
+	assert.Equal(t, reflect.TypeOf(record{}), r.Type())
+	assert.Equal(t, []string{"_id", "name", "created_at", "details", "active", "owner_id", "tags"}, r.Names())
+	assert.Equal(t, []Field{
+		{Name: "_id", Kind: Int, Index: 0},
+		{Name: "name", Kind: Text, Index: 1},
+		{Name: "created_at", Kind: Time, Index: 2},
+		{Name: "details", Kind: Text, FullText: true, Index: 3},
+		{Name: "active", Kind: Bool, Index: 4},
+		{Name: "owner_id", Kind: Int, Ref: "users", Inverse: "owned", Index: 5},
+		{Name: "tags", Kind: List, Index: 6},
+	}, r.Fields())
+
+	assert.True(t, r.Has("owner_id"))
+	assert.False(t, r.Has("Untagged"))
+	assert.True(t, r.Is("created_at", Time))
+	assert.False(t, r.Is("name", Time))
+	assert.False(t, r.Is("unknown", Text))
+	assert.True(t, r.FullText("details"))
+	assert.False(t, r.FullText("name"))
+	assert.Equal(t, []string{"_id", "owner_id"}, r.NamesOf(Int))
+	assert.Equal(t, []string{"details"}, r.FullTextNames())
+	_, ok := r.Field("hidden")
+	assert.False(t, ok)
+}
+
+func TestNewInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		New(struct {
+			Score float64 `json:"score"`
+		}{})
+	})
+	assert.Panics(t, func() {
+		New(struct {
+			Active bool `json:"active" search:"time"`
+		}{})
+	})
+	assert.Panics(t, func() {
+		New(struct {
+			OwnerId int `json:"owner_id" search:"inverse=owned"`
+		}{})
+	})
+}
+
+func TestValues(t *testing.T) {
+	r := New(record{})
+	rec := record{Id: 7, Name: "Francisca", CreatedAt: "2016-04-15T05:19:46 -10:00", Active: true, Tags: []string{"Springville", "Sutton"}}
+	assert.Equal(t, []string{"7"}, r.Values(rec, "_id"))
+	assert.Equal(t, []string{"Francisca"}, r.Values(&rec, "name"))
+	assert.Equal(t, []string{"true"}, r.Values(rec, "active"))
+	assert.Equal(t, []string{"0"}, r.Values(rec, "owner_id"))
+	assert.Equal(t, []string{"Springville", "Sutton"}, r.Values(rec, "tags"))
+	assert.Nil(t, r.Values(record{}, "tags"))
+	assert.Nil(t, r.Values(rec, "unknown"))
+	assert.True(t, r.Match(rec, "tags", func(v string) bool { return v == "Sutton" }))
+	assert.False(t, r.Match(rec, "unknown", func(v string) bool { return true }))
+	assert.Equal(t, []string{"7", "Francisca", "2016-04-15T05:19:46 -10:00", "", "true", "0", "Springville;Sutton"}, r.Strings(rec, ";"))
+	assert.Panics(t, func() {
+		r.Values(struct{ Id int }{}, "_id")
+	})
+}
+
+func TestDefine(t *testing.T) {
+	r := Define([]Field{
+		{Name: "_id", Kind: Int},
+		{Name: "name", Kind: Text, FullText: true},
+		{Name: "owner_id", Kind: Int, Ref: "users"},
+		{Name: "tags", Kind: List},
+	})
+	assert.Equal(t, []string{"_id", "name", "owner_id", "tags"}, r.Names())
+	assert.Nil(t, r.Type())
+	assert.Equal(t, Field{Name: "owner_id", Kind: Int, Ref: "users", Index: 2}, r.Fields()[2])
+	assert.Equal(t, []string{"name"}, r.FullTextNames())
+
+	rec := map[string]interface{}{"_id": 3, "name": "Support", "tags": []string{"tier1", "tier2"}}
+	assert.Equal(t, []string{"3"}, r.Values(rec, "_id"))
+	assert.Equal(t, []string{"0"}, r.Values(rec, "owner_id"))
+	assert.Equal(t, []string{"tier1", "tier2"}, r.Values(rec, "tags"))
+	assert.Equal(t, []string{"3", "Support", "0", "tier1;tier2"}, r.Strings(rec, ";"))
+	assert.Panics(t, func() {
+		r.Values(record{}, "_id")
+	})
+	assert.Panics(t, func() {
+		Define([]Field{{Name: "_id", Kind: Int}, {Name: "_id", Kind: Text}})
+	})
+}
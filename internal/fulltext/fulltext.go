@@ -0,0 +1,249 @@
+package fulltext
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 tuning, k1 limits how much repeated terms add to the score and b how much long fields are penalised
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// minStemLength shortest word left after removing a suffix
+const minStemLength = 3
+
+// stopWords common english words dropped from the text and queries
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "from": true, "has": true, "have": true, "i": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "its": true, "no": true, "not": true, "of": true, "on": true,
+	"or": true, "s": true, "such": true, "t": true, "that": true, "the": true, "their": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "to": true, "was": true, "were": true,
+	"will": true, "with": true,
+}
+
+// suffixes removed by Stem, longest first so "ations" is removed before "s"
+var suffixes = []struct {
+	suffix      string
+	replacement string
+}{
+	{"ational", "ate"},
+	{"ations", "ate"},
+	{"ation", "ate"},
+	{"nesses", ""},
+	{"ness", ""},
+	{"ingly", ""},
+	{"ings", ""},
+	{"ing", ""},
+	{"edly", ""},
+	{"ies", "y"},
+	{"sses", "ss"},
+	{"ed", ""},
+	{"ly", ""},
+	{"s", ""},
+}
+
+// Hit a matching document and its relevance score
+type Hit struct {
+	Doc   int
+	Score float64
+}
+
+// Index an inverted index over the text fields of a set of documents, scored with BM25
+type Index struct {
+	fields map[string]*fieldIndex
+	docs   map[int]bool
+}
+
+// fieldIndex term postings and length statistics of a single field
+type fieldIndex struct {
+	postings    map[string]map[int]int
+	lengths     map[int]int
+	totalLength int
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{
+		fields: make(map[string]*fieldIndex),
+		docs:   make(map[int]bool),
+	}
+}
+
+// Add indexes the text of a document field, a field added twice for the same document is appended to
+func (idx *Index) Add(doc int, field string, text string) {
+	fi, ok := idx.fields[field]
+	if !ok {
+		fi = &fieldIndex{
+			postings: make(map[string]map[int]int),
+			lengths:  make(map[int]int),
+		}
+		idx.fields[field] = fi
+	}
+	idx.docs[doc] = true
+	terms := Tokenize(text)
+	for _, term := range terms {
+		docs, ok := fi.postings[term]
+		if !ok {
+			docs = make(map[int]int)
+			fi.postings[term] = docs
+		}
+		docs[doc]++
+	}
+	fi.lengths[doc] += len(terms)
+	fi.totalLength += len(terms)
+}
+
+// Search returns the documents holding every query term in the fields, all fields when none are given, as
+// ContainsAll filters text. A term may be held by any of the fields. Hits are ordered by descending score with ties
+// in document order
+func (idx *Index) Search(query string, fields ...string) []Hit {
+	if len(fields) == 0 {
+		for field := range idx.fields {
+			fields = append(fields, field)
+		}
+		// sum the field scores in a fixed order so equal scores compare equal between searches
+		sort.Strings(fields)
+	}
+	terms := QueryTerms(query)
+	scores := make(map[int]float64)
+	held := make(map[int]map[string]bool)
+	n := float64(len(idx.docs))
+	for _, field := range fields {
+		fi, ok := idx.fields[field]
+		if !ok || len(fi.lengths) == 0 {
+			continue
+		}
+		avgLength := float64(fi.totalLength) / float64(len(fi.lengths))
+		for _, term := range terms {
+			docs := fi.postings[term]
+			for doc, tf := range docs {
+				scores[doc] += Score(n, float64(len(docs)), float64(tf), float64(fi.lengths[doc]), avgLength)
+				Hold(held, doc, term)
+			}
+		}
+	}
+	return Rank(HoldingAll(scores, held, terms))
+}
+
+// Hold records that the document holds the term, for HoldingAll
+func Hold(held map[int]map[string]bool, doc int, term string) {
+	if held[doc] == nil {
+		held[doc] = make(map[string]bool)
+	}
+	held[doc][term] = true
+}
+
+// HoldingAll returns the scores of the documents holding every one of the terms
+func HoldingAll(scores map[int]float64, held map[int]map[string]bool, terms []string) map[int]float64 {
+	for doc := range scores {
+		if len(held[doc]) < len(terms) {
+			delete(scores, doc)
+		}
+	}
+	return scores
+}
+
+// Score returns the BM25 score of a term found freq times in a field of length terms, within n documents of
+// which df hold the term and whose field averages avgLength terms
+func Score(n float64, df float64, freq float64, length float64, avgLength float64) float64 {
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	norm := 1 - b + b*length/avgLength
+	return idf * freq * (k1 + 1) / (freq + k1*norm)
+}
+
+// Rank orders the scored documents by descending score with ties in document order
+func Rank(scores map[int]float64) []Hit {
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		hits = append(hits, Hit{Doc: doc, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Doc < hits[j].Doc
+	})
+	return hits
+}
+
+// Tokenize splits text into lower case stemmed terms, dropping punctuation and stop words
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if stopWords[word] {
+			continue
+		}
+		terms = append(terms, Stem(word))
+	}
+	return terms
+}
+
+// QueryTerms returns the distinct terms of a query in the order they are first written
+func QueryTerms(query string) []string {
+	return unique(Tokenize(query))
+}
+
+// Stem reduces an english word to its stem by removing a common suffix e.g. "catastrophes" to "catastrophe"
+func Stem(word string) string {
+	for _, s := range suffixes {
+		if strings.HasSuffix(word, s.suffix) && len(word)-len(s.suffix)+len(s.replacement) >= minStemLength {
+			if s.suffix == "s" && strings.HasSuffix(word, "ss") {
+				// keep words such as "address" whole
+				return word
+			}
+			return strings.TrimSuffix(word, s.suffix) + s.replacement
+		}
+	}
+	return word
+}
+
+// ContainsAll reports whether text holds every term of the query, used to filter text without ranking it
+func ContainsAll(text string, query string) bool {
+	terms := make(map[string]bool)
+	for _, term := range Tokenize(text) {
+		terms[term] = true
+	}
+	queryTerms := Tokenize(query)
+	for _, term := range queryTerms {
+		if !terms[term] {
+			return false
+		}
+	}
+	return len(queryTerms) > 0
+}
+
+// ContainsAny reports whether text holds a term of the query, used to tell which fields hold the words of a query
+// spread across them
+func ContainsAny(text string, query string) bool {
+	queryTerms := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		queryTerms[term] = true
+	}
+	for _, term := range Tokenize(text) {
+		if queryTerms[term] {
+			return true
+		}
+	}
+	return false
+}
+
+// unique removes repeated terms keeping the first occurrence
+func unique(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	result := terms[:0:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package fulltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word   string
+		result string
+	}{
+		{word: "catastrophes", result: "catastrophe"},
+		{word: "catastrophe", result: "catastrophe"},
+		{word: "countries", result: "country"},
+		{word: "address", result: "address"},
+		{word: "addresses", result: "address"},
+		{word: "running", result: "runn"},
+		{word: "exercitation", result: "exercitate"},
+		{word: "happiness", result: "happi"},
+		{word: "quickly", result: "quick"},
+		{word: "is", result: "is"},
+		{word: "sing", result: "sing"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.result, Stem(tt.word), tt.word)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		test   string
+		text   string
+		result []string
+	}{
+		{
+			test:   "DropsStopWordsAndPunctuation",
+			text:   "A Catastrophe in Korea (North)",
+			result: []string{"catastrophe", "korea", "north"},
+		},
+		{
+			test:   "Apostrophe",
+			text:   "Don't Worry Be Happy!",
+			result: []string{"don", "worry", "happy"},
+		},
+		{
+			test:   "Empty",
+			text:   "",
+			result: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.result, Tokenize(tt.text), tt.test)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(0, "subject", "A Catastrophe in Korea (North)")
+	idx.Add(0, "description", "Nostrud ad sit velit cupidatat laboris ipsum nisi amet laboris")
+	idx.Add(1, "subject", "A Catastrophe in Micronesia")
+	idx.Add(1, "description", "Aliquip excepteur fugiat ex minim ea aute eu labore")
+	idx.Add(2, "subject", "A Drama in Korea (South)")
+	idx.Add(2, "description", "Korea korea catastrophes everywhere")
+	idx.Add(3, "subject", "A Problem in Ghana")
+
+	tests := []struct {
+		test   string
+		query  string
+		fields []string
+		docs   []int
+	}{
+		{
+			test:   "EveryTerm",
+			query:  "catastrophe Korea",
+			fields: []string{"subject"},
+			docs:   []int{0},
+		},
+		{
+			test:  "EveryTermInAnyField",
+			query: "catastrophe Korea",
+			docs:  []int{2, 0},
+		},
+		{
+			test:   "StemmedQuery",
+			query:  "catastrophes",
+			fields: []string{"subject"},
+			docs:   []int{1, 0},
+		},
+		{
+			test:   "StopWordsOnly",
+			query:  "the in a",
+			fields: []string{"subject"},
+			docs:   []int{},
+		},
+		{
+			test:   "UnknownField",
+			query:  "korea",
+			fields: []string{"name"},
+			docs:   []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		docs := []int{}
+		for _, hit := range idx.Search(tt.query, tt.fields...) {
+			assert.True(t, hit.Score > 0, tt.test)
+			docs = append(docs, hit.Doc)
+		}
+		assert.Equal(t, tt.docs, docs, tt.test)
+	}
+}
+
+func TestContainsAll(t *testing.T) {
+	assert.True(t, ContainsAll("A Catastrophe in Korea (North)", "korea catastrophes"))
+	assert.False(t, ContainsAll("A Catastrophe in Micronesia", "korea catastrophes"))
+	assert.False(t, ContainsAll("A Catastrophe in Micronesia", "the"))
+}
+
+func TestContainsAny(t *testing.T) {
+	assert.True(t, ContainsAny("A Catastrophe in Micronesia", "korea catastrophes"))
+	assert.False(t, ContainsAny("Nostrud ea dolor", "korea catastrophes"))
+	assert.False(t, ContainsAny("A Catastrophe in Micronesia", "the"))
+}
@@ -0,0 +1,270 @@
+package jsonstream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// gzipMagic the first bytes of a gzip compressed file
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Progress how far the read of a file has got
+type Progress struct {
+	Records  int   // records read so far
+	Rejected int   // invalid records skipped so far
+	Read     int64 // bytes of the file read so far, compressed bytes for a gzip file
+	Size     int64 // size of the file in bytes, 0 when unknown
+}
+
+// Options how the records are read
+type Options struct {
+	// Progress is called after every record when set
+	Progress func(Progress)
+	// Reject is called with every record holding a value of the wrong type when set and the record is skipped,
+	// when not set the read stops at the first invalid record. A line of newline delimited JSON that is not valid
+	// JSON and a record the record func refuses with an InvalidRecord are rejected the same way, a record of an
+	// array that is not valid JSON always stops the read
+	Reject func(RecordError)
+}
+
+// RecordError a record that could not be read, Index is the position of the record in the array or among the lines
+// from 0 and Field the field holding the invalid value, empty when the record as a whole is invalid
+type RecordError struct {
+	Index int
+	Field string
+	Err   error
+}
+
+// InvalidRecord is returned by a record func refusing a record that was read, such as a record whose id is held by an
+// earlier record. Field is the field holding the refused value
+type InvalidRecord struct {
+	Field string
+	Err   error
+}
+
+// Error describes why the record was refused
+func (e *InvalidRecord) Error() string {
+	return e.Err.Error()
+}
+
+// Error describes the record and the reason it could not be read
+func (e RecordError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("record %d field %s: %s", e.Index, e.Field, e.Err)
+	}
+	return fmt.Sprintf("record %d: %s", e.Index, e.Err)
+}
+
+// Unwrap returns the reason the record could not be read
+func (e RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Fraction returns the share of the file read from 0 to 1, 0 when the size is unknown
+func (p Progress) Fraction() float64 {
+	if p.Size <= 0 {
+		return 0
+	}
+	if p.Read >= p.Size {
+		return 1
+	}
+	return float64(p.Read) / float64(p.Size)
+}
+
+// ReadFile reads the records of a file one at a time, see Read. The file is gunzipped when it is gzip compressed,
+// whatever its name
+func ReadFile(path string, opts Options, record func(*json.Decoder) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var size int64
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+
+	counter := &countingReader{r: file}
+	buffered := bufio.NewReader(counter)
+	var r io.Reader = buffered
+	if magic, err := buffered.Peek(len(gzipMagic)); err == nil && string(magic) == string(gzipMagic) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	if progress := opts.Progress; progress != nil {
+		opts.Progress = func(p Progress) {
+			p.Read, p.Size = counter.n, size
+			progress(p)
+		}
+	}
+	return Read(r, opts, record)
+}
+
+// Read walks the records held in r one at a time without holding them all in memory, calling record with the
+// decoder positioned at each record for it to decode. r may hold a JSON array of records or newline delimited JSON
+// with one record per line, an empty input or null holds no records. A record that cannot be read is returned as
+// a RecordError unless opts rejects it
+func Read(r io.Reader, opts Options, record func(*json.Decoder) error) error {
+	buffered := bufio.NewReader(r)
+	first, err := firstByte(buffered)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(buffered)
+	switch first {
+	case '[':
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	case 'n':
+		var null interface{}
+		if err := decoder.Decode(&null); err != nil {
+			return err
+		}
+		if null != nil {
+			return fmt.Errorf("expected a JSON array or newline delimited JSON objects")
+		}
+		return nil
+	case '{':
+		return readLines(buffered, opts, record)
+	default:
+		return fmt.Errorf("expected a JSON array or newline delimited JSON objects, found %q", first)
+	}
+
+	var progress Progress
+	for index := 0; decoder.More(); index++ {
+		if err := readRecord(index, false, opts, &progress, func() error { return record(decoder) }); err != nil {
+			return err
+		}
+	}
+	if first == '[' {
+		// consume the closing bracket so a truncated array is reported
+		if _, err := decoder.Token(); err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLines reads newline delimited JSON one line at a time, so a line that is not valid JSON can be rejected and
+// the next line read. Blank lines are skipped
+func readLines(r *bufio.Reader, opts Options, record func(*json.Decoder) error) error {
+	var progress Progress
+	index := 0
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			read := func() error {
+				decoder, err := lineDecoder(line)
+				if err != nil {
+					return err
+				}
+				return record(decoder)
+			}
+			if err := readRecord(index, true, opts, &progress, read); err != nil {
+				return err
+			}
+			index++
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// errLineData a line of newline delimited JSON holding more than one record
+var errLineData = errors.New("invalid character after the record, a line holds a single record")
+
+// lineDecoder returns the decoder positioned at the record of a line, the line is checked to hold a single valid
+// JSON value before any of it is decoded
+func lineDecoder(line []byte) (*json.Decoder, error) {
+	var raw json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errLineData
+	}
+	return json.NewDecoder(bytes.NewReader(raw)), nil
+}
+
+// readRecord reads the record with the index, counting it in progress. An invalid record is passed to opts.Reject
+// when set, or returned as a RecordError. A record on its own line that is not valid JSON is invalid as a record
+// holding a value of the wrong type is, other errors stop the read
+func readRecord(index int, line bool, opts Options, progress *Progress, read func() error) error {
+	err := read()
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var refused *InvalidRecord
+	invalid := errors.As(err, &typeErr) || errors.As(err, &refused) ||
+		(line && (errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errLineData)))
+	field := ""
+	switch {
+	case typeErr != nil:
+		field = typeErr.Field
+	case refused != nil:
+		field = refused.Field
+	}
+	switch {
+	case invalid && opts.Reject != nil:
+		// the decoder has read past the whole record so the next record can be read
+		opts.Reject(RecordError{Index: index, Field: field, Err: err})
+		progress.Rejected++
+	case typeErr != nil || refused != nil:
+		return RecordError{Index: index, Field: field, Err: err}
+	case err != nil:
+		return RecordError{Index: index, Err: err}
+	default:
+		progress.Records++
+	}
+	if opts.Progress != nil {
+		opts.Progress(*progress)
+	}
+	return nil
+}
+
+// firstByte returns the first byte of r that is not white space, leaving it unread
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, r.UnreadByte()
+	}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
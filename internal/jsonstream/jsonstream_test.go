@@ -0,0 +1,229 @@
+package jsonstream
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// record a record of the test inputs
+type record struct {
+	Id   int    `json:"_id"`
+	Name string `json:"name"`
+}
+
+// readAll reads every record of r
+func readAll(r *strings.Reader) ([]record, []int, error) {
+	var records []record
+	var progress []int
+	err := Read(r, Options{Progress: func(p Progress) {
+		progress = append(progress, p.Records)
+	}}, func(decoder *json.Decoder) error {
+		var rec record
+		if err := decoder.Decode(&rec); err != nil {
+			return err
+		}
+		records = append(records, rec)
+		return nil
+	})
+	return records, progress, err
+}
+
+func TestRead(t *testing.T) {
+	both := []record{{Id: 1, Name: "Francisca Rasmussen"}, {Id: 2, Name: "Cross Barlow"}}
+	tests := []struct {
+		test    string
+		input   string
+		records []record
+		err     bool
+	}{
+		{test: "Array", input: "[\n  {\"_id\": 1, \"name\": \"Francisca Rasmussen\"},\n  {\"_id\": 2, \"name\": \"Cross Barlow\"}\n]\n", records: both},
+		{test: "NewlineDelimited", input: "{\"_id\": 1, \"name\": \"Francisca Rasmussen\"}\n{\"_id\": 2, \"name\": \"Cross Barlow\"}\n", records: both},
+		{test: "NewlineDelimitedBlankLines", input: "\n{\"_id\": 1, \"name\": \"Francisca Rasmussen\"}\n\n{\"_id\": 2, \"name\": \"Cross Barlow\"}", records: both},
+		{test: "EmptyArray", input: "[]"},
+		{test: "EmptyInput", input: "  \n"},
+		{test: "Null", input: "null"},
+		{test: "TruncatedArray", input: "[{\"_id\": 1, \"name\": \"Francisca Rasmussen\"},", records: both[:1], err: true},
+		{test: "UnclosedArray", input: "[{\"_id\": 1, \"name\": \"Francisca Rasmussen\"}", records: both[:1], err: true},
+		{test: "InvalidRecord", input: "[{\"_id\": \"one\"}]", err: true},
+		{test: "NotRecords", input: "\"users\"", err: true},
+	}
+
+	for _, tt := range tests {
+		records, progress, err := readAll(strings.NewReader(tt.input))
+		assert.Equal(t, tt.err, err != nil, tt.test)
+		assert.Equal(t, tt.records, records, tt.test)
+		assert.Equal(t, len(records), len(progress), tt.test)
+	}
+}
+
+func TestReadRecordError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := Read(strings.NewReader(`[{"_id": 1}, {"_id": 2}]`), Options{}, func(decoder *json.Decoder) error {
+		calls++
+		return stop
+	})
+	assert.Equal(t, RecordError{Index: 0, Err: stop}, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestReadReject(t *testing.T) {
+	input := "[{\"_id\": 1, \"name\": \"Francisca Rasmussen\"}, {\"_id\": \"two\"}, 3, {\"_id\": 4, \"name\": [\"Cross\"]}, {\"_id\": 5}]"
+	decode := func(records *[]record) func(*json.Decoder) error {
+		return func(decoder *json.Decoder) error {
+			var rec record
+			if err := decoder.Decode(&rec); err != nil {
+				return err
+			}
+			*records = append(*records, rec)
+			return nil
+		}
+	}
+
+	// lenient, records holding values of the wrong type are skipped and reported
+	var records []record
+	var rejected []RecordError
+	var last Progress
+	err := Read(strings.NewReader(input), Options{
+		Progress: func(p Progress) { last = p },
+		Reject:   func(e RecordError) { rejected = append(rejected, e) },
+	}, decode(&records))
+	assert.Nil(t, err)
+	assert.Equal(t, []record{{Id: 1, Name: "Francisca Rasmussen"}, {Id: 5}}, records)
+	assert.Equal(t, Progress{Records: 2, Rejected: 3}, last)
+	assert.Equal(t, 3, len(rejected))
+	assert.Equal(t, []int{1, 2, 3}, []int{rejected[0].Index, rejected[1].Index, rejected[2].Index})
+	assert.Equal(t, []string{"_id", "", "name"}, []string{rejected[0].Field, rejected[1].Field, rejected[2].Field})
+	assert.True(t, strings.HasPrefix(rejected[0].Error(), "record 1 field _id: "))
+	assert.True(t, strings.HasPrefix(rejected[1].Error(), "record 2: "))
+
+	// strict, the read stops at the first invalid record
+	records = nil
+	err = Read(strings.NewReader(input), Options{}, decode(&records))
+	var recordErr RecordError
+	assert.True(t, errors.As(err, &recordErr))
+	assert.Equal(t, 1, recordErr.Index)
+	assert.Equal(t, "_id", recordErr.Field)
+	assert.Equal(t, []record{{Id: 1, Name: "Francisca Rasmussen"}}, records)
+
+	// a line that is not valid JSON is rejected and the next line read
+	records, rejected = nil, nil
+	err = Read(strings.NewReader("{\"_id\": 1}\n{\"_id\": }\n{\"_id\": 3\n{\"_id\": 4} {\"_id\": 5}\n\n{\"_id\": 6}"), Options{
+		Progress: func(p Progress) { last = p },
+		Reject:   func(e RecordError) { rejected = append(rejected, e) },
+	}, decode(&records))
+	assert.Nil(t, err)
+	assert.Equal(t, []record{{Id: 1}, {Id: 6}}, records)
+	assert.Equal(t, Progress{Records: 2, Rejected: 3}, last)
+	if assert.Equal(t, 3, len(rejected)) {
+		assert.Equal(t, []int{1, 2, 3}, []int{rejected[0].Index, rejected[1].Index, rejected[2].Index})
+		assert.True(t, strings.HasPrefix(rejected[0].Error(), "record 1: invalid character"), rejected[0].Error())
+	}
+
+	// and stops the read when strict
+	records = nil
+	err = Read(strings.NewReader("{\"_id\": 1}\n{\"_id\": }\n{\"_id\": 3}\n"), Options{}, decode(&records))
+	assert.True(t, errors.As(err, &recordErr))
+	assert.Equal(t, 1, recordErr.Index)
+	assert.Equal(t, []record{{Id: 1}}, records)
+
+	// a record of an array that is not valid JSON stops the read even when lenient
+	rejected = nil
+	err = Read(strings.NewReader("[{\"_id\": 1}, {\"_id\": }, {\"_id\": 3}]"), Options{
+		Reject: func(e RecordError) { rejected = append(rejected, e) },
+	}, decode(&records))
+	assert.NotNil(t, err)
+	assert.Nil(t, rejected)
+}
+
+func TestReadInvalidRecord(t *testing.T) {
+	input := "[{\"_id\": 1}, {\"_id\": 1}, {\"_id\": 2}]"
+	seen := make(map[int]bool)
+	var records []record
+	decode := func(decoder *json.Decoder) error {
+		var rec record
+		if err := decoder.Decode(&rec); err != nil {
+			return err
+		}
+		if seen[rec.Id] {
+			return &InvalidRecord{Field: "_id", Err: fmt.Errorf("_id %d is used by an earlier record", rec.Id)}
+		}
+		seen[rec.Id] = true
+		records = append(records, rec)
+		return nil
+	}
+
+	// a record refused by the record func is rejected as an invalid record is
+	var rejected []RecordError
+	var last Progress
+	err := Read(strings.NewReader(input), Options{
+		Progress: func(p Progress) { last = p },
+		Reject:   func(e RecordError) { rejected = append(rejected, e) },
+	}, decode)
+	assert.Nil(t, err)
+	assert.Equal(t, []record{{Id: 1}, {Id: 2}}, records)
+	assert.Equal(t, Progress{Records: 2, Rejected: 1}, last)
+	if assert.Equal(t, 1, len(rejected)) {
+		assert.Equal(t, "record 1 field _id: _id 1 is used by an earlier record", rejected[0].Error())
+	}
+
+	// and stops the read when strict
+	seen, records = make(map[int]bool), nil
+	err = Read(strings.NewReader(input), Options{}, decode)
+	assert.Equal(t, "record 1 field _id: _id 1 is used by an earlier record", err.Error())
+	assert.Equal(t, []record{{Id: 1}}, records)
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	input := "{\"_id\": 1, \"name\": \"Francisca Rasmussen\"}\n{\"_id\": 2, \"name\": \"Cross Barlow\"}\n"
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write([]byte(input))
+	assert.Nil(t, err)
+	assert.Nil(t, gz.Close())
+	plainPath := filepath.Join(dir, "users.json")
+	// gzip compressed files are detected by their content, not their name
+	gzipPath := filepath.Join(dir, "users.export")
+	assert.Nil(t, os.WriteFile(plainPath, []byte(input), 0644))
+	assert.Nil(t, os.WriteFile(gzipPath, compressed.Bytes(), 0644))
+
+	for _, path := range []string{plainPath, gzipPath} {
+		var names []string
+		var last Progress
+		err := ReadFile(path, Options{Progress: func(p Progress) {
+			last = p
+		}}, func(decoder *json.Decoder) error {
+			var rec record
+			err := decoder.Decode(&rec)
+			names = append(names, rec.Name)
+			return err
+		})
+		assert.Nil(t, err, path)
+		assert.Equal(t, []string{"Francisca Rasmussen", "Cross Barlow"}, names, path)
+		assert.Equal(t, 2, last.Records, path)
+		assert.Equal(t, 1.0, last.Fraction(), path)
+	}
+
+	assert.Nil(t, os.WriteFile(gzipPath, compressed.Bytes()[:20], 0644))
+	assert.NotNil(t, ReadFile(gzipPath, Options{}, func(decoder *json.Decoder) error {
+		var rec record
+		return decoder.Decode(&rec)
+	}))
+	assert.NotNil(t, ReadFile(filepath.Join(dir, "missing.json"), Options{}, nil))
+}
+
+func TestProgressFraction(t *testing.T) {
+	assert.Equal(t, 0.0, Progress{Read: 10}.Fraction())
+	assert.Equal(t, 0.25, Progress{Read: 10, Size: 40}.Fraction())
+	assert.Equal(t, 1.0, Progress{Read: 50, Size: 40}.Fraction())
+}
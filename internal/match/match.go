@@ -0,0 +1,116 @@
+package match
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fulltext"
+)
+
+const (
+	// Exact value equals the search value
+	Exact = "exact"
+	// IgnoreCase value equals the search value ignoring case
+	IgnoreCase = "ignorecase"
+	// Prefix value starts with the search value ignoring case
+	Prefix = "prefix"
+	// Substring value contains the search value ignoring case
+	Substring = "substring"
+	// Glob value matches the search value as a pattern ignoring case, * matches any run of characters and ? a single character
+	Glob = "glob"
+	// Text value holds every word of the search value after stemming and dropping stop words, free text fields are ranked by relevance
+	Text = "text"
+	// Regex value matches the search value as a regular expression, unanchored and case sensitive unless the pattern starts with (?i)
+	Regex = "regex"
+	// Range value is a number or timestamp within the search value range: >x, >=x, <x, <=x, x..y or between x and y
+	Range = "range"
+)
+
+// Modes the supported match modes in the order they are listed
+var Modes = []string{Exact, IgnoreCase, Prefix, Substring, Glob, Text, Regex, Range}
+
+// Matcher reports whether a field value matches the search value
+type Matcher func(value string) bool
+
+// Valid checks a mode against the supported match modes, blank is exact
+func Valid(mode string) bool {
+	if mode == "" {
+		return true
+	}
+	for _, m := range Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// New returns the matcher comparing field values to value using the mode, blank mode is exact
+func New(mode string, value string) (Matcher, error) {
+	switch mode {
+	case "", Exact:
+		return func(v string) bool {
+			return v == value
+		}, nil
+	case IgnoreCase:
+		return func(v string) bool {
+			return strings.EqualFold(v, value)
+		}, nil
+	case Prefix:
+		lower := strings.ToLower(value)
+		return func(v string) bool {
+			return strings.HasPrefix(strings.ToLower(v), lower)
+		}, nil
+	case Substring:
+		lower := strings.ToLower(value)
+		return func(v string) bool {
+			return strings.Contains(strings.ToLower(v), lower)
+		}, nil
+	case Glob:
+		pattern := regexp.MustCompile(globPattern(value))
+		return pattern.MatchString, nil
+	case Text:
+		return func(v string) bool {
+			return fulltext.ContainsAll(v, value)
+		}, nil
+	case Regex:
+		pattern, err := Compile(value)
+		if err != nil {
+			return nil, err
+		}
+		return pattern.MatchString, nil
+	case Range:
+		// numbers and timestamps are compared differently so the matcher is built by NewIntRange or NewTimeRange
+		return nil, fmt.Errorf("range match mode needs a number or date field")
+	default:
+		return nil, fmt.Errorf("unknown match mode %q", mode)
+	}
+}
+
+// Compile parses a regex mode search value, the error describes where the pattern is invalid
+func Compile(value string) (*regexp.Regexp, error) {
+	pattern, err := regexp.Compile(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %s", value, strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+	}
+	return pattern, nil
+}
+
+// globPattern converts a glob to an anchored case insensitive regular expression
+func globPattern(glob string) string {
+	var pattern strings.Builder
+	pattern.WriteString("(?is)^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			pattern.WriteString(".*")
+		case '?':
+			pattern.WriteString(".")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	pattern.WriteString("$")
+	return pattern.String()
+}
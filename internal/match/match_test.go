@@ -0,0 +1,184 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	for _, mode := range Modes {
+		assert.True(t, Valid(mode), mode)
+	}
+	assert.True(t, Valid(""))
+	assert.False(t, Valid("fuzzy"))
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		test   string
+		mode   string
+		value  string
+		input  string
+		result bool
+	}{
+		{
+			test:   "BlankIsExact",
+			mode:   "",
+			value:  "Francisca Rasmussen",
+			input:  "Francisca Rasmussen",
+			result: true,
+		},
+		{
+			test:   "ExactCaseDiffers",
+			mode:   Exact,
+			value:  "francisca rasmussen",
+			input:  "Francisca Rasmussen",
+			result: false,
+		},
+		{
+			test:   "IgnoreCase",
+			mode:   IgnoreCase,
+			value:  "francisca rasmussen",
+			input:  "Francisca Rasmussen",
+			result: true,
+		},
+		{
+			test:   "IgnoreCasePartial",
+			mode:   IgnoreCase,
+			value:  "francisca",
+			input:  "Francisca Rasmussen",
+			result: false,
+		},
+		{
+			test:   "Prefix",
+			mode:   Prefix,
+			value:  "coffey",
+			input:  "coffeyrasmussen@flotonic.com",
+			result: true,
+		},
+		{
+			test:   "PrefixNotAtStart",
+			mode:   Prefix,
+			value:  "rasmussen",
+			input:  "coffeyrasmussen@flotonic.com",
+			result: false,
+		},
+		{
+			test:   "Substring",
+			mode:   Substring,
+			value:  "francisca",
+			input:  "Francisca Rasmussen",
+			result: true,
+		},
+		{
+			test:   "SubstringNoMatch",
+			mode:   Substring,
+			value:  "korea",
+			input:  "A Catastrophe in Micronesia",
+			result: false,
+		},
+		{
+			test:   "GlobStar",
+			mode:   Glob,
+			value:  "*@flotonic.com",
+			input:  "coffeyrasmussen@flotonic.com",
+			result: true,
+		},
+		{
+			test:   "GlobQuestionMark",
+			mode:   Glob,
+			value:  "8335-???-718",
+			input:  "8335-422-718",
+			result: true,
+		},
+		{
+			test:   "GlobMetaCharactersAreLiteral",
+			mode:   Glob,
+			value:  "a catastrophe in korea (north)",
+			input:  "A Catastrophe in Korea (North)",
+			result: true,
+		},
+		{
+			test:   "GlobAnchored",
+			mode:   Glob,
+			value:  "*korea",
+			input:  "A Catastrophe in Korea (North)",
+			result: false,
+		},
+		{
+			test:   "TextAllWords",
+			mode:   Text,
+			value:  "korea catastrophes",
+			input:  "A Catastrophe in Korea (North)",
+			result: true,
+		},
+		{
+			test:   "TextMissingWord",
+			mode:   Text,
+			value:  "korea drama",
+			input:  "A Catastrophe in Korea (North)",
+			result: false,
+		},
+		{
+			test:   "RegexAnchoredPhone",
+			mode:   Regex,
+			value:  "^8335-",
+			input:  "8335-422-718",
+			result: true,
+		},
+		{
+			test:   "RegexUnanchored",
+			mode:   Regex,
+			value:  "422",
+			input:  "8335-422-718",
+			result: true,
+		},
+		{
+			test:   "RegexAlternation",
+			mode:   Regex,
+			value:  "@(flotonic|zentix)\\.com$",
+			input:  "coffeyrasmussen@flotonic.com",
+			result: true,
+		},
+		{
+			test:   "RegexCaseSensitive",
+			mode:   Regex,
+			value:  "^francisca",
+			input:  "Francisca Rasmussen",
+			result: false,
+		},
+		{
+			test:   "RegexIgnoreCaseFlag",
+			mode:   Regex,
+			value:  "(?i)^francisca",
+			input:  "Francisca Rasmussen",
+			result: true,
+		},
+	}
+
+	for _, tt := range tests {
+		matcher, err := New(tt.mode, tt.value)
+		assert.Nil(t, err, tt.test)
+		assert.Equal(t, tt.result, matcher(tt.input), tt.test)
+	}
+
+	_, err := New("fuzzy", "value")
+	assert.NotNil(t, err)
+
+	_, err = New(Regex, "[8335-")
+	assert.NotNil(t, err)
+
+	_, err = New(Range, ">5")
+	assert.NotNil(t, err)
+}
+
+func TestCompile(t *testing.T) {
+	pattern, err := Compile("^8335-")
+	assert.Nil(t, err)
+	assert.True(t, pattern.MatchString("8335-422-718"))
+
+	pattern, err = Compile("(unclosed")
+	assert.Nil(t, pattern)
+	assert.EqualError(t, err, `invalid regular expression "(unclosed": missing closing ): `+"`(unclosed`")
+}
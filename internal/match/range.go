@@ -0,0 +1,140 @@
+package match
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nicholas-boyson/wordsearch/internal/timestamp"
+)
+
+// betweenSeparator separates the lower and upper bound of a between range e.g. 2016-01-01..2016-06-30
+const betweenSeparator = ".."
+
+// Bounds the parsed bounds of a range, a missing bound is open
+type Bounds struct {
+	Lower          string
+	Upper          string
+	HasLower       bool
+	HasUpper       bool
+	LowerInclusive bool
+	UpperInclusive bool
+}
+
+// IsRange reports whether value is written as a range: >x, >=x, <x, <=x, x..y or between x and y
+func IsRange(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, ">") || strings.HasPrefix(value, "<") ||
+		strings.Contains(value, betweenSeparator) || strings.HasPrefix(strings.ToLower(value), "between ")
+}
+
+// ParseRange parses a range value, between ranges include both bounds
+func ParseRange(value string) (Bounds, error) {
+	value = strings.TrimSpace(value)
+	var b Bounds
+	switch {
+	case strings.HasPrefix(value, ">="):
+		b.Lower, b.HasLower, b.LowerInclusive = strings.TrimSpace(value[2:]), true, true
+	case strings.HasPrefix(value, ">"):
+		b.Lower, b.HasLower = strings.TrimSpace(value[1:]), true
+	case strings.HasPrefix(value, "<="):
+		b.Upper, b.HasUpper, b.UpperInclusive = strings.TrimSpace(value[2:]), true, true
+	case strings.HasPrefix(value, "<"):
+		b.Upper, b.HasUpper = strings.TrimSpace(value[1:]), true
+	case strings.HasPrefix(strings.ToLower(value), "between "):
+		rest := value[len("between "):]
+		i := strings.Index(strings.ToLower(rest), " and ")
+		if i < 0 {
+			return b, fmt.Errorf("range %q must be written as between x and y", value)
+		}
+		b = Bounds{Lower: strings.TrimSpace(rest[:i]), Upper: strings.TrimSpace(rest[i+len(" and "):]), HasLower: true, HasUpper: true, LowerInclusive: true, UpperInclusive: true}
+	case strings.Contains(value, betweenSeparator):
+		parts := strings.SplitN(value, betweenSeparator, 2)
+		b = Bounds{Lower: strings.TrimSpace(parts[0]), Upper: strings.TrimSpace(parts[1]), HasLower: true, HasUpper: true, LowerInclusive: true, UpperInclusive: true}
+	default:
+		return b, fmt.Errorf("range %q must start with >, >=, < or <=, or be written as x..y or between x and y", value)
+	}
+	if (b.HasLower && b.Lower == "") || (b.HasUpper && b.Upper == "") {
+		return b, fmt.Errorf("range %q is missing a bound", value)
+	}
+	return b, nil
+}
+
+// NewIntRange returns the matcher accepting whole numbers within the range, values that are not numbers never match
+func NewIntRange(value string) (Matcher, error) {
+	b, err := ParseRange(value)
+	if err != nil {
+		return nil, err
+	}
+	var lower, upper int64
+	if b.HasLower {
+		if lower, err = strconv.ParseInt(b.Lower, 10, 64); err != nil {
+			return nil, fmt.Errorf("range %q: %q is not a whole number", value, b.Lower)
+		}
+	}
+	if b.HasUpper {
+		if upper, err = strconv.ParseInt(b.Upper, 10, 64); err != nil {
+			return nil, fmt.Errorf("range %q: %q is not a whole number", value, b.Upper)
+		}
+	}
+	return func(v string) bool {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return false
+		}
+		if b.HasLower && (n < lower || (n == lower && !b.LowerInclusive)) {
+			return false
+		}
+		if b.HasUpper && (n > upper || (n == upper && !b.UpperInclusive)) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// NewTimeRange returns the matcher accepting timestamps within the range, values that are not timestamps never match.
+// Bounds with an offset compare instants, bounds without one compare the local date and time of each record
+func NewTimeRange(value string) (Matcher, error) {
+	b, err := ParseRange(value)
+	if err != nil {
+		return nil, err
+	}
+	var lower, upper time.Time
+	var lowerZoned, upperZoned bool
+	if b.HasLower {
+		if lower, lowerZoned, err = timestamp.ParseBound(b.Lower); err != nil {
+			return nil, fmt.Errorf("range %q: %s", value, err)
+		}
+	}
+	if b.HasUpper {
+		if upper, upperZoned, err = timestamp.ParseBound(b.Upper); err != nil {
+			return nil, fmt.Errorf("range %q: %s", value, err)
+		}
+	}
+	return func(v string) bool {
+		t, err := timestamp.Parse(v)
+		if err != nil {
+			return false
+		}
+		if b.HasLower && !after(t, lower, lowerZoned, b.LowerInclusive) {
+			return false
+		}
+		if b.HasUpper && !after(upper, t, upperZoned, b.UpperInclusive) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// after reports whether a is after b, or equal when inclusive, comparing local date and time when not zoned.
+// b is the bound when checking the lower bound and a is the bound when checking the upper bound
+func after(a time.Time, b time.Time, zoned bool, inclusive bool) bool {
+	if !zoned {
+		a, b = timestamp.Wall(a), timestamp.Wall(b)
+	}
+	if a.Equal(b) {
+		return inclusive
+	}
+	return a.After(b)
+}
@@ -0,0 +1,77 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRange(t *testing.T) {
+	for _, value := range []string{">5", ">=5", "<2016-08-01", "<=2016", "10..20", "between 10 and 20", "Between 2016 AND 2017"} {
+		assert.True(t, IsRange(value), value)
+	}
+	for _, value := range []string{"5", "2016-08-01", "pending", ""} {
+		assert.False(t, IsRange(value), value)
+	}
+}
+
+func TestNewIntRange(t *testing.T) {
+	tests := []struct {
+		test   string
+		value  string
+		input  string
+		result bool
+	}{
+		{test: "GreaterThan", value: ">50", input: "51", result: true},
+		{test: "GreaterThanExcludesBound", value: ">50", input: "50", result: false},
+		{test: "GreaterOrEqualIncludesBound", value: ">=50", input: "50", result: true},
+		{test: "LessThan", value: "<50", input: "49", result: true},
+		{test: "LessThanExcludesBound", value: "< 50", input: "50", result: false},
+		{test: "LessOrEqualIncludesBound", value: "<=50", input: "50", result: true},
+		{test: "BetweenIncludesBounds", value: "10..20", input: "20", result: true},
+		{test: "BetweenOutside", value: "10..20", input: "21", result: false},
+		{test: "BetweenWords", value: "between 10 and 20", input: "10", result: true},
+		{test: "NotANumber", value: ">50", input: "fifty", result: false},
+	}
+
+	for _, tt := range tests {
+		matcher, err := NewIntRange(tt.value)
+		assert.Nil(t, err, tt.test)
+		assert.Equal(t, tt.result, matcher(tt.input), tt.test)
+	}
+
+	for _, value := range []string{"50", ">fifty", "10..", "between 10", ">"} {
+		_, err := NewIntRange(value)
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestNewTimeRange(t *testing.T) {
+	tests := []struct {
+		test   string
+		value  string
+		input  string
+		result bool
+	}{
+		{test: "BeforeDate", value: "<2016-08-01", input: "2016-07-31T02:37:50 -10:00", result: true},
+		{test: "BeforeDateComparesLocalTime", value: "<2016-08-01", input: "2016-07-31T20:00:00 -10:00", result: true},
+		{test: "BeforeDateExcludesDate", value: "<2016-08-01", input: "2016-08-01T00:00:00 -10:00", result: false},
+		{test: "BeforeYear", value: "<2014", input: "2013-12-31T23:59:59 -10:00", result: true},
+		{test: "AfterYear", value: ">2014", input: "2013-12-31T23:59:59 -10:00", result: false},
+		{test: "ZonedBoundComparesInstants", value: "<2016-08-01T00:00:00 +00:00", input: "2016-07-31T20:00:00 -10:00", result: false},
+		{test: "Between", value: "2016-01-01..2016-06-30", input: "2016-04-28T11:19:34 -10:00", result: true},
+		{test: "BetweenWords", value: "between 2016-01 and 2016-03", input: "2016-04-28T11:19:34 -10:00", result: false},
+		{test: "NotATimestamp", value: ">2014", input: "", result: false},
+	}
+
+	for _, tt := range tests {
+		matcher, err := NewTimeRange(tt.value)
+		assert.Nil(t, err, tt.test)
+		assert.Equal(t, tt.result, matcher(tt.input), tt.test)
+	}
+
+	for _, value := range []string{"2016-08-01", "<yesterday", "2016..", "between 2016"} {
+		_, err := NewTimeRange(value)
+		assert.NotNil(t, err, value)
+	}
+}
@@ -0,0 +1,102 @@
+package organizations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexSearch(t *testing.T) {
+	orgList := []Organization{
+		{Id: 101, Name: "Enthaze", DomainNames: []string{"kage.com", "ecratic.com"}, Details: "MegaCorp", Tags: []string{"West", "West"}},
+		{Id: 102, Name: "Nutralab", DomainNames: []string{"trollery.com"}, Details: "Non profit", Tags: []string{"Cherry", "West"}},
+		{Id: 103, Name: "Plasmos", Details: "MegaCorp", SharedTickets: true},
+	}
+	idx := BuildIndex(orgList)
+
+	tests := []struct {
+		test   string
+		ident  string
+		value  string
+		result []Organization
+	}{
+		{
+			test:   "IntIdent",
+			ident:  "_id",
+			value:  "102",
+			result: []Organization{orgList[1]},
+		},
+		{
+			test:   "StringIdentMultipleResults",
+			ident:  "details",
+			value:  "MegaCorp",
+			result: []Organization{orgList[0], orgList[2]},
+		},
+		{
+			test:   "DomainNames",
+			ident:  "domain_names",
+			value:  "ecratic.com",
+			result: []Organization{orgList[0]},
+		},
+		{
+			test:   "BoolIdent",
+			ident:  "shared_tickets",
+			value:  "true",
+			result: []Organization{orgList[2]},
+		},
+		{
+			test:   "TagsDuplicateTagReturnedOnce",
+			ident:  "tags",
+			value:  "West",
+			result: []Organization{orgList[0], orgList[1]},
+		},
+		{
+			test:   "NoResult",
+			ident:  "name",
+			value:  "Zentry",
+			result: nil,
+		},
+		{
+			test:   "InvalidIdent",
+			ident:  "invalid",
+			value:  "101",
+			result: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		result := idx.Search(tt.ident, tt.value)
+		assert.Equal(t, Records(tt.result), result, tt.test)
+	}
+}
+
+func TestIndexSearchText(t *testing.T) {
+	input := []Organization{{Id: 101, Details: "MegaCorp"}, {Id: 102, Details: "Non profit"}}
+	idx := BuildIndex(input)
+	assert.Equal(t, Records([]Organization{input[1]}), idx.SearchText("profit", "details"))
+	assert.Nil(t, idx.SearchText("missing"))
+	assert.True(t, registry.FullText("details"))
+	assert.False(t, registry.FullText("_id"))
+}
+
+func TestIndexMatchesSearchOrganizations(t *testing.T) {
+	orgs, err := LoadOrganizations("../source_data/organizations.json")
+	assert.Nil(t, err)
+	idx := BuildIndex(orgs)
+	for _, ident := range registry.Names() {
+		for _, org := range orgs {
+			for _, value := range FieldValues(org, ident) {
+				assert.Equal(t, Records(SearchOrganizations(orgs, ident, value)), idx.Search(ident, value))
+			}
+		}
+	}
+}
+
+func BenchmarkOrganisationIndexSearch(b *testing.B) {
+	orgs, err := LoadOrganizations("../source_data/organizations.json")
+	assert.Nil(b, err)
+	idx := BuildIndex(orgs)
+	for i := 0; i < b.N; i++ {
+		_ = idx.Search("_id", "125")
+	}
+}
@@ -2,10 +2,10 @@ package organizations
 
 import (
 	"encoding/json"
-	"io"
-	"os"
 	"path/filepath"
-	"strconv"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
 )
 
 //Organization struct defining an organization
@@ -15,106 +15,98 @@ type Organization struct {
 	ExternalId    string   `json:"external_id"`
 	Name          string   `json:"name"`
 	DomainNames   []string `json:"domain_names"`
-	CreatedAt     string   `json:"created_at"`
-	Details       string   `json:"details"`
+	CreatedAt     string   `json:"created_at" search:"time"`
+	Details       string   `json:"details" search:"text"`
 	SharedTickets bool     `json:"shared_tickets"`
 	Tags          []string `json:"tags"`
 }
 
 const organizationsFilePath = "internal/source_data/organizations.json"
 
-// LoadOrganizations process to load the organizations datastore into a slice
-func LoadOrganizations(testFilePath string) ([]Organization, error) {
-	//open the files
-	absPath, err := filepath.Abs(organizationsFilePath)
-	if err != nil {
-		return nil, err
-	}
-	filePath := absPath
-	if testFilePath != "" {
-		filePath = testFilePath
-	}
-	organizationsFilePtr, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer func() (err error) {
-		// ensure we close resource
-		if rErr := organizationsFilePtr.Close(); rErr != nil {
-			return rErr
-		}
-		return nil
-	}()
+// registry the searchable fields of a organization, read from the json and search tags of Organization
+var registry = fields.New(Organization{})
 
-	decoder := json.NewDecoder(organizationsFilePtr)
+// LoadOrganizations process to load the organizations datastore into a slice, an empty path loads the bundled source data
+func LoadOrganizations(dataFilePath string) ([]Organization, error) {
 	var organizations []Organization
-	err = decoder.Decode(&organizations)
-	if err != nil && err != io.EOF {
-		// return error when the JSON is invalid and not empty
+	err := StreamOrganizations(dataFilePath, jsonstream.Options{}, func(org Organization) error {
+		organizations = append(organizations, org)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	return organizations, nil
 }
 
+// StreamOrganizations reads the organizations file one organization at a time without holding the whole file in
+// memory, calling fn with every organization, opts reports progress and rejects invalid organizations. The file holds
+// a JSON array or one JSON organization per line and may be gzip compressed, an empty path reads the bundled source data
+func StreamOrganizations(dataFilePath string, opts jsonstream.Options, fn func(Organization) error) error {
+	filePath := dataFilePath
+	if filePath == "" {
+		absPath, err := filepath.Abs(organizationsFilePath)
+		if err != nil {
+			return err
+		}
+		filePath = absPath
+	}
+	return jsonstream.ReadFile(filePath, opts, func(decoder *json.Decoder) error {
+		var org Organization
+		if err := decoder.Decode(&org); err != nil {
+			return err
+		}
+		return fn(org)
+	})
+}
+
 //SearchOrganizations function to search over all organizations based on ident and value provided
-func SearchOrganizations(organizations []Organization, ident string, value string) (organizationList []Organization) {
+func SearchOrganizations(organizations []Organization, ident string, value string) []Organization {
+	return SearchOrganizationsMatch(organizations, ident, func(v string) bool {
+		return v == value
+	})
+}
+
+// SearchOrganizationsMatch return slice of organizations with a value for ident accepted by match
+func SearchOrganizationsMatch(organizations []Organization, ident string, match func(string) bool) (organizationList []Organization) {
+	if !ValidSearchTerms(ident) {
+		// Invalid ident so return
+		return
+	}
 	for _, org := range organizations {
-		switch ident {
-		case "_id":
-			if strconv.Itoa(org.Id) == value {
-				organizationList = append(organizationList, org)
-			}
-		case "url":
-			if org.URL == value {
-				organizationList = append(organizationList, org)
-			}
-		case "external_id":
-			if org.ExternalId == value {
-				organizationList = append(organizationList, org)
-			}
-		case "name":
-			if org.Name == value {
-				organizationList = append(organizationList, org)
-			}
-		case "domain_names":
-			for _, dn := range org.DomainNames {
-				if dn == value {
-					organizationList = append(organizationList, org)
-				}
-			}
-		case "created_at":
-			if org.CreatedAt == value {
-				organizationList = append(organizationList, org)
-			}
-		case "details":
-			if org.Details == value {
-				organizationList = append(organizationList, org)
-			}
-		case "shared_tickets":
-			if strconv.FormatBool(org.SharedTickets) == value {
-				organizationList = append(organizationList, org)
-			}
-		case "tags":
-			for _, tag := range org.Tags {
-				if tag == value {
-					organizationList = append(organizationList, org)
-				}
-			}
-		default:
-			// Invalid ident so return
-			return
+		if MatchOrganization(org, ident, match) {
+			organizationList = append(organizationList, org)
 		}
 	}
 	return
 }
 
-// ValidSearchTerms checks an ident against a list of valid options and returns true if it exists
+// MatchOrganization reports whether any value of the organization for ident is accepted by match, tags are matched one by one
+func MatchOrganization(org Organization, ident string, match func(string) bool) bool {
+	return registry.Match(org, ident, match)
+}
+
+// FieldValues returns the searchable string values of an organization for an ident
+func FieldValues(org Organization, ident string) []string {
+	return registry.Values(org, ident)
+}
+
+// ValidSearchTerms checks an ident against the searchable fields and returns true if it exists
 func ValidSearchTerms(ident string) bool {
-	validIdents := []string{"_id", "url", "external_id", "name", "domain_names", "created_at", "details", "shared_tickets", "tags"}
-	for _, v := range validIdents {
-		if v == ident {
-			return true
-		}
-	}
-	return false
+	return registry.Has(ident)
+}
+
+// IntField reports whether ident holds whole numbers
+func IntField(ident string) bool {
+	return registry.Is(ident, fields.Int)
+}
+
+// TimeField reports whether ident holds timestamps
+func TimeField(ident string) bool {
+	return registry.Is(ident, fields.Time)
+}
+
+// Fields returns the searchable fields of an organization
+func Fields() *fields.Registry {
+	return registry
 }
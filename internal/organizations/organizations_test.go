@@ -2,6 +2,7 @@ package organizations
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,7 +27,7 @@ func TestLoadOrganizations(t *testing.T) {
 		{
 			test:         "InvalidOrganizationsFile1",
 			testFilePath: "test_files/invalid_json_organizations.json",
-			err:          errors.New("json: cannot unmarshal string into Go struct field Organization._id of type int"),
+			err:          errors.New("record 0 field _id: json: cannot unmarshal string into Go struct field Organization._id of type int"),
 		},
 		{
 			test:         "GoodOrganizationsFile",
@@ -95,6 +96,15 @@ func TestValidSearchTerms(t *testing.T) {
 	}
 }
 
+func TestFieldTypes(t *testing.T) {
+	assert.True(t, IntField("_id"))
+	assert.False(t, IntField("created_at"))
+	assert.True(t, TimeField("created_at"))
+	assert.False(t, TimeField("_id"))
+	assert.False(t, IntField("name"))
+	assert.False(t, TimeField("name"))
+}
+
 func TestSearchOrganizations(t *testing.T) {
 	tests := []struct {
 		test   string
@@ -429,3 +439,55 @@ func BenchmarkOrganisationLoadThenSearch(b *testing.B) {
 		_ = SearchOrganizations(orgs, "_id", "125")
 	}
 }
+
+func TestSearchOrganizationsMatch(t *testing.T) {
+	input := []Organization{
+		{Id: 101, Name: "Enthaze", DomainNames: []string{"kage.com", "ecratic.com"}},
+		{Id: 102, Name: "Nutralab", DomainNames: []string{"trollery.com"}},
+	}
+	tests := []struct {
+		test   string
+		ident  string
+		match  func(string) bool
+		result []Organization
+	}{
+		{
+			test:  "Prefix",
+			ident: "name",
+			match: func(v string) bool {
+				return strings.HasPrefix(v, "Enth")
+			},
+			result: input[:1],
+		},
+		{
+			test:  "MultiValueMatchedOnce",
+			ident: "domain_names",
+			match: func(v string) bool {
+				return true
+			},
+			result: input,
+		},
+		{
+			test:  "MultiValueSubstring",
+			ident: "domain_names",
+			match: func(v string) bool {
+				return strings.Contains(strings.ToLower(v), "ecratic")
+			},
+			result: input[:1],
+		},
+		{
+			test:  "InvalidIdent",
+			ident: "invalid",
+			match: func(v string) bool {
+				return true
+			},
+			result: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		result := SearchOrganizationsMatch(input, tt.ident, tt.match)
+		assert.Equal(t, tt.result, result, tt.test)
+		assert.Equal(t, len(tt.result) > 0, MatchOrganization(input[0], tt.ident, tt.match), tt.test)
+	}
+}
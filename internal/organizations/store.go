@@ -0,0 +1,33 @@
+package organizations
+
+import (
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+)
+
+// Records returns the organizations as the records of a store, in order
+func Records(organizations []Organization) (records []interface{}) {
+	for _, org := range organizations {
+		records = append(records, org)
+	}
+	return
+}
+
+// BuildIndex builds an index over every searchable field of the organizations, built once after LoadOrganizations
+func BuildIndex(organizations []Organization) *store.Index {
+	return store.BuildIndex(registry, Records(organizations))
+}
+
+// LoadStore streams the organizations file into an indexed in-memory store, opts reports progress and rejects invalid organizations.
+// An empty path loads the bundled source data
+func LoadStore(dataFilePath string, opts jsonstream.Options) (*store.Index, error) {
+	var records []interface{}
+	err := StreamOrganizations(dataFilePath, opts, func(org Organization) error {
+		records = append(records, org)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store.BuildIndex(registry, records), nil
+}
@@ -0,0 +1,40 @@
+package organizations
+
+import (
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexStore(t *testing.T) {
+	orgList := []Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}, {Id: 103, Name: "Zentix"}}
+	var idx store.Store = BuildIndex(orgList)
+
+	found, ok := idx.Get("102")
+	assert.True(t, ok)
+	assert.Equal(t, orgList[1], found)
+	_, ok = idx.Get("999")
+	assert.False(t, ok)
+
+	assert.Equal(t, 3, idx.Len())
+	var each []interface{}
+	idx.Each(1, 3, func(record interface{}) {
+		each = append(each, record)
+	})
+	assert.Equal(t, Records(orgList[1:]), each)
+}
+
+func TestLoadStore(t *testing.T) {
+	records := 0
+	store, err := LoadStore("test_files/good_organizations.json", jsonstream.Options{Progress: func(p jsonstream.Progress) {
+		records = p.Records
+	}})
+	assert.Nil(t, err)
+	assert.True(t, store.Len() > 0)
+	assert.Equal(t, store.Len(), records)
+
+	_, err = LoadStore("test_files/missing.json", jsonstream.Options{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,330 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// And keyword joining two expressions that must both hold, adjacent expressions are joined by AND when omitted
+	And = "AND"
+	// Or keyword joining two expressions where either must hold
+	Or = "OR"
+	// Not keyword negating the expression that follows
+	Not = "NOT"
+)
+
+// Term a single field compared to a value
+type Term struct {
+	Field string
+	Value string
+}
+
+// Expr a parsed query expression evaluated against a record
+type Expr interface {
+	// Eval reports whether the record holds the expression, match reports whether the record holds a single term
+	Eval(match func(term Term) bool) bool
+	// Terms returns every term of the expression in the order written
+	Terms() []Term
+	// String returns the expression in query syntax with every AND and OR in parentheses
+	String() string
+}
+
+// AndExpr holds when both sides hold
+type AndExpr struct {
+	Left  Expr
+	Right Expr
+}
+
+// OrExpr holds when either side holds
+type OrExpr struct {
+	Left  Expr
+	Right Expr
+}
+
+// NotExpr holds when the expression does not hold
+type NotExpr struct {
+	Expr Expr
+}
+
+// Eval reports whether the record holds the term
+func (t Term) Eval(match func(term Term) bool) bool {
+	return match(t)
+}
+
+// Terms returns the term
+func (t Term) Terms() []Term {
+	return []Term{t}
+}
+
+// String returns the term as field:value, quoting the value when it holds spaces, quotes or parentheses
+func (t Term) String() string {
+	if t.Value == "" || strings.ContainsAny(t.Value, " \t\"()") {
+		return t.Field + ":" + fmt.Sprintf("%q", t.Value)
+	}
+	return t.Field + ":" + t.Value
+}
+
+// Eval reports whether the record holds both sides, the right side is skipped when the left does not hold
+func (e AndExpr) Eval(match func(term Term) bool) bool {
+	return e.Left.Eval(match) && e.Right.Eval(match)
+}
+
+// Terms returns the terms of both sides
+func (e AndExpr) Terms() []Term {
+	return append(e.Left.Terms(), e.Right.Terms()...)
+}
+
+// String returns both sides joined by AND
+func (e AndExpr) String() string {
+	return "(" + e.Left.String() + " " + And + " " + e.Right.String() + ")"
+}
+
+// Eval reports whether the record holds either side, the right side is skipped when the left holds
+func (e OrExpr) Eval(match func(term Term) bool) bool {
+	return e.Left.Eval(match) || e.Right.Eval(match)
+}
+
+// Terms returns the terms of both sides
+func (e OrExpr) Terms() []Term {
+	return append(e.Left.Terms(), e.Right.Terms()...)
+}
+
+// String returns both sides joined by OR
+func (e OrExpr) String() string {
+	return "(" + e.Left.String() + " " + Or + " " + e.Right.String() + ")"
+}
+
+// Eval reports whether the record does not hold the expression
+func (e NotExpr) Eval(match func(term Term) bool) bool {
+	return !e.Expr.Eval(match)
+}
+
+// Terms returns the terms of the negated expression
+func (e NotExpr) Terms() []Term {
+	return e.Expr.Terms()
+}
+
+// String returns the expression prefixed by NOT
+func (e NotExpr) String() string {
+	return Not + " " + e.Expr.String()
+}
+
+// Parse parses a query such as `status:pending AND (priority:high OR priority:urgent) NOT via:chat`.
+// Terms are field:value with the value quoted when it holds spaces, keywords are matched ignoring case,
+// NOT binds tightest then AND then OR, and terms written next to each other are joined by AND
+func Parse(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// tokenKind the kinds of token in a query
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+// token a keyword, parenthesis or term read from the query
+type token struct {
+	kind tokenKind
+	term Term
+	text string
+}
+
+// String describes the token in parse errors
+func (t token) String() string {
+	return fmt.Sprintf("%q", t.text)
+}
+
+// tokenize splits the query into keywords, parentheses and field:value terms
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")"})
+			i++
+		default:
+			start := i
+			for i < len(runes) && runes[i] != ':' && runes[i] != '(' && runes[i] != ')' && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			if i == len(runes) || runes[i] != ':' {
+				kind, ok := keyword(word)
+				if !ok {
+					return nil, fmt.Errorf("expected field:value or AND, OR, NOT at %q", word)
+				}
+				tokens = append(tokens, token{kind: kind, text: word})
+				break
+			}
+			if word == "" {
+				return nil, fmt.Errorf("missing field before ':' at position %d", i+1)
+			}
+			// skip the colon and read the value, quoted values may hold spaces, parentheses and escaped quotes
+			i++
+			value, next, err := readValue(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+			tokens = append(tokens, token{kind: tokenTerm, term: Term{Field: word, Value: value}, text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+// keyword returns the token kind of a keyword ignoring case
+func keyword(word string) (tokenKind, bool) {
+	switch strings.ToUpper(word) {
+	case And:
+		return tokenAnd, true
+	case Or:
+		return tokenOr, true
+	case Not:
+		return tokenNot, true
+	default:
+		return 0, false
+	}
+}
+
+// readValue reads a term value starting at i returning the value and the position after it
+func readValue(runes []rune, i int) (string, int, error) {
+	if i < len(runes) && runes[i] == '"' {
+		var value strings.Builder
+		for i++; i < len(runes); i++ {
+			switch runes[i] {
+			case '\\':
+				if i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			case '"':
+				return value.String(), i + 1, nil
+			default:
+				value.WriteRune(runes[i])
+			}
+		}
+		return "", i, fmt.Errorf("missing closing quote")
+	}
+	start := i
+	for i < len(runes) && runes[i] != '(' && runes[i] != ')' && !unicode.IsSpace(runes[i]) {
+		i++
+	}
+	return string(runes[start:i]), i, nil
+}
+
+// parser recursive descent parser over the query tokens
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// peek returns the kind of the next token, ok is false at the end of the query
+func (p *parser) peek() (tokenKind, bool) {
+	if p.pos >= len(p.tokens) {
+		return 0, false
+	}
+	return p.tokens[p.pos].kind, true
+}
+
+// parseOr parses expressions joined by OR
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if kind, ok := p.peek(); !ok || kind != tokenOr {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = OrExpr{Left: left, Right: right}
+	}
+}
+
+// parseAnd parses expressions joined by AND or written next to each other
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		kind, ok := p.peek()
+		if !ok || kind == tokenOr || kind == tokenClose {
+			return left, nil
+		}
+		if kind == tokenAnd {
+			p.pos++
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = AndExpr{Left: left, Right: right}
+	}
+}
+
+// parseNot parses a term or parenthesised expression with any number of NOT before it
+func (p *parser) parseNot() (Expr, error) {
+	kind, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	switch kind {
+	case tokenNot:
+		p.pos++
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Expr: expr}, nil
+	case tokenOpen:
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if kind, ok := p.peek(); !ok || kind != tokenClose {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	case tokenTerm:
+		p.pos++
+		return p.tokens[p.pos-1].term, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+}
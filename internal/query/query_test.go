@@ -0,0 +1,177 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		test   string
+		input  string
+		result string
+	}{
+		{
+			test:   "SingleTerm",
+			input:  "status:pending",
+			result: "status:pending",
+		},
+		{
+			test:   "AndBindsTighterThanOr",
+			input:  "status:pending AND priority:high OR priority:urgent",
+			result: "((status:pending AND priority:high) OR priority:urgent)",
+		},
+		{
+			test:   "Parentheses",
+			input:  "status:pending AND (priority:high OR priority:urgent) NOT via:chat",
+			result: "((status:pending AND (priority:high OR priority:urgent)) AND NOT via:chat)",
+		},
+		{
+			test:   "ImplicitAnd",
+			input:  "status:pending priority:high",
+			result: "(status:pending AND priority:high)",
+		},
+		{
+			test:   "KeywordsIgnoreCase",
+			input:  "status:pending and not priority:low or tags:Ohio",
+			result: "((status:pending AND NOT priority:low) OR tags:Ohio)",
+		},
+		{
+			test:   "QuotedValue",
+			input:  `subject:"A Catastrophe in Korea (North)" AND type:incident`,
+			result: `(subject:"A Catastrophe in Korea (North)" AND type:incident)`,
+		},
+		{
+			test:   "EscapedQuote",
+			input:  `signature:"Don't \"Worry\""`,
+			result: `signature:"Don't \"Worry\""`,
+		},
+		{
+			test:   "BlankValue",
+			input:  `description:""`,
+			result: `description:""`,
+		},
+		{
+			test:   "ValueHoldsColon",
+			input:  "url:http://initech.zendesk.com",
+			result: "url:http://initech.zendesk.com",
+		},
+		{
+			test:   "DoubleNot",
+			input:  "NOT NOT active:true",
+			result: "NOT NOT active:true",
+		},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.input)
+		assert.Nil(t, err, tt.test)
+		assert.Equal(t, tt.result, expr.String(), tt.test)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		test  string
+		input string
+		err   string
+	}{
+		{
+			test:  "Empty",
+			input: "  ",
+			err:   "empty query",
+		},
+		{
+			test:  "BareWord",
+			input: "pending",
+			err:   `expected field:value or AND, OR, NOT at "pending"`,
+		},
+		{
+			test:  "MissingField",
+			input: ":pending",
+			err:   "missing field before ':' at position 1",
+		},
+		{
+			test:  "MissingClosingQuote",
+			input: `subject:"A Catastrophe`,
+			err:   "missing closing quote",
+		},
+		{
+			test:  "MissingClosingParenthesis",
+			input: "(status:pending OR status:open",
+			err:   "missing closing parenthesis",
+		},
+		{
+			test:  "UnexpectedClosingParenthesis",
+			input: "status:pending)",
+			err:   `unexpected ")"`,
+		},
+		{
+			test:  "TrailingOperator",
+			input: "status:pending AND",
+			err:   "unexpected end of query",
+		},
+		{
+			test:  "DoubleOperator",
+			input: "status:pending OR AND priority:high",
+			err:   `unexpected "AND"`,
+		},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.input)
+		assert.Nil(t, expr, tt.test)
+		assert.EqualError(t, err, tt.err, tt.test)
+	}
+}
+
+func TestEval(t *testing.T) {
+	record := map[string]string{"status": "pending", "priority": "urgent", "via": "web"}
+	holds := func(term Term) bool {
+		return record[term.Field] == term.Value
+	}
+	tests := []struct {
+		test   string
+		input  string
+		result bool
+	}{
+		{
+			test:   "Term",
+			input:  "status:pending",
+			result: true,
+		},
+		{
+			test:   "AndOrNot",
+			input:  "status:pending AND (priority:high OR priority:urgent) NOT via:chat",
+			result: true,
+		},
+		{
+			test:   "NotHolds",
+			input:  "status:pending NOT via:web",
+			result: false,
+		},
+		{
+			test:   "OrNeitherHolds",
+			input:  "priority:high OR priority:low",
+			result: false,
+		},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.input)
+		assert.Nil(t, err, tt.test)
+		assert.Equal(t, tt.result, expr.Eval(holds), tt.test)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	expr, err := Parse("status:pending AND (priority:high OR priority:urgent) NOT via:chat")
+	assert.Nil(t, err)
+	assert.Equal(t, []Term{
+		{Field: "status", Value: "pending"},
+		{Field: "priority", Value: "high"},
+		{Field: "priority", Value: "urgent"},
+		{Field: "via", Value: "chat"},
+	}, expr.Terms())
+}
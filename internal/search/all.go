@@ -0,0 +1,196 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+)
+
+// FieldMatch the records of a group holding the searched value in one of its fields
+type FieldMatch struct {
+	Group  string
+	Field  string
+	Result SearchResult // the records found in the slice of the group, no records are linked
+}
+
+// fieldMatchRecord a field match as written to JSON
+type fieldMatchRecord struct {
+	Group   string      `json:"group"`
+	Field   string      `json:"field"`
+	Count   int         `json:"count"`
+	Records interface{} `json:"records"`
+}
+
+// SearchAll searches Value against every searchable field of every group in the search match mode, for a value
+// whose field is not known. The fields holding the value are returned in group order and the field order of each
+// group. A range is only compared to the number or date fields it can be compared to
+func SearchAll(s Search) (matches []FieldMatch) {
+	for _, group := range Groups() {
+		matches = append(matches, s.fieldMatches(group)...)
+	}
+	return
+}
+
+// fieldMatches returns the fields of the group holding Value in field order, with the records holding it
+func (s Search) fieldMatches(group string) (matches []FieldMatch) {
+	s.Query = nil
+	for _, field := range registryOf(group).Names() {
+		fs := s
+		fs.Group = group
+		fs.Ident = field
+		if s.Match == match.Range {
+			if _, err := fs.rangeMatcher(field, s.Value); err != nil {
+				continue
+			}
+		}
+		if result := fs.find(); result.Count(group) > 0 {
+			matches = append(matches, FieldMatch{Group: group, Field: field, Result: result})
+		}
+	}
+	return
+}
+
+// find returns the records of the search group matching the search ident and value, without linking records
+func (s Search) find() (result SearchResult) {
+	result.add(s.findRecords()...)
+	return
+}
+
+// compileAll checks the search value of a search across every field, of every group or of the wildcard field of a
+// group, a range has to be a number or date range
+func (s *Search) compileAll() error {
+	switch s.Match {
+	case match.Regex:
+		pattern, err := match.Compile(s.Value)
+		if err != nil {
+			return err
+		}
+		s.Pattern = pattern
+	case match.Range:
+		if _, err := match.NewIntRange(s.Value); err == nil {
+			return nil
+		}
+		if _, err := match.NewTimeRange(s.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count returns the number of records holding the value in the field
+func (m FieldMatch) Count() int {
+	return m.Result.Count(m.Group)
+}
+
+// ids returns the id of every record holding the value in the field
+func (m FieldMatch) ids() []string {
+	return m.Result.ids(m.Group)
+}
+
+// records returns the records holding the value in the field
+func (m FieldMatch) records() interface{} {
+	return m.Result.groupRecords(m.Group)
+}
+
+// summaries returns one line describing each record holding the value in the field
+func (m FieldMatch) summaries() []string {
+	return m.Result.summaries(m.Group)
+}
+
+// ids returns the id of every record found in the group
+func (sr SearchResult) ids(group string) (ids []string) {
+	r := registryOf(group)
+	for _, rec := range sr.found(group) {
+		ids = append(ids, r.Values(rec, store.IdField)...)
+	}
+	return
+}
+
+// groupRecords returns the records found in the group
+func (sr SearchResult) groupRecords(group string) interface{} {
+	return sr.found(group)
+}
+
+// summaries returns one line describing each record found in the group
+func (sr SearchResult) summaries(group string) (lines []string) {
+	for _, rec := range sr.found(group) {
+		lines = append(lines, summary(group, rec))
+	}
+	return
+}
+
+// summary returns one line describing a record of the group
+func summary(group string, rec interface{}) string {
+	switch rec := rec.(type) {
+	case organizations.Organization:
+		return display.OrganizationSummary(rec)
+	case tickets.Ticket:
+		return display.TicketSummary(rec)
+	case users.User:
+		return display.UserSummary(rec)
+	default:
+		t, _ := entity.Lookup(group)
+		return display.RecordSummary(t, rec.(entity.Record))
+	}
+}
+
+// groupLabel returns the name of the group as shown to the user
+func groupLabel(group string) string {
+	if t, ok := entity.Lookup(group); ok {
+		return t.Label()
+	}
+	return group
+}
+
+// FieldMatchesDisplayFormat displays the fields holding the value in the output format
+func FieldMatchesDisplayFormat(format string, matches []FieldMatch) {
+	switch format {
+	case display.FormatJSON:
+		display.DisplayJSON(FieldMatchRecords(matches))
+	case display.FormatCSV, display.FormatTSV:
+		if err := FieldMatchesExport(os.Stdout, format, matches); err != nil {
+			display.ExportFailed(err)
+		}
+	default:
+		displayMatches := make([]display.FieldMatch, 0, len(matches))
+		for _, m := range matches {
+			displayMatches = append(displayMatches, display.FieldMatch{Group: groupLabel(m.Group), Field: m.Field, Records: m.summaries()})
+		}
+		display.DisplayFieldMatches(displayMatches)
+	}
+}
+
+// FieldMatchRecords returns the fields holding the value with their records, the group named as its entity
+func FieldMatchRecords(matches []FieldMatch) interface{} {
+	records := make([]fieldMatchRecord, 0, len(matches))
+	for _, m := range matches {
+		records = append(records, fieldMatchRecord{Group: entityName(m.Group), Field: m.Field, Count: m.Count(), Records: m.records()})
+	}
+	return records
+}
+
+// FieldMatchesExport writes the fields holding the value to w as JSON, or as CSV or TSV with one row per group,
+// field and record id
+func FieldMatchesExport(w io.Writer, format string, matches []FieldMatch) error {
+	if !display.ExportFormat(format) {
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+	if format == display.FormatJSON {
+		return display.WriteJSON(w, FieldMatchRecords(matches))
+	}
+	rows := [][]string{{"group", "field", "_id"}}
+	for _, m := range matches {
+		for _, id := range m.ids() {
+			rows = append(rows, []string{entityName(m.Group), m.Field, id})
+		}
+	}
+	return display.WriteDelimited(w, format, rows)
+}
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+// allTestSearch a search across every group of a few built in records and the entity test schema
+func allTestSearch(t *testing.T) Search {
+	return Search{
+		Group: SearchGroupAll,
+		Organizations: organizations.BuildIndex([]organizations.Organization{
+			{Id: 101, Name: "Enthaze", Tags: []string{"Fulton"}},
+		}),
+		Tickets: tickets.BuildIndex([]tickets.Ticket{
+			{Id: "436bf9b0", Subject: "A Catastrophe in Korea", Status: "pending", SubmitterId: 2, OrganizationId: 101},
+		}),
+		Users: users.BuildIndex([]users.User{
+			{Id: 1, Name: "Francisca Rasmussen", CreatedAt: "2016-04-15T05:19:46 -10:00", OrganizationId: 101},
+			{Id: 2, Name: "Cross Barlow", OrganizationId: 102, Tags: []string{"Fulton"}},
+		}),
+		Entities: useTestSchema(t),
+	}
+}
+
+// fieldMatchIds returns the group, field and record ids of each field match
+func fieldMatchIds(matches []FieldMatch) (ids [][]string) {
+	for _, m := range matches {
+		ids = append(ids, append([]string{m.Group, m.Field}, m.ids()...))
+	}
+	return
+}
+
+func TestSearchAll(t *testing.T) {
+	base := allTestSearch(t)
+	tests := []struct {
+		test    string
+		match   string
+		value   string
+		matches [][]string
+	}{
+		{
+			test:  "IdInEveryGroup",
+			value: "2",
+			matches: [][]string{
+				{SearchGroupUsers, "_id", "2"},
+				{SearchGroupTickets, "submitter_id", "436bf9b0"},
+				{"groups", "_id", "2"},
+				{"macros", "group_id", "11", "12"},
+				{"macros", "author_id", "11"},
+			},
+		},
+		{
+			test:  "ListValue",
+			value: "Fulton",
+			matches: [][]string{
+				{SearchGroupUsers, "tags", "2"},
+				{SearchGroupOrganizations, "tags", "101"},
+			},
+		},
+		{
+			test:  "IgnoreCase",
+			match: match.IgnoreCase,
+			value: "BILLING",
+			matches: [][]string{
+				{"groups", "name", "2"},
+			},
+		},
+		{
+			test:  "NumberRangeSkipsTextFields",
+			match: match.Range,
+			value: "101..102",
+			matches: [][]string{
+				{SearchGroupUsers, "organization_id", "1", "2"},
+				{SearchGroupTickets, "organization_id", "436bf9b0"},
+				{SearchGroupOrganizations, "_id", "101"},
+				{"groups", "organization_id", "1", "2"},
+			},
+		},
+		{
+			test:  "DateRange",
+			match: match.Range,
+			value: "<2016-05-01",
+			matches: [][]string{
+				{SearchGroupUsers, "created_at", "1"},
+				{"groups", "created_at", "1"},
+			},
+		},
+		{
+			test:  "NoMatch",
+			value: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		s := base
+		s.Match = tt.match
+		s.Value = tt.value
+		if !assert.Nil(t, s.Compile(), tt.test) {
+			continue
+		}
+		matches := SearchAll(s)
+		assert.Equal(t, tt.matches, fieldMatchIds(matches), tt.test)
+		for _, m := range matches {
+			// a search across every group never links records
+			assert.Nil(t, m.Result.Linked, tt.test)
+		}
+	}
+}
+
+func TestSearchCompileAll(t *testing.T) {
+	assert.Nil(t, (&Search{Group: SearchGroupAll, Value: "any"}).Compile())
+	assert.NotNil(t, (&Search{Group: SearchGroupAll, Match: match.Regex, Value: "["}).Compile())
+	assert.NotNil(t, (&Search{Group: SearchGroupAll, Match: match.Range, Value: "high"}).Compile())
+	assert.NotNil(t, (&Search{Group: SearchGroupAll, Match: match.Range, Value: ">=abc"}).Compile())
+}
+
+func TestFieldMatchesExport(t *testing.T) {
+	s := allTestSearch(t)
+	s.Value = "101"
+	matches := SearchAll(s)
+
+	var buf bytes.Buffer
+	assert.Nil(t, FieldMatchesExport(&buf, display.FormatCSV, matches))
+	assert.Equal(t, "group,field,_id\nusers,organization_id,1\ntickets,organization_id,436bf9b0\norganizations,_id,101\ngroups,organization_id,1\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, FieldMatchesExport(&buf, display.FormatJSON, matches[2:3]))
+	assert.Contains(t, buf.String(), "\"group\": \"organizations\",\n    \"field\": \"_id\",\n    \"count\": 1,")
+	assert.Contains(t, buf.String(), "\"name\": \"Enthaze\"")
+
+	assert.NotNil(t, FieldMatchesExport(&buf, "xml", matches))
+}
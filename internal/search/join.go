@@ -0,0 +1,110 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+	"github.com/nicholas-boyson/wordsearch/internal/query"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+)
+
+// joinSeparator separates a relation from the field of the related group e.g. assignee.role
+const joinSeparator = "."
+
+// relation links a group to another group through a key held on both
+type relation struct {
+	group     string // related group
+	localKey  string // field of the group holding the key
+	remoteKey string // field of the related group holding the same key
+}
+
+// relations returns the relations of the group by name, derived from the fields referring to the id of another
+// entity. A field such as assignee_id relates its group to the record it refers to, named by the field without its
+// _id suffix, and relates the entity it refers to back to the records holding it, named by the inverse of the field
+// or else by the group holding it e.g. users assigned and organizations tickets. A search on relation.field holds
+// for records with a related record holding the field e.g. tickets assignee.role:admin or organizations
+// users.suspended:true. A name already taken keeps the relation it names first
+func relations(group string) map[string]relation {
+	rels := make(map[string]relation)
+	for _, f := range registryOf(group).Fields() {
+		if f.Ref != "" {
+			rels[strings.TrimSuffix(f.Name, "_id")] = relation{group: groupOf(f.Ref), localKey: f.Name, remoteKey: store.IdField}
+		}
+	}
+	name := entityName(group)
+	for _, other := range Groups() {
+		for _, f := range registryOf(other).Fields() {
+			if f.Ref != name {
+				continue
+			}
+			inverse := f.Inverse
+			if inverse == "" {
+				inverse = entityName(other)
+			}
+			if _, ok := rels[inverse]; !ok {
+				rels[inverse] = relation{group: other, localKey: store.IdField, remoteKey: f.Name}
+			}
+		}
+	}
+	return rels
+}
+
+// splitJoin splits a join field into the relation and the field of the related group, ok is false for plain fields
+func splitJoin(group string, ident string) (rel relation, field string, ok bool) {
+	parts := strings.SplitN(ident, joinSeparator, 2)
+	if len(parts) != 2 {
+		return relation{}, "", false
+	}
+	rel, ok = relations(group)[parts[0]]
+	return rel, parts[1], ok
+}
+
+// validJoin checks a join field names a relation of the group and a search term of the related group, other than
+// the wildcard field. Joins may be nested e.g. tickets submitter.organization.name
+func validJoin(group string, ident string) bool {
+	rel, field, ok := splitJoin(group, ident)
+	return ok && field != WildcardField && ValidSearchTerms(rel.group, field)
+}
+
+// joinMatcher returns the matcher comparing the local key of a record to the keys of the related records holding
+// the field and value, the related group is searched once for the whole search
+func (s Search) joinMatcher(ident string, value string) (termMatcher, error) {
+	rel, field, _ := splitJoin(s.Group, ident)
+	related := s
+	related.Group = rel.group
+	related.Ident = ""
+	related.Value = ""
+	related.Pattern = nil
+	related.Query = query.Term{Field: field, Value: value}
+	if _, err := related.termMatchers(); err != nil {
+		return termMatcher{}, err
+	}
+	keys := related.joinKeys(rel.remoteKey)
+	return termMatcher{
+		field: rel.localKey,
+		match: func(v string) bool {
+			return keys[v]
+		},
+	}, nil
+}
+
+// joinKeys returns the values of key held by the records of the group holding the query
+func (s Search) joinKeys(key string) map[string]bool {
+	keys := make(map[string]bool)
+	addKeys := func(values []string) {
+		for _, value := range values {
+			keys[value] = true
+		}
+	}
+	r := registryOf(s.Group)
+	for _, rec := range s.queryRecords() {
+		addKeys(r.Values(rec, key))
+	}
+	return keys
+}
+
+// termMatcher compares a field of a record to a query term, join terms compare the key of the record
+type termMatcher struct {
+	field string
+	match match.Matcher
+}
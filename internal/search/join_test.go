@@ -0,0 +1,161 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/query"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidJoin(t *testing.T) {
+	tests := []struct {
+		test   string
+		group  string
+		ident  string
+		result bool
+	}{
+		{test: "TicketAssignee", group: SearchGroupTickets, ident: "assignee.role", result: true},
+		{test: "TicketSubmitterOrganization", group: SearchGroupTickets, ident: "submitter.organization.name", result: true},
+		{test: "OrganizationUsers", group: SearchGroupOrganizations, ident: "users.suspended", result: true},
+		{test: "UserSubmitted", group: SearchGroupUsers, ident: "submitted.status", result: true},
+		{test: "UnknownRelation", group: SearchGroupUsers, ident: "assignee.role", result: false},
+		{test: "UnknownRelatedField", group: SearchGroupTickets, ident: "assignee.status", result: false},
+		{test: "MissingField", group: SearchGroupTickets, ident: "assignee.", result: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.result, ValidSearchTerms(tt.group, tt.ident), tt.test)
+	}
+}
+
+func TestRelations(t *testing.T) {
+	assert.Equal(t, map[string]relation{
+		"organization": {group: SearchGroupOrganizations, localKey: "organization_id", remoteKey: "_id"},
+		"submitted":    {group: SearchGroupTickets, localKey: "_id", remoteKey: "submitter_id"},
+		"assigned":     {group: SearchGroupTickets, localKey: "_id", remoteKey: "assignee_id"},
+	}, relations(SearchGroupUsers))
+	assert.Equal(t, map[string]relation{
+		"organization": {group: SearchGroupOrganizations, localKey: "organization_id", remoteKey: "_id"},
+		"submitter":    {group: SearchGroupUsers, localKey: "submitter_id", remoteKey: "_id"},
+		"assignee":     {group: SearchGroupUsers, localKey: "assignee_id", remoteKey: "_id"},
+	}, relations(SearchGroupTickets))
+	assert.Equal(t, map[string]relation{
+		"users":   {group: SearchGroupUsers, localKey: "_id", remoteKey: "organization_id"},
+		"tickets": {group: SearchGroupTickets, localKey: "_id", remoteKey: "organization_id"},
+	}, relations(SearchGroupOrganizations))
+	assert.Empty(t, relations("unknown"))
+
+	// the entities of a schema are related both ways to the entities they refer to
+	stores := useTestSchema(t)
+	assert.Equal(t, relation{group: "macros", localKey: "_id", remoteKey: "author_id"}, relations(SearchGroupUsers)["macros"])
+	assert.Equal(t, relation{group: "groups", localKey: "_id", remoteKey: "organization_id"}, relations(SearchGroupOrganizations)["groups"])
+	assert.Equal(t, map[string]relation{
+		"organization": {group: SearchGroupOrganizations, localKey: "organization_id", remoteKey: "_id"},
+		"macros":       {group: "macros", localKey: "_id", remoteKey: "group_id"},
+	}, relations("groups"))
+
+	userList := []users.User{{Id: 1, Name: "Francisca Rasmussen"}, {Id: 2, Name: "Cross Barlow"}}
+	s := Search{Group: SearchGroupUsers, Ident: "macros.title", Match: match.Text, Value: "refund", Users: users.BuildIndex(userList), Entities: stores}
+	assert.Nil(t, s.Compile())
+	assert.Equal(t, userList[1:], SearchData(s).Users)
+}
+
+func TestSearchJoin(t *testing.T) {
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}, {Id: 103, Name: "Zentix"}}
+	userList := []users.User{
+		{Id: 1, Name: "Francisca Rasmussen", Role: "admin", Suspended: true, OrganizationId: 101},
+		{Id: 2, Name: "Cross Barlow", Role: "agent", Suspended: false, OrganizationId: 102},
+		{Id: 3, Name: "Ingrid Wagner", Role: "end-user", Suspended: false, OrganizationId: 103},
+	}
+	ticketList := []tickets.Ticket{
+		{Id: "a", Status: "pending", SubmitterId: 2, AssigneeId: 1, OrganizationId: 101},
+		{Id: "b", Status: "open", SubmitterId: 3, AssigneeId: 2, OrganizationId: 102},
+		{Id: "c", Status: "pending", SubmitterId: 2, AssigneeId: 1, OrganizationId: 102},
+		{Id: "d", Status: "closed", SubmitterId: 1, OrganizationId: 103},
+	}
+	tests := []struct {
+		test   string
+		search Search
+		query  string
+		result SearchResult
+	}{
+		{
+			test:   "TicketsAssignedToAdmins",
+			search: Search{Group: SearchGroupTickets, Ident: "assignee.role", Value: "admin"},
+			result: SearchResult{Tickets: []tickets.Ticket{ticketList[0], ticketList[2]}, Organizations: orgList[:2]},
+		},
+		{
+			test:   "OrganizationsWithSuspendedUsers",
+			search: Search{Group: SearchGroupOrganizations, Ident: "users.suspended", Value: "true"},
+			result: SearchResult{Organizations: orgList[:1], Tickets: ticketList[:1], Users: userList[:1]},
+		},
+		{
+			test:   "TicketsSubmittedByUsersInOrganization",
+			search: Search{Group: SearchGroupTickets, Ident: "submitter.organization.name", Value: "Nutralab"},
+			result: SearchResult{Tickets: []tickets.Ticket{ticketList[0], ticketList[2]}, Organizations: orgList[:2]},
+		},
+		{
+			test:   "UsersWithPendingSubmittedTickets",
+			search: Search{Group: SearchGroupUsers},
+			query:  "submitted.status:pending",
+			result: SearchResult{Users: userList[1:2], Organizations: orgList[1:2], Tickets: []tickets.Ticket{ticketList[0], ticketList[2], ticketList[1]}},
+		},
+		{
+			test:   "UsersAssignedNothing",
+			search: Search{Group: SearchGroupUsers, Match: match.Glob},
+			query:  "NOT assigned.status:*",
+			result: SearchResult{Users: userList[2:], Organizations: orgList[2:], Tickets: ticketList[1:2]},
+		},
+		{
+			test:   "JoinTermWithMatchMode",
+			search: Search{Group: SearchGroupTickets, Match: match.Glob},
+			query:  "status:pending AND assignee.name:francisca*",
+			result: SearchResult{Tickets: []tickets.Ticket{ticketList[0], ticketList[2]}, Organizations: orgList[:2]},
+		},
+		{
+			test:   "JoinRangeTerm",
+			search: Search{Group: SearchGroupOrganizations},
+			query:  "tickets.submitter_id:>2",
+			result: SearchResult{Organizations: orgList[1:2], Tickets: ticketList[1:3], Users: userList[1:2]},
+		},
+		{
+			test:   "NoRelatedRecords",
+			search: Search{Group: SearchGroupTickets, Ident: "assignee.role", Value: "owner"},
+			result: SearchResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.query != "" {
+			expr, err := query.Parse(tt.query)
+			assert.Nil(t, err, tt.test)
+			tt.search.Query = expr
+		}
+		tt.search.Organizations = orgSlice(orgList)
+		tt.search.Tickets = ticketSlice(ticketList)
+		tt.search.Users = userSlice(userList)
+		assert.Nil(t, tt.search.Compile(), tt.test)
+		assert.Equal(t, tt.result, SearchData(tt.search), tt.test)
+
+		// joins scan the related group so the result is the same with indexes built
+		tt.search.Organizations = organizations.BuildIndex(orgList)
+		tt.search.Tickets = tickets.BuildIndex(ticketList)
+		tt.search.Users = users.BuildIndex(userList)
+		assert.Equal(t, tt.result, SearchData(tt.search), tt.test)
+	}
+}
+
+func TestSearchCompileJoin(t *testing.T) {
+	s := Search{Group: SearchGroupTickets, Ident: "assignee.name", Match: match.Regex, Value: "[Fran"}
+	assert.NotNil(t, s.Compile())
+
+	s = Search{Group: SearchGroupTickets, Ident: "assignee.last_login_at", Match: match.Range, Value: "<yesterday"}
+	assert.NotNil(t, s.Compile())
+
+	s = Search{Group: SearchGroupTickets, Ident: "assignee.name", Match: match.Regex, Value: "^Fran"}
+	assert.Nil(t, s.Compile())
+}
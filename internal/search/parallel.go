@@ -0,0 +1,48 @@
+package search
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minChunkSize smallest number of records handed to a worker, below this the goroutine overhead outweighs the scan
+const minChunkSize = 512
+
+// scanWorkers returns the number of workers used to scan n records, workers <= 0 uses one worker per CPU
+func scanWorkers(n int, workers int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if maxWorkers := (n + minChunkSize - 1) / minChunkSize; workers > maxWorkers {
+		workers = maxWorkers
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// parallelScan splits n records into one contiguous chunk per worker and runs scan on every chunk concurrently.
+// Each call receives its chunk number so the caller can store the chunk result in its own slot and merge
+// the slots in order once parallelScan returns
+func parallelScan(n int, workers int, scan func(chunk int, start int, end int)) {
+	if workers <= 1 {
+		scan(0, 0, n)
+		return
+	}
+	var workerGrp sync.WaitGroup
+	split := n / workers
+	for i := 0; i < workers; i++ {
+		start := split * i
+		end := split * (i + 1)
+		if i == workers-1 {
+			end = n
+		}
+		workerGrp.Add(1)
+		go func(chunk int) {
+			defer workerGrp.Done()
+			scan(chunk, start, end)
+		}(i)
+	}
+	workerGrp.Wait()
+}
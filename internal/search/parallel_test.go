@@ -0,0 +1,105 @@
+package search
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanWorkers(t *testing.T) {
+	tests := []struct {
+		test    string
+		n       int
+		workers int
+		result  int
+	}{
+		{
+			test:    "NoRecords",
+			n:       0,
+			workers: 4,
+			result:  1,
+		},
+		{
+			test:    "SmallDatasetScansSerially",
+			n:       minChunkSize,
+			workers: 4,
+			result:  1,
+		},
+		{
+			test:    "LimitedByChunkSize",
+			n:       minChunkSize*2 + 1,
+			workers: 8,
+			result:  3,
+		},
+		{
+			test:    "LimitedByWorkers",
+			n:       minChunkSize * 100,
+			workers: 8,
+			result:  8,
+		},
+		{
+			test:    "SerialWorker",
+			n:       minChunkSize * 100,
+			workers: 1,
+			result:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.result, scanWorkers(tt.n, tt.workers), tt.test)
+	}
+}
+
+func TestParallelScan(t *testing.T) {
+	tests := []struct {
+		test    string
+		n       int
+		workers int
+	}{
+		{
+			test:    "Serial",
+			n:       10,
+			workers: 1,
+		},
+		{
+			test:    "EvenSplit",
+			n:       12,
+			workers: 4,
+		},
+		{
+			test:    "RemainderInLastChunk",
+			n:       13,
+			workers: 4,
+		},
+		{
+			test:    "Empty",
+			n:       0,
+			workers: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		var mu sync.Mutex
+		seen := make([]int, tt.n)
+		chunks := make([][2]int, tt.workers)
+		parallelScan(tt.n, tt.workers, func(chunk int, start int, end int) {
+			mu.Lock()
+			defer mu.Unlock()
+			chunks[chunk] = [2]int{start, end}
+			for i := start; i < end; i++ {
+				seen[i]++
+			}
+		})
+		// every record is scanned exactly once and the chunks are contiguous in order
+		for i := range seen {
+			assert.Equal(t, 1, seen[i], tt.test)
+		}
+		next := 0
+		for _, c := range chunks {
+			assert.Equal(t, next, c[0], tt.test)
+			next = c[1]
+		}
+		assert.Equal(t, tt.n, next, tt.test)
+	}
+}
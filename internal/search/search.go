@@ -1,11 +1,20 @@
 package search
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
 	"strconv"
-	"sync"
+	"strings"
 
 	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
 	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/query"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
 	"github.com/nicholas-boyson/wordsearch/internal/tickets"
 	"github.com/nicholas-boyson/wordsearch/internal/users"
 )
@@ -17,16 +26,33 @@ const (
 	SearchGroupTickets = "Tickets"
 	//SearchGroupOrganizations "Organizations"
 	SearchGroupOrganizations = "Organizations"
+	//SearchGroupAll "All" searches a value in every field of every group, see SearchAll
+	SearchGroupAll = "All"
+	//WildcardField "*" searches a value in every field of a group, see SearchFields
+	WildcardField = "*"
 )
 
 //Search search definition
 type Search struct {
-	Ident         string
-	Group         string
-	Value         string
-	Organizations []organizations.Organization
-	Tickets       []tickets.Ticket
-	Users         []users.User
+	Ident   string
+	Group   string
+	Value   string
+	Match   string         // match mode comparing field values to Value, blank is exact
+	Pattern *regexp.Regexp // Value compiled by Compile when Match is regex
+	Query   query.Expr     // compound query combining fields, when set Ident and Value are ignored
+	// stores the groups are searched in, a nil store holds no records. The records of a store are those of its
+	// group: organizations.Organization, tickets.Ticket and users.User values
+	Organizations store.Store
+	Tickets       store.Store
+	Users         store.Store
+	// stores of the schema entities by entity name holding entity.Record values, the group of a schema entity is its
+	// name and a missing store holds no records
+	Entities map[string]store.Store
+	// Workers number of goroutines scanning the stores, 0 uses one per CPU and 1 scans serially
+	Workers int
+	// StoreErr returns the first error of the stores while searching, nil for stores that cannot fail such as the
+	// in-memory stores
+	StoreErr func() error
 }
 
 // SearchResult search result definition
@@ -34,179 +60,578 @@ type SearchResult struct {
 	Organizations []organizations.Organization
 	Tickets       []tickets.Ticket
 	Users         []users.User
+	// Records the records found when the group is a schema entity
+	Records []entity.Record
+	// Linked the records of schema entities linked to the records found, by entity name
+	Linked map[string][]entity.Record
 }
 
-const workerGrpMax = 10
-
-// SearchData search across all data sources linking on organization id when single result or search by organization id
+// SearchData search across all data sources, linking tickets and users to their own organization and a single
+// organization to its tickets and users. Records of a schema entity are linked to the records their relations
+// refer to, and a single organization, user or schema record to the schema records referring to it
 func SearchData(s Search) (result SearchResult) {
+	s = s.withJoin()
+	if !isGroup(s.Group) {
+		return SearchResult{}
+	}
+	result.add(s.findRecords()...)
 	switch s.Group {
 	case SearchGroupOrganizations:
-		var workerGrp sync.WaitGroup
-		searchOrgChan := make(chan []organizations.Organization, workerGrpMax)
-		split := (len(s.Organizations) / workerGrpMax)
-		for i := 0; i < workerGrpMax; i++ {
-			workerGrp.Add(1)
-			var orgs []organizations.Organization
-			if i == workerGrpMax-1 {
-				orgs = s.Organizations[split*i:]
-			} else {
-				orgs = s.Organizations[split*i : split*(i+1)]
-			}
-			go func() {
-				defer workerGrp.Done()
-				searchOrgChan <- organizations.SearchOrganizations(orgs, s.Ident, s.Value)
-			}()
-			result.Organizations = append(result.Organizations, <-searchOrgChan...)
-		}
-		workerGrp.Wait()
-
 		if len(result.Organizations) == 1 {
 			// Only search when there is a single organization returned
-			var workerGrp sync.WaitGroup
-			searchTicketChan := make(chan []tickets.Ticket, workerGrpMax)
-			searchUserChan := make(chan []users.User, workerGrpMax)
-			splitTicket := (len(s.Tickets) / workerGrpMax)
-			splitUsers := (len(s.Users) / workerGrpMax)
-			for i := 0; i < workerGrpMax; i++ {
-				workerGrp.Add(1)
-				var ticketsInput []tickets.Ticket
-				var usersInput []users.User
-				if i == workerGrpMax-1 {
-					ticketsInput = s.Tickets[splitTicket*i:]
-					usersInput = s.Users[splitUsers*i:]
-				} else {
-					ticketsInput = s.Tickets[splitTicket*i : splitTicket*(i+1)]
-					usersInput = s.Users[splitUsers*i : splitUsers*(i+1)]
+			orgId := strconv.Itoa(result.Organizations[0].Id)
+			result.add(s.searchRecords(SearchGroupTickets, "organization_id", orgId)...)
+			result.add(s.searchRecords(SearchGroupUsers, "organization_id", orgId)...)
+			s.linkReferring(&result, entityName(SearchGroupOrganizations), orgId)
+		}
+	case SearchGroupTickets:
+		// link every ticket to its own organization
+		orgIds := make([]int, 0, len(result.Tickets))
+		for _, ticket := range result.Tickets {
+			orgIds = append(orgIds, ticket.OrganizationId)
+		}
+		result.add(s.linkIds(SearchGroupOrganizations, orgIds)...)
+		if len(result.Tickets) == 1 {
+			// link the submitter and assignee of a single ticket, the same user is linked once
+			result.add(s.linkIds(SearchGroupUsers, []int{result.Tickets[0].SubmitterId, result.Tickets[0].AssigneeId})...)
+		}
+	case SearchGroupUsers:
+		// link every user to its own organization
+		orgIds := make([]int, 0, len(result.Users))
+		for _, user := range result.Users {
+			orgIds = append(orgIds, user.OrganizationId)
+		}
+		result.add(s.linkIds(SearchGroupOrganizations, orgIds)...)
+		if len(result.Users) == 1 {
+			// link the tickets submitted by and assigned to a single user, a ticket both submitted and assigned is linked once
+			userId := strconv.Itoa(result.Users[0].Id)
+			result.add(s.searchRecords(SearchGroupTickets, "submitter_id", userId)...)
+			for _, rec := range s.searchRecords(SearchGroupTickets, "assignee_id", userId) {
+				if rec.(tickets.Ticket).SubmitterId != result.Users[0].Id {
+					result.add(rec)
 				}
-				go func() {
-					defer workerGrp.Done()
-					searchTicketChan <- tickets.SearchTickets(ticketsInput, "organization_id", strconv.Itoa(result.Organizations[0].Id))
-					searchUserChan <- users.SearchUsers(usersInput, "organization_id", strconv.Itoa(result.Organizations[0].Id))
-				}()
-				result.Tickets = append(result.Tickets, <-searchTicketChan...)
-				result.Users = append(result.Users, <-searchUserChan...)
 			}
-			workerGrp.Wait()
+			s.linkReferring(&result, entityName(SearchGroupUsers), userId)
 		}
-	case SearchGroupTickets:
-		var workerGrp sync.WaitGroup
-		searchTicketChan := make(chan []tickets.Ticket, workerGrpMax)
-		split := (len(s.Tickets) / workerGrpMax)
-		for i := 0; i < workerGrpMax; i++ {
-			workerGrp.Add(1)
-			var ticketList []tickets.Ticket
-			if i == workerGrpMax-1 {
-				ticketList = s.Tickets[split*i:]
-			} else {
-				ticketList = s.Tickets[split*i : split*(i+1)]
+	default:
+		t, _ := entity.Lookup(s.Group)
+		s.linkReferred(&result, t)
+		if len(result.Records) == 1 {
+			s.linkReferring(&result, t.Name, strconv.Itoa(result.Records[0].Id()))
+		}
+	}
+	return
+}
+
+// linkReferred links the records found of the schema entity to the records their relations refer to, every
+// record is linked once in the order it is first referred to
+func (s Search) linkReferred(result *SearchResult, t *entity.Type) {
+	var refs []string
+	ids := make(map[string][]int)
+	for _, rec := range result.Records {
+		for _, f := range t.Fields().Fields() {
+			if f.Ref == "" {
+				continue
 			}
-			go func() {
-				defer workerGrp.Done()
-				searchTicketChan <- tickets.SearchTickets(ticketList, s.Ident, s.Value)
-			}()
-			result.Tickets = append(result.Tickets, <-searchTicketChan...)
-		}
-		workerGrp.Wait()
-		if len(result.Tickets) == 1 || (len(result.Tickets) > 0 && s.Ident == "organization_id") {
-			// Only link organization details when there is a single ticket returned or the search was on the org id
-			var workerGrp sync.WaitGroup
-			searchOrgChan := make(chan []organizations.Organization, workerGrpMax)
-			split := (len(s.Organizations) / workerGrpMax)
-			for i := 0; i < workerGrpMax; i++ {
-				workerGrp.Add(1)
-				var orgs []organizations.Organization
-				if i == workerGrpMax-1 {
-					orgs = s.Organizations[split*i:]
-				} else {
-					orgs = s.Organizations[split*i : split*(i+1)]
-				}
-				go func() {
-					defer workerGrp.Done()
-					searchOrgChan <- organizations.SearchOrganizations(orgs, "_id", strconv.Itoa(result.Tickets[0].OrganizationId))
-				}()
-				result.Organizations = append(result.Organizations, <-searchOrgChan...)
+			if _, ok := ids[f.Ref]; !ok {
+				refs = append(refs, f.Ref)
 			}
-			workerGrp.Wait()
+			id, _ := rec[f.Name].(int)
+			ids[f.Ref] = append(ids[f.Ref], id)
 		}
-	case SearchGroupUsers:
-		var workerGrp sync.WaitGroup
-		searchUsersChan := make(chan []users.User, workerGrpMax)
-		split := (len(s.Users) / workerGrpMax)
-		for i := 0; i < workerGrpMax; i++ {
-			workerGrp.Add(1)
-			var usersList []users.User
-			if i == workerGrpMax-1 {
-				usersList = s.Users[split*i:]
+	}
+	for _, ref := range refs {
+		for _, rec := range s.linkIds(groupOf(ref), ids[ref]) {
+			if linked, ok := rec.(entity.Record); ok {
+				result.link(ref, linked)
 			} else {
-				usersList = s.Users[split*i : split*(i+1)]
+				result.add(rec)
 			}
-			go func() {
-				defer workerGrp.Done()
-				searchUsersChan <- users.SearchUsers(usersList, s.Ident, s.Value)
-			}()
-			result.Users = append(result.Users, <-searchUsersChan...)
-		}
-		workerGrp.Wait()
-		if len(result.Users) == 1 || (len(result.Users) > 0 && s.Ident == "organization_id") {
-			// Only link organization details when there is a single user returned or the search was on the org id
-			var workerGrp sync.WaitGroup
-			searchOrgChan := make(chan []organizations.Organization, workerGrpMax)
-			split := (len(s.Organizations) / workerGrpMax)
-			for i := 0; i < workerGrpMax; i++ {
-				workerGrp.Add(1)
-				var orgs []organizations.Organization
-				if i == workerGrpMax-1 {
-					orgs = s.Organizations[split*i:]
-				} else {
-					orgs = s.Organizations[split*i : split*(i+1)]
-				}
-				go func() {
-					defer workerGrp.Done()
-					searchOrgChan <- organizations.SearchOrganizations(orgs, "_id", strconv.Itoa(result.Users[0].OrganizationId))
-				}()
-				result.Organizations = append(result.Organizations, <-searchOrgChan...)
+		}
+	}
+}
+
+// linkReferring links the records of every schema entity with a relation to the entity holding id
+func (s Search) linkReferring(result *SearchResult, name string, id string) {
+	for _, t := range entity.Types() {
+		for _, f := range t.Fields().Fields() {
+			if f.Ref != name {
+				continue
+			}
+			for _, rec := range s.storeOf(t.Name).Search(f.Name, id) {
+				result.link(t.Name, rec.(entity.Record))
 			}
-			workerGrp.Wait()
 		}
+	}
+}
+
+// link adds a linked record of the schema entity, a record already linked is not added again
+func (sr *SearchResult) link(name string, rec entity.Record) {
+	for _, linked := range sr.Linked[name] {
+		if linked.Id() == rec.Id() {
+			return
+		}
+	}
+	if sr.Linked == nil {
+		sr.Linked = make(map[string][]entity.Record)
+	}
+	sr.Linked[name] = append(sr.Linked[name], rec)
+}
+
+// add adds records found or linked to the records of their group, the records of a schema entity are those found
+func (sr *SearchResult) add(records ...interface{}) {
+	for _, rec := range records {
+		switch rec := rec.(type) {
+		case organizations.Organization:
+			sr.Organizations = append(sr.Organizations, rec)
+		case tickets.Ticket:
+			sr.Tickets = append(sr.Tickets, rec)
+		case users.User:
+			sr.Users = append(sr.Users, rec)
+		case entity.Record:
+			sr.Records = append(sr.Records, rec)
+		}
+	}
+}
+
+// found returns the records of the group held by the result as the records of a store
+func (sr SearchResult) found(group string) []interface{} {
+	switch group {
+	case SearchGroupOrganizations:
+		return organizations.Records(sr.Organizations)
+	case SearchGroupTickets:
+		return tickets.Records(sr.Tickets)
+	case SearchGroupUsers:
+		return users.Records(sr.Users)
 	default:
-		return SearchResult{}
+		if _, ok := entity.Lookup(group); ok {
+			return entity.Records(sr.Records)
+		}
+		return nil
+	}
+}
+
+// linkIds returns the records of the group with the ids once each in the order the ids are first held
+func (s Search) linkIds(group string, ids []int) (records []interface{}) {
+	st := s.storeOf(group)
+	linked := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if linked[id] {
+			continue
+		}
+		linked[id] = true
+		if rec, ok := st.Get(strconv.Itoa(id)); ok {
+			records = append(records, rec)
+		}
 	}
 	return
 }
 
-// ValidSearchTerms return if ident is valid for a group
-func ValidSearchTerms(group string, ident string) bool {
+// findRecords returns the records of the search group matching the search ident and value in the search match mode
+func (s Search) findRecords() []interface{} {
+	if s.Query != nil {
+		return s.queryRecords()
+	}
+	if s.exactMatch() {
+		return s.searchRecords(s.Group, s.Ident, s.Value)
+	}
+	st := s.storeOf(s.Group)
+	if s.Match == match.Text && s.Ident == "" {
+		// the words may sit in different text fields, every text field is searched together and ranked
+		return st.SearchText(s.Value)
+	}
+	if s.Match == match.Text && registryOf(s.Group).FullText(s.Ident) {
+		// free text fields are ranked by relevance
+		return st.SearchText(s.Value, s.Ident)
+	}
+	if ms, ok := st.(store.MatchStore); ok {
+		// a store such as a database answers the mode itself rather than being scanned
+		if records, ok := ms.SearchMatch(s.Ident, s.Match, s.Value); ok {
+			return records
+		}
+	}
+	matcher, err := s.matcher()
+	if err != nil {
+		return nil
+	}
+	return s.scanRecords(s.Ident, matcher)
+}
+
+// Err returns the error the stores failed with while searching, the results of a failed search are incomplete
+func (s Search) Err() error {
+	if s.StoreErr == nil {
+		return nil
+	}
+	return s.StoreErr()
+}
+
+// Compile prepares the search value for its match mode, compiling Value into Pattern for the regex mode and
+// checking range values and query terms. The error describes an invalid pattern or range so it can be reported
+// rather than searching with no results
+func (s *Search) Compile() error {
+	s.Pattern = nil
+	if s.Group == SearchGroupAll || s.Ident == WildcardField {
+		return s.compileAll()
+	}
+	if joined := s.withJoin(); joined.Query != nil {
+		_, err := joined.termMatchers()
+		return err
+	}
+	switch s.Match {
+	case match.Regex:
+		pattern, err := match.Compile(s.Value)
+		if err != nil {
+			return err
+		}
+		s.Pattern = pattern
+	case match.Range:
+		if _, err := s.rangeMatcher(s.Ident, s.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// noFields the fields of a group holding no records
+var noFields = fields.Define(nil)
+
+// registryOf returns the searchable fields of the group, none for a group holding no records
+func registryOf(group string) *fields.Registry {
+	switch group {
+	case SearchGroupOrganizations:
+		return organizations.Fields()
+	case SearchGroupTickets:
+		return tickets.Fields()
+	case SearchGroupUsers:
+		return users.Fields()
+	default:
+		if t, ok := entity.Lookup(group); ok {
+			return t.Fields()
+		}
+		return noFields
+	}
+}
+
+// storeOf returns the store of the group, an empty store when the search holds none
+func (s Search) storeOf(group string) store.Store {
+	var st store.Store
+	switch group {
+	case SearchGroupOrganizations:
+		st = s.Organizations
+	case SearchGroupTickets:
+		st = s.Tickets
+	case SearchGroupUsers:
+		st = s.Users
+	default:
+		st = s.Entities[group]
+	}
+	if st == nil {
+		return store.BuildIndex(registryOf(group), nil)
+	}
+	return st
+}
+
+// withJoin returns the search with a join ident such as assignee.role searched as a single term query
+func (s Search) withJoin() Search {
+	if s.Query == nil && strings.Contains(s.Ident, joinSeparator) {
+		s.Query = query.Term{Field: s.Ident, Value: s.Value}
+	}
+	return s
+}
+
+// matcher returns the matcher for the search match mode, using the compiled pattern when held
+func (s Search) matcher() (match.Matcher, error) {
+	if s.Match == match.Regex && s.Pattern != nil {
+		return s.Pattern.MatchString, nil
+	}
+	if s.Match == match.Range {
+		return s.rangeMatcher(s.Ident, s.Value)
+	}
+	return match.New(s.Match, s.Value)
+}
+
+// rangeMatcher returns the matcher for a range on a number or date field of the search group
+func (s Search) rangeMatcher(ident string, value string) (match.Matcher, error) {
+	intField, timeField := fieldType(s.Group, ident)
+	switch {
+	case intField:
+		return match.NewIntRange(value)
+	case timeField:
+		return match.NewTimeRange(value)
+	default:
+		return nil, fmt.Errorf("field %q is not a number or date, ranges need one", ident)
+	}
+}
+
+// fieldType reports whether ident of the group holds whole numbers or timestamps
+func fieldType(group string, ident string) (intField bool, timeField bool) {
+	r := registryOf(group)
+	return r.Is(ident, fields.Int), r.Is(ident, fields.Time)
+}
+
+// entityName returns the entity name of a search group as used by the relations of a schema, the group of a schema
+// entity is its name
+func entityName(group string) string {
+	switch group {
+	case SearchGroupOrganizations, SearchGroupTickets, SearchGroupUsers:
+		return strings.ToLower(group)
+	default:
+		return group
+	}
+}
+
+// groupOf returns the search group of an entity name
+func groupOf(name string) string {
+	switch name {
+	case entityName(SearchGroupOrganizations):
+		return SearchGroupOrganizations
+	case entityName(SearchGroupTickets):
+		return SearchGroupTickets
+	case entityName(SearchGroupUsers):
+		return SearchGroupUsers
+	default:
+		return name
+	}
+}
+
+// termMatchers returns the matcher of every query term in the search match mode, terms on number or date
+// fields written as a range such as due_at:<2016-08-01 are ranges whatever the match mode and terms on a
+// relation such as assignee.role:admin match the records related to a record holding the term
+func (s Search) termMatchers() (map[query.Term]termMatcher, error) {
+	matchers := make(map[query.Term]termMatcher)
+	for _, term := range s.Query.Terms() {
+		if _, ok := matchers[term]; ok {
+			continue
+		}
+		if _, _, ok := splitJoin(s.Group, term.Field); ok {
+			joined, err := s.joinMatcher(term.Field, term.Value)
+			if err != nil {
+				return nil, err
+			}
+			matchers[term] = joined
+			continue
+		}
+		var matcher match.Matcher
+		var err error
+		intField, timeField := fieldType(s.Group, term.Field)
+		if s.Match == match.Range || ((intField || timeField) && match.IsRange(term.Value)) {
+			matcher, err = s.rangeMatcher(term.Field, term.Value)
+		} else {
+			matcher, err = match.New(s.Match, term.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		matchers[term] = termMatcher{field: term.Field, match: matcher}
+	}
+	return matchers, nil
+}
+
+// exactMatch reports whether the search compares whole values, which the indexes can answer
+func (s Search) exactMatch() bool {
+	return s.Match == "" || s.Match == match.Exact
+}
+
+// searchRecords looks up the records holding exactly value for ident in the store of the group
+func (s Search) searchRecords(group string, ident string, value string) []interface{} {
+	if !registryOf(group).Has(ident) {
+		return nil
+	}
+	return s.storeOf(group).Search(ident, value)
+}
+
+// scanRecords scans the store of the search group in parallel for values of ident accepted by matcher
+func (s Search) scanRecords(ident string, matcher match.Matcher) []interface{} {
+	r := registryOf(s.Group)
+	if !r.Has(ident) {
+		return nil
+	}
+	return s.each(func(rec interface{}) bool {
+		return r.Match(rec, ident, matcher)
+	})
+}
+
+// queryRecords scans the store of the search group in parallel for records holding the query
+func (s Search) queryRecords() []interface{} {
+	matchers, err := s.termMatchers()
+	if err != nil {
+		return nil
+	}
+	r := registryOf(s.Group)
+	return s.each(func(rec interface{}) bool {
+		return s.Query.Eval(func(term query.Term) bool {
+			return r.Match(rec, matchers[term].field, matchers[term].match)
+		})
+	})
+}
+
+// each returns the records of the store of the search group accepted by keep, scanning the store in chunks in
+// parallel and keeping the load order
+func (s Search) each(keep func(interface{}) bool) (records []interface{}) {
+	st := s.storeOf(s.Group)
+	n := st.Len()
+	workers := scanWorkers(n, s.Workers)
+	chunks := make([][]interface{}, workers)
+	parallelScan(n, workers, func(chunk int, start int, end int) {
+		st.Each(start, end, func(rec interface{}) {
+			if keep(rec) {
+				chunks[chunk] = append(chunks[chunk], rec)
+			}
+		})
+	})
+	for _, chunk := range chunks {
+		records = append(records, chunk...)
+	}
+	return
+}
+
+// Groups returns every search group, the built in groups followed by the schema entities in schema order
+func Groups() []string {
+	groups := []string{SearchGroupUsers, SearchGroupTickets, SearchGroupOrganizations}
+	for _, t := range entity.Types() {
+		groups = append(groups, t.Name)
+	}
+	return groups
+}
+
+// isGroup reports whether group is a group holding records, SearchGroupAll is not
+func isGroup(group string) bool {
+	for _, g := range Groups() {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseGroup returns the search group matching a group name, ignoring case, all is SearchGroupAll
+func ParseGroup(name string) (string, bool) {
+	for _, group := range append(Groups(), SearchGroupAll) {
+		if strings.EqualFold(name, group) {
+			return group, true
+		}
+	}
+	return "", false
+}
+
+// Count returns the number of records found for the searched group, excluding linked records
+func (sr SearchResult) Count(group string) int {
 	switch group {
 	case SearchGroupOrganizations:
-		return organizations.ValidSearchTerms(ident)
+		return len(sr.Organizations)
 	case SearchGroupTickets:
-		return tickets.ValidSearchTerms(ident)
+		return len(sr.Tickets)
 	case SearchGroupUsers:
-		return users.ValidSearchTerms(ident)
+		return len(sr.Users)
 	default:
-		return false
+		if _, ok := entity.Lookup(group); ok {
+			return len(sr.Records)
+		}
+		return 0
 	}
 }
 
+// ValidSearchTerms return if ident is valid for a group, idents on a relation such as assignee.role are valid
+// when the relation and the field of the related group are, the wildcard field * is valid for every group
+func ValidSearchTerms(group string, ident string) bool {
+	if ident == WildcardField {
+		return isGroup(group)
+	}
+	if strings.Contains(ident, joinSeparator) {
+		return validJoin(group, ident)
+	}
+	return registryOf(group).Has(ident)
+}
+
+// ValidQuery checks every field of the query is a search term of the group
+func ValidQuery(group string, expr query.Expr) error {
+	for _, term := range expr.Terms() {
+		if term.Field == WildcardField || !ValidSearchTerms(group, term.Field) {
+			return fmt.Errorf("unknown field %q for %s", term.Field, strings.ToLower(group))
+		}
+	}
+	return nil
+}
+
 // SearchResultDisplay determines the display based on group and search results
 func SearchResultDisplay(group string, sr SearchResult) {
 	switch group {
 	case SearchGroupOrganizations:
 		display.DisplayOrganizations(sr.Organizations, sr.Tickets, sr.Users)
-	case SearchGroupTickets:
-		if len(sr.Organizations) > 0 {
-			display.DisplayTickets(sr.Tickets, sr.Organizations[0])
-		} else {
-			display.DisplayTickets(sr.Tickets, organizations.Organization{})
+		if len(sr.Organizations) == 1 {
+			display.DisplayReferring(entityName(group), sr.Organizations[0].Id, sr.Linked)
 		}
+	case SearchGroupTickets:
+		display.DisplayTickets(sr.Tickets, sr.Organizations, sr.Users)
 	case SearchGroupUsers:
-		if len(sr.Organizations) > 0 {
-			display.DisplayUsers(sr.Users, sr.Organizations[0])
-		} else {
-			display.DisplayUsers(sr.Users, organizations.Organization{})
+		display.DisplayUsers(sr.Users, sr.Organizations, sr.Tickets)
+		if len(sr.Users) == 1 {
+			display.DisplayReferring(entityName(group), sr.Users[0].Id, sr.Linked)
 		}
 	default:
+		if t, ok := entity.Lookup(group); ok {
+			display.DisplayRecords(t, sr.Records, sr.Organizations, sr.Users, sr.Linked)
+			return
+		}
 		display.NoResultFound()
 	}
 }
+
+// SearchResultDisplayFormat determines the display based on output format, group and search results
+func SearchResultDisplayFormat(format string, group string, sr SearchResult) {
+	switch format {
+	case display.FormatJSON:
+		display.DisplayJSON(SearchResultRecords(group, sr))
+	case display.FormatCSV, display.FormatTSV:
+		if err := SearchResultExport(os.Stdout, format, group, sr); err != nil {
+			display.ExportFailed(err)
+		}
+	default:
+		SearchResultDisplay(group, sr)
+	}
+}
+
+// SearchResultRecords returns the records found for the group with their linked records nested
+func SearchResultRecords(group string, sr SearchResult) interface{} {
+	switch group {
+	case SearchGroupOrganizations:
+		return display.OrganizationRecords(sr.Organizations, sr.Tickets, sr.Users)
+	case SearchGroupTickets:
+		return display.TicketRecords(sr.Tickets, sr.Organizations, sr.Users)
+	case SearchGroupUsers:
+		return display.UserRecords(sr.Users, sr.Organizations, sr.Tickets)
+	default:
+		if t, ok := entity.Lookup(group); ok {
+			return display.EntityRecords(t, sr.Records, sr.Organizations, sr.Users, sr.Linked)
+		}
+		return []interface{}{}
+	}
+}
+
+// SearchResultExport writes the records found for the group to w as JSON, CSV or TSV
+func SearchResultExport(w io.Writer, format string, group string, sr SearchResult) error {
+	if !display.ExportFormat(format) {
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+	t, schemaGroup := entity.Lookup(group)
+	switch group {
+	case SearchGroupOrganizations, SearchGroupTickets, SearchGroupUsers:
+	default:
+		if !schemaGroup {
+			return fmt.Errorf("unknown search group %q", group)
+		}
+	}
+	if format == display.FormatJSON {
+		return display.WriteJSON(w, SearchResultRecords(group, sr))
+	}
+	switch group {
+	case SearchGroupOrganizations:
+		return display.WriteOrganizationsDelimited(w, format, sr.Organizations)
+	case SearchGroupTickets:
+		return display.WriteTicketsDelimited(w, format, sr.Tickets)
+	case SearchGroupUsers:
+		return display.WriteUsersDelimited(w, format, sr.Users)
+	default:
+		return display.WriteRecordsDelimited(w, format, t, sr.Records)
+	}
+}
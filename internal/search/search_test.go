@@ -1,9 +1,19 @@
 package search
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
 	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/query"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
 	"github.com/nicholas-boyson/wordsearch/internal/tickets"
 	"github.com/nicholas-boyson/wordsearch/internal/users"
 	"github.com/stretchr/testify/assert"
@@ -76,6 +86,24 @@ func TestValidSearchTerms(t *testing.T) {
 			ident:  "",
 			result: false,
 		},
+		{
+			test:   "WildcardField",
+			group:  "Tickets",
+			ident:  "*",
+			result: true,
+		},
+		{
+			test:   "WildcardFieldAllGroups",
+			group:  "All",
+			ident:  "*",
+			result: false,
+		},
+		{
+			test:   "WildcardFieldOnRelation",
+			group:  "Tickets",
+			ident:  "assignee.*",
+			result: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -84,6 +112,63 @@ func TestValidSearchTerms(t *testing.T) {
 	}
 }
 
+func TestParseGroup(t *testing.T) {
+	tests := []struct {
+		test   string
+		name   string
+		group  string
+		result bool
+	}{
+		{
+			test:   "Users",
+			name:   "users",
+			group:  SearchGroupUsers,
+			result: true,
+		},
+		{
+			test:   "Tickets",
+			name:   "TICKETS",
+			group:  SearchGroupTickets,
+			result: true,
+		},
+		{
+			test:   "Organizations",
+			name:   "Organizations",
+			group:  SearchGroupOrganizations,
+			result: true,
+		},
+		{
+			test:   "All",
+			name:   "all",
+			group:  SearchGroupAll,
+			result: true,
+		},
+		{
+			test:   "UnknownGroup",
+			name:   "groups",
+			group:  "",
+			result: false,
+		},
+	}
+
+	for _, tt := range tests {
+		group, result := ParseGroup(tt.name)
+		assert.Equal(t, tt.group, group, tt.test)
+		assert.Equal(t, tt.result, result, tt.test)
+	}
+}
+
+func TestSearchResultCount(t *testing.T) {
+	sr := SearchResult{
+		Organizations: []organizations.Organization{{Id: 101}},
+		Users:         []users.User{{Id: 1}, {Id: 2}},
+	}
+	assert.Equal(t, 1, sr.Count(SearchGroupOrganizations))
+	assert.Equal(t, 0, sr.Count(SearchGroupTickets))
+	assert.Equal(t, 2, sr.Count(SearchGroupUsers))
+	assert.Equal(t, 0, sr.Count("invalid"))
+}
+
 func TestSearch(t *testing.T) {
 	tests := []struct {
 		test          string
@@ -911,7 +996,19 @@ func TestSearch(t *testing.T) {
 				},
 			},
 			result: SearchResult{
-				Organizations: nil,
+				Organizations: []organizations.Organization{
+					{
+						Id:            101,
+						URL:           "http://initech.zendesk.com/api/v2/organizations/101.json",
+						ExternalId:    "9270ed79-35eb-4a38-a46f-35725197ea8d",
+						Name:          "Enthaze",
+						DomainNames:   []string{"kage.com", "ecratic.com", "endipin.com", "zentix.com"},
+						CreatedAt:     "2016-05-21T11:10:28 -10:00",
+						Details:       "MegaCorp",
+						SharedTickets: false,
+						Tags:          []string{"Fulton", "West", "Rodriguez", "Farley"},
+					},
+				},
 				Tickets: []tickets.Ticket{
 					{
 						Id:             "436bf9b0-1147-4c0a-8439-6f79833bff5b",
@@ -1635,8 +1732,20 @@ func TestSearch(t *testing.T) {
 				},
 			},
 			result: SearchResult{
-				Organizations: nil,
-				Tickets:       nil,
+				Organizations: []organizations.Organization{
+					{
+						Id:            101,
+						URL:           "http://initech.zendesk.com/api/v2/organizations/101.json",
+						ExternalId:    "9270ed79-35eb-4a38-a46f-35725197ea8d",
+						Name:          "Enthaze",
+						DomainNames:   []string{"kage.com", "ecratic.com", "endipin.com", "zentix.com"},
+						CreatedAt:     "2016-05-21T11:10:28 -10:00",
+						Details:       "MegaCorp",
+						SharedTickets: false,
+						Tags:          []string{"Fulton", "West", "Rodriguez", "Farley"},
+					},
+				},
+				Tickets: nil,
 				Users: []users.User{
 					{
 						Id:             1,
@@ -2235,15 +2344,359 @@ func TestSearch(t *testing.T) {
 			Group:         tt.group,
 			Value:         tt.value,
 			Ident:         tt.ident,
-			Organizations: tt.organizations,
-			Tickets:       tt.tickets,
-			Users:         tt.users,
+			Organizations: orgSlice(tt.organizations),
+			Tickets:       ticketSlice(tt.tickets),
+			Users:         userSlice(tt.users),
 		}
 		result := SearchData(searchInput)
 		assert.Equal(t, tt.result, result)
+
+		// the indexed lookups must return the same result as a store scanning its records
+		searchInput.Organizations = organizations.BuildIndex(tt.organizations)
+		searchInput.Tickets = tickets.BuildIndex(tt.tickets)
+		searchInput.Users = users.BuildIndex(tt.users)
+		result = SearchData(searchInput)
+		assert.Equal(t, tt.result, result)
+	}
+}
+
+func TestSearchMatchModes(t *testing.T) {
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}}
+	ticketList := []tickets.Ticket{{Id: "a", Subject: "A Catastrophe in Korea (North)", OrganizationId: 101}, {Id: "b", Subject: "A Drama in Portugal", Description: "Nostrud ea dolor", OrganizationId: 102}}
+	userList := []users.User{{Id: 1, Name: "Francisca Rasmussen", OrganizationId: 101}, {Id: 2, Name: "Cross Barlow", OrganizationId: 102}}
+	tests := []struct {
+		test   string
+		search Search
+		result SearchResult
+	}{
+		{
+			test:   "UsersSubstring",
+			search: Search{Group: SearchGroupUsers, Ident: "name", Match: match.Substring, Value: "francisca"},
+			result: SearchResult{Users: userList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "UsersExactIsCaseSensitive",
+			search: Search{Group: SearchGroupUsers, Ident: "name", Match: match.Exact, Value: "francisca rasmussen"},
+			result: SearchResult{},
+		},
+		{
+			test:   "UsersIgnoreCase",
+			search: Search{Group: SearchGroupUsers, Ident: "name", Match: match.IgnoreCase, Value: "francisca rasmussen"},
+			result: SearchResult{Users: userList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "TicketsGlob",
+			search: Search{Group: SearchGroupTickets, Ident: "subject", Match: match.Glob, Value: "a * in korea*"},
+			result: SearchResult{Tickets: ticketList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "OrganizationsPrefix",
+			search: Search{Group: SearchGroupOrganizations, Ident: "name", Match: match.Prefix, Value: "nutra"},
+			result: SearchResult{Organizations: orgList[1:], Tickets: ticketList[1:], Users: userList[1:]},
+		},
+		{
+			test:   "TicketsTextRanked",
+			search: Search{Group: SearchGroupTickets, Ident: "subject", Match: match.Text, Value: "korea catastrophes"},
+			result: SearchResult{Tickets: ticketList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "TicketsTextAcrossFields",
+			search: Search{Group: SearchGroupTickets, Match: match.Text, Value: "drama nostrud"},
+			result: SearchResult{Tickets: ticketList[1:], Organizations: orgList[1:]},
+		},
+		{
+			test:   "TicketsTextOneField",
+			search: Search{Group: SearchGroupTickets, Ident: "subject", Match: match.Text, Value: "drama nostrud"},
+			result: SearchResult{},
+		},
+		{
+			test:   "UsersTextOnNonTextField",
+			search: Search{Group: SearchGroupUsers, Ident: "name", Match: match.Text, Value: "rasmussen"},
+			result: SearchResult{Users: userList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "TicketsRegex",
+			search: Search{Group: SearchGroupTickets, Ident: "subject", Match: match.Regex, Value: "(Korea|Micronesia)"},
+			result: SearchResult{Tickets: ticketList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "InvalidPattern",
+			search: Search{Group: SearchGroupUsers, Ident: "name", Match: match.Regex, Value: "(francisca"},
+			result: SearchResult{},
+		},
+		{
+			test:   "UnknownMatchMode",
+			search: Search{Group: SearchGroupOrganizations, Ident: "name", Match: "fuzzy", Value: "nutra"},
+			result: SearchResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.search.Organizations = orgSlice(orgList)
+		tt.search.Tickets = ticketSlice(ticketList)
+		tt.search.Users = userSlice(userList)
+		assert.Equal(t, tt.result, SearchData(tt.search), tt.test)
+
+		// a compiled pattern gives the same result as compiling the value while searching
+		if err := tt.search.Compile(); err == nil {
+			assert.Equal(t, tt.result, SearchData(tt.search), tt.test)
+		}
+
+		// partial matches scan the data so the result is the same with indexes built
+		tt.search.Organizations = organizations.BuildIndex(orgList)
+		tt.search.Tickets = tickets.BuildIndex(ticketList)
+		tt.search.Users = users.BuildIndex(userList)
+		assert.Equal(t, tt.result, SearchData(tt.search), tt.test)
+	}
+}
+
+func TestSearchLinksTicketUsers(t *testing.T) {
+	userList := []users.User{{Id: 38, Name: "Elma Castro"}, {Id: 24, Name: "Harris Côpeland"}, {Id: 5, Name: "Loraine Pittman"}}
+	ticketList := []tickets.Ticket{
+		{Id: "a", Subject: "A Catastrophe in Korea (North)", SubmitterId: 38, AssigneeId: 24},
+		{Id: "b", Subject: "A Drama in Portugal", SubmitterId: 24, AssigneeId: 24},
+		{Id: "c", Subject: "A Problem in Russian Federation", SubmitterId: 5, AssigneeId: 38},
+	}
+	tests := []struct {
+		test   string
+		search Search
+		result SearchResult
+	}{
+		{
+			test:   "TicketSubmitterAndAssignee",
+			search: Search{Group: SearchGroupTickets, Ident: "_id", Value: "a"},
+			result: SearchResult{Tickets: ticketList[:1], Users: userList[:2]},
+		},
+		{
+			test:   "TicketSubmitterIsAssignee",
+			search: Search{Group: SearchGroupTickets, Ident: "_id", Value: "b"},
+			result: SearchResult{Tickets: ticketList[1:2], Users: userList[1:2]},
+		},
+		{
+			test:   "MultipleTicketsNotLinked",
+			search: Search{Group: SearchGroupTickets, Ident: "subject", Match: match.Prefix, Value: "a "},
+			result: SearchResult{Tickets: ticketList},
+		},
+		{
+			test:   "UserSubmittedAndAssignedTickets",
+			search: Search{Group: SearchGroupUsers, Ident: "_id", Value: "24"},
+			result: SearchResult{Users: userList[1:2], Tickets: []tickets.Ticket{ticketList[1], ticketList[0]}},
+		},
+		{
+			test:   "UserAssignedTickets",
+			search: Search{Group: SearchGroupUsers, Ident: "_id", Value: "38"},
+			result: SearchResult{Users: userList[:1], Tickets: []tickets.Ticket{ticketList[0], ticketList[2]}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.search.Tickets = ticketSlice(ticketList)
+		tt.search.Users = userSlice(userList)
+		assert.Equal(t, tt.result, SearchData(tt.search), tt.test)
+
+		tt.search.Tickets = tickets.BuildIndex(ticketList)
+		tt.search.Users = users.BuildIndex(userList)
+		assert.Equal(t, tt.result, SearchData(tt.search), tt.test)
 	}
 }
 
+func TestSearchQuery(t *testing.T) {
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}}
+	ticketList := []tickets.Ticket{
+		{Id: "a", Status: "pending", Priority: "high", Via: "web", Tags: []string{"Ohio"}, OrganizationId: 101},
+		{Id: "b", Status: "pending", Priority: "urgent", Via: "chat", Tags: []string{"Ohio"}, OrganizationId: 102},
+		{Id: "c", Status: "pending", Priority: "urgent", Via: "voice", Tags: []string{"Texas"}, OrganizationId: 102},
+		{Id: "d", Status: "open", Priority: "high", Via: "web", Tags: []string{"Ohio"}, OrganizationId: 101},
+	}
+	userList := []users.User{{Id: 1, Name: "Francisca Rasmussen", Role: "admin", OrganizationId: 101}, {Id: 2, Name: "Cross Barlow", Role: "admin", OrganizationId: 102}}
+	tests := []struct {
+		test   string
+		group  string
+		query  string
+		match  string
+		result SearchResult
+	}{
+		{
+			test:   "TicketsAndOrNot",
+			group:  SearchGroupTickets,
+			query:  "status:pending AND (priority:high OR priority:urgent) NOT via:chat",
+			result: SearchResult{Tickets: []tickets.Ticket{ticketList[0], ticketList[2]}, Organizations: orgList},
+		},
+		{
+			test:   "TicketsSingleResultLinksOrganization",
+			group:  SearchGroupTickets,
+			query:  "status:pending AND priority:high AND tags:Ohio",
+			result: SearchResult{Tickets: ticketList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "UsersMatchModeAppliesToEveryTerm",
+			group:  SearchGroupUsers,
+			query:  "role:admin AND name:francisca",
+			match:  match.Prefix,
+			result: SearchResult{Users: userList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "OrganizationsNot",
+			group:  SearchGroupOrganizations,
+			query:  "NOT name:Enthaze",
+			result: SearchResult{Organizations: orgList[1:], Tickets: ticketList[1:3], Users: userList[1:]},
+		},
+		{
+			test:   "NoResult",
+			group:  SearchGroupUsers,
+			query:  "role:admin NOT role:admin",
+			result: SearchResult{},
+		},
+		{
+			test:   "InvalidPattern",
+			group:  SearchGroupUsers,
+			query:  `name:"(francisca"`,
+			match:  match.Regex,
+			result: SearchResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		expr, err := query.Parse(tt.query)
+		assert.Nil(t, err, tt.test)
+		searchInput := Search{
+			Group:         tt.group,
+			Query:         expr,
+			Match:         tt.match,
+			Organizations: orgSlice(orgList),
+			Tickets:       ticketSlice(ticketList),
+			Users:         userSlice(userList),
+		}
+		assert.Equal(t, tt.result, SearchData(searchInput), tt.test)
+
+		// the query scans the data so the result is the same with indexes built
+		searchInput.Organizations = organizations.BuildIndex(orgList)
+		searchInput.Tickets = tickets.BuildIndex(ticketList)
+		searchInput.Users = users.BuildIndex(userList)
+		assert.Equal(t, tt.result, SearchData(searchInput), tt.test)
+	}
+}
+
+func TestSearchRange(t *testing.T) {
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}}
+	ticketList := []tickets.Ticket{
+		{Id: "a", Status: "pending", DueAt: "2016-07-31T02:37:50 -10:00", SubmitterId: 38, OrganizationId: 101},
+		{Id: "b", Status: "open", DueAt: "2016-08-01T02:37:50 -10:00", SubmitterId: 71, OrganizationId: 102},
+		{Id: "c", Status: "pending", DueAt: "", SubmitterId: 9, OrganizationId: 102},
+	}
+	userList := []users.User{
+		{Id: 1, Role: "admin", LastLoginAt: "2013-08-04T01:03:27 -10:00", OrganizationId: 101},
+		{Id: 2, Role: "admin", LastLoginAt: "2016-08-04T01:03:27 -10:00", OrganizationId: 102},
+	}
+	tests := []struct {
+		test   string
+		search Search
+		query  string
+		result SearchResult
+	}{
+		{
+			test:   "TicketsDueBefore",
+			search: Search{Group: SearchGroupTickets, Ident: "due_at", Match: match.Range, Value: "<2016-08-01"},
+			result: SearchResult{Tickets: ticketList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "TicketsSubmitterBetween",
+			search: Search{Group: SearchGroupTickets, Ident: "submitter_id", Match: match.Range, Value: "10..80"},
+			result: SearchResult{Tickets: ticketList[:2], Organizations: orgList},
+		},
+		{
+			test:   "UsersNotLoggedInSince",
+			search: Search{Group: SearchGroupUsers, Ident: "last_login_at", Match: match.Range, Value: "<2014"},
+			result: SearchResult{Users: userList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "OrganizationsId",
+			search: Search{Group: SearchGroupOrganizations, Ident: "_id", Match: match.Range, Value: ">101"},
+			result: SearchResult{Organizations: orgList[1:], Tickets: ticketList[1:], Users: userList[1:]},
+		},
+		{
+			test:   "QueryRangeTermWithExactTerm",
+			search: Search{Group: SearchGroupTickets},
+			query:  "status:pending AND due_at:<2016-08-01",
+			result: SearchResult{Tickets: ticketList[:1], Organizations: orgList[:1]},
+		},
+		{
+			test:   "QueryRangeOnStringFieldIsExact",
+			search: Search{Group: SearchGroupTickets},
+			query:  "status:>pending",
+			result: SearchResult{},
+		},
+		{
+			test:   "NotANumberOrDateField",
+			search: Search{Group: SearchGroupTickets, Ident: "status", Match: match.Range, Value: ">a"},
+			result: SearchResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.query != "" {
+			expr, err := query.Parse(tt.query)
+			assert.Nil(t, err, tt.test)
+			tt.search.Query = expr
+		}
+		tt.search.Organizations = orgSlice(orgList)
+		tt.search.Tickets = ticketSlice(ticketList)
+		tt.search.Users = userSlice(userList)
+		assert.Equal(t, tt.result, SearchData(tt.search), tt.test)
+	}
+}
+
+func TestSearchCompileRange(t *testing.T) {
+	s := Search{Group: SearchGroupTickets, Ident: "due_at", Match: match.Range, Value: "<2016-08-01"}
+	assert.Nil(t, s.Compile())
+
+	s = Search{Group: SearchGroupTickets, Ident: "due_at", Match: match.Range, Value: "<soon"}
+	assert.NotNil(t, s.Compile())
+
+	s = Search{Group: SearchGroupTickets, Ident: "status", Match: match.Range, Value: "<2016-08-01"}
+	assert.EqualError(t, s.Compile(), `field "status" is not a number or date, ranges need one`)
+
+	expr, err := query.Parse("status:pending AND assignee_id:>fifty")
+	assert.Nil(t, err)
+	s = Search{Group: SearchGroupTickets, Query: expr}
+	assert.NotNil(t, s.Compile())
+}
+
+func TestSearchCompileQuery(t *testing.T) {
+	expr, err := query.Parse(`name:"^Fran" OR name:"[Cross"`)
+	assert.Nil(t, err)
+	s := Search{Match: match.Regex, Query: expr}
+	assert.NotNil(t, s.Compile())
+
+	expr, err = query.Parse(`name:"^Fran" OR name:"^Cross"`)
+	assert.Nil(t, err)
+	s = Search{Match: match.Regex, Query: expr}
+	assert.Nil(t, s.Compile())
+}
+
+func TestValidQuery(t *testing.T) {
+	expr, err := query.Parse("status:pending AND priority:high")
+	assert.Nil(t, err)
+	assert.Nil(t, ValidQuery(SearchGroupTickets, expr))
+	assert.EqualError(t, ValidQuery(SearchGroupUsers, expr), `unknown field "status" for users`)
+	assert.EqualError(t, ValidQuery(SearchGroupTickets, query.Term{Field: WildcardField, Value: "pending"}), `unknown field "*" for tickets`)
+}
+
+func TestSearchCompile(t *testing.T) {
+	s := Search{Match: match.Regex, Value: "^8335-"}
+	assert.Nil(t, s.Compile())
+	assert.NotNil(t, s.Pattern)
+	assert.True(t, s.Pattern.MatchString("8335-422-718"))
+
+	s = Search{Match: match.Regex, Value: "[8335"}
+	assert.NotNil(t, s.Compile())
+	assert.Nil(t, s.Pattern)
+
+	s = Search{Match: match.Substring, Value: "[8335"}
+	assert.Nil(t, s.Compile())
+	assert.Nil(t, s.Pattern)
+}
+
 func TestDisplaySearchResults(t *testing.T) {
 	tests := []struct {
 		test  string
@@ -2539,6 +2992,61 @@ func TestDisplaySearchResults(t *testing.T) {
 
 	for _, tt := range tests {
 		SearchResultDisplay(tt.group, tt.input)
+		SearchResultDisplayFormat(display.FormatJSON, tt.group, tt.input)
+		SearchResultDisplayFormat(display.FormatCSV, tt.group, tt.input)
+	}
+}
+
+func TestSearchResultExport(t *testing.T) {
+	sr := SearchResult{
+		Organizations: []organizations.Organization{{Id: 101, Name: "Enthaze"}},
+		Tickets:       []tickets.Ticket{{Id: "a", Subject: "A Catastrophe in Korea (North)", OrganizationId: 101}},
+		Users:         []users.User{{Id: 1, Name: "Francisca Rasmussen", OrganizationId: 101}},
+	}
+	tests := []struct {
+		test   string
+		format string
+		group  string
+		prefix string
+		err    bool
+	}{
+		{
+			test:   "OrganizationsCSV",
+			format: display.FormatCSV,
+			group:  SearchGroupOrganizations,
+			prefix: "_id,url,external_id,name,domain_names",
+		},
+		{
+			test:   "TicketsTSV",
+			format: display.FormatTSV,
+			group:  SearchGroupTickets,
+			prefix: "_id\turl\texternal_id\tcreated_at",
+		},
+		{
+			test:   "UsersJSON",
+			format: display.FormatJSON,
+			group:  SearchGroupUsers,
+			prefix: "[\n  {\n    \"_id\": 1,",
+		},
+		{
+			test:   "TextNotExportable",
+			format: display.FormatText,
+			group:  SearchGroupUsers,
+			err:    true,
+		},
+		{
+			test:   "InvalidGroup",
+			format: display.FormatCSV,
+			group:  "test",
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		err := SearchResultExport(&buf, tt.format, tt.group, sr)
+		assert.Equal(t, tt.err, err != nil, tt.test)
+		assert.True(t, strings.HasPrefix(buf.String(), tt.prefix), tt.test)
 	}
 }
 
@@ -2553,9 +3061,9 @@ func BenchmarkOrganizationsSearch(b *testing.B) {
 		Group:         "Organizations",
 		Value:         "125",
 		Ident:         "_id",
-		Organizations: orgs,
-		Tickets:       tickets,
-		Users:         users,
+		Organizations: orgSlice(orgs),
+		Tickets:       ticketSlice(tickets),
+		Users:         userSlice(users),
 	}
 
 	for i := 0; i < b.N; i++ {
@@ -2574,9 +3082,9 @@ func BenchmarkTicketsSearch(b *testing.B) {
 		Group:         "Tickets",
 		Value:         "125",
 		Ident:         "organization_id",
-		Organizations: orgs,
-		Tickets:       tickets,
-		Users:         users,
+		Organizations: orgSlice(orgs),
+		Tickets:       ticketSlice(tickets),
+		Users:         userSlice(users),
 	}
 
 	for i := 0; i < b.N; i++ {
@@ -2595,12 +3103,322 @@ func BenchmarkUsersSearch(b *testing.B) {
 		Group:         "Users",
 		Value:         "125",
 		Ident:         "organization_id",
-		Organizations: orgs,
-		Tickets:       tickets,
-		Users:         users,
+		Organizations: orgSlice(orgs),
+		Tickets:       ticketSlice(tickets),
+		Users:         userSlice(users),
 	}
 
 	for i := 0; i < b.N; i++ {
 		_ = SearchData(searchRequest)
 	}
 }
+
+func BenchmarkUsersIndexSearch(b *testing.B) {
+	orgStore, err := organizations.LoadStore("../source_data/organizations.json", jsonstream.Options{})
+	assert.Nil(b, err)
+	ticketStore, err := tickets.LoadStore("../source_data/tickets.json", jsonstream.Options{})
+	assert.Nil(b, err)
+	userStore, err := users.LoadStore("../source_data/users.json", jsonstream.Options{})
+	assert.Nil(b, err)
+	searchRequest := Search{
+		Group:         "Users",
+		Value:         "125",
+		Ident:         "organization_id",
+		Organizations: orgStore,
+		Tickets:       ticketStore,
+		Users:         userStore,
+	}
+
+	for i := 0; i < b.N; i++ {
+		_ = SearchData(searchRequest)
+	}
+}
+
+// syntheticData copies the source data until each group holds tens of thousands of records
+func syntheticData(tb testing.TB, copies int) ([]organizations.Organization, []tickets.Ticket, []users.User) {
+	orgs, err := organizations.LoadOrganizations("../source_data/organizations.json")
+	assert.Nil(tb, err)
+	ticketList, err := tickets.LoadTickets("../source_data/tickets.json")
+	assert.Nil(tb, err)
+	userList, err := users.LoadUsers("../source_data/users.json")
+	assert.Nil(tb, err)
+
+	var orgData []organizations.Organization
+	var ticketData []tickets.Ticket
+	var userData []users.User
+	for c := 0; c < copies; c++ {
+		offset := c * 1000
+		for _, org := range orgs {
+			org.Id += offset
+			orgData = append(orgData, org)
+		}
+		for _, ticket := range ticketList {
+			ticket.Id = fmt.Sprintf("%s-%d", ticket.Id, c)
+			ticket.OrganizationId += offset
+			ticketData = append(ticketData, ticket)
+		}
+		for _, user := range userList {
+			user.Id += offset
+			user.OrganizationId += offset
+			userData = append(userData, user)
+		}
+	}
+	return orgData, ticketData, userData
+}
+
+func TestSearchParallelMatchesSerial(t *testing.T) {
+	orgs, ticketList, userList := syntheticData(t, 100)
+	searches := []Search{
+		{Group: SearchGroupOrganizations, Ident: "details", Match: match.IgnoreCase, Value: "megacorp"},
+		{Group: SearchGroupTickets, Ident: "status", Match: match.IgnoreCase, Value: "Pending"},
+		{Group: SearchGroupUsers, Ident: "role", Match: match.IgnoreCase, Value: "ADMIN"},
+	}
+	for _, s := range searches {
+		s.Organizations = orgSlice(orgs)
+		s.Tickets = ticketSlice(ticketList)
+		s.Users = userSlice(userList)
+		s.Workers = 1
+		serial := SearchData(s)
+		s.Workers = 8
+		assert.Equal(t, serial, SearchData(s))
+	}
+}
+
+// useTestSchema searches the groups and macros of the entity test schema until the test ends, returning their stores
+func useTestSchema(t *testing.T) map[string]store.Store {
+	schema, err := entity.LoadSchema("../entity/test_files/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entity.Use(schema)
+	t.Cleanup(func() {
+		entity.Use(nil)
+	})
+	stores := make(map[string]store.Store)
+	for _, typ := range schema.Types {
+		store, err := typ.LoadStore(jsonstream.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stores[typ.Name] = store
+	}
+	return stores
+}
+
+// recordIds returns the ids of the schema records in order
+func recordIds(records []entity.Record) (ids []int) {
+	for _, rec := range records {
+		ids = append(ids, rec.Id())
+	}
+	return
+}
+
+func TestSearchEntities(t *testing.T) {
+	stores := useTestSchema(t)
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}}
+	userList := []users.User{{Id: 1, Name: "Francisca Rasmussen", OrganizationId: 101}, {Id: 2, Name: "Cross Barlow", OrganizationId: 102}}
+	tests := []struct {
+		test          string
+		search        Search
+		records       []int
+		linked        map[string][]int
+		organizations []organizations.Organization
+		users         []users.User
+	}{
+		{
+			test:    "MacrosLinkTheirGroupsAndAuthors",
+			search:  Search{Group: "macros", Ident: "group_id", Value: "2"},
+			records: []int{11, 12},
+			linked:  map[string][]int{"groups": {2}},
+			users:   userList[1:],
+		},
+		{
+			test:          "SingleGroupLinksItsMacros",
+			search:        Search{Group: "groups", Ident: "name", Value: "Billing"},
+			records:       []int{2},
+			linked:        map[string][]int{"macros": {11, 12}},
+			organizations: orgList[1:],
+		},
+		{
+			test:    "ListValue",
+			search:  Search{Group: "macros", Ident: "tags", Value: "thanks"},
+			records: []int{10},
+			linked:  map[string][]int{"groups": {1}},
+			users:   userList[:1],
+		},
+		{
+			test:    "TextMatch",
+			search:  Search{Group: "macros", Ident: "title", Match: match.Text, Value: "billing"},
+			records: []int{12},
+			linked:  map[string][]int{"groups": {2}},
+		},
+		{
+			test:          "SubstringMatch",
+			search:        Search{Group: "groups", Ident: "name", Match: match.Substring, Value: "ill"},
+			records:       []int{2},
+			linked:        map[string][]int{"macros": {11, 12}},
+			organizations: orgList[1:],
+		},
+		{
+			test:          "DateRange",
+			search:        Search{Group: "groups", Ident: "created_at", Match: match.Range, Value: ">=2016-05-01"},
+			records:       []int{2},
+			linked:        map[string][]int{"macros": {11, 12}},
+			organizations: orgList[1:],
+		},
+		{
+			test:    "JoinOnRelation",
+			search:  Search{Group: "macros", Query: query.Term{Field: "group.name", Value: "Support"}},
+			records: []int{10},
+			linked:  map[string][]int{"groups": {1}},
+			users:   userList[:1],
+		},
+		{
+			test:    "JoinOnBuiltinRelation",
+			search:  Search{Group: "macros", Ident: "author.name", Value: "Cross Barlow"},
+			records: []int{11},
+			linked:  map[string][]int{"groups": {2}},
+			users:   userList[1:],
+		},
+		{
+			test:   "UnknownField",
+			search: Search{Group: "macros", Ident: "unknown", Value: "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.search.Organizations = organizations.BuildIndex(orgList)
+		tt.search.Users = users.BuildIndex(userList)
+		tt.search.Entities = stores
+		if !assert.Nil(t, tt.search.Compile(), tt.test) {
+			continue
+		}
+		result := SearchData(tt.search)
+		assert.Equal(t, tt.records, recordIds(result.Records), tt.test)
+		linked := make(map[string][]int)
+		for name, records := range result.Linked {
+			linked[name] = recordIds(records)
+		}
+		if tt.linked == nil {
+			tt.linked = map[string][]int{}
+		}
+		assert.Equal(t, tt.linked, linked, tt.test)
+		assert.Equal(t, tt.organizations, result.Organizations, tt.test)
+		assert.Equal(t, tt.users, result.Users, tt.test)
+		assert.Equal(t, len(tt.records), result.Count(tt.search.Group), tt.test)
+	}
+}
+
+func TestSearchLinksReferringEntities(t *testing.T) {
+	stores := useTestSchema(t)
+	orgs := organizations.BuildIndex([]organizations.Organization{{Id: 101, Name: "Enthaze"}, {Id: 102, Name: "Nutralab"}})
+	userStore := users.BuildIndex([]users.User{{Id: 1, Name: "Francisca Rasmussen"}, {Id: 2, Name: "Cross Barlow"}})
+
+	result := SearchData(Search{Group: SearchGroupOrganizations, Ident: "_id", Value: "101", Organizations: orgs, Users: userStore, Entities: stores})
+	assert.Equal(t, []int{1}, recordIds(result.Linked["groups"]))
+	result = SearchData(Search{Group: SearchGroupUsers, Ident: "_id", Value: "2", Organizations: orgs, Users: userStore, Entities: stores})
+	assert.Equal(t, []int{11}, recordIds(result.Linked["macros"]))
+	// several organizations link no schema records
+	result = SearchData(Search{Group: SearchGroupOrganizations, Ident: "_id", Match: match.Prefix, Value: "10", Organizations: orgs, Entities: stores})
+	assert.Nil(t, result.Linked)
+}
+
+func TestEntityGroups(t *testing.T) {
+	useTestSchema(t)
+	assert.Equal(t, []string{SearchGroupUsers, SearchGroupTickets, SearchGroupOrganizations, "groups", "macros"}, Groups())
+	group, ok := ParseGroup("Macros")
+	assert.True(t, ok)
+	assert.Equal(t, "macros", group)
+	assert.True(t, ValidSearchTerms("macros", "title"))
+	assert.True(t, ValidSearchTerms("macros", "group.name"))
+	assert.True(t, ValidSearchTerms("macros", "author.role"))
+	assert.True(t, ValidSearchTerms("groups", "organization.name"))
+	assert.False(t, ValidSearchTerms("macros", "group.title"))
+	assert.False(t, ValidSearchTerms("macros", "name"))
+	assert.Nil(t, ValidQuery("macros", query.Term{Field: "active", Value: "true"}))
+
+	entity.Use(nil)
+	_, ok = ParseGroup("macros")
+	assert.False(t, ok)
+	assert.False(t, ValidSearchTerms("macros", "title"))
+}
+
+func TestEntityResultExport(t *testing.T) {
+	stores := useTestSchema(t)
+	sr := SearchData(Search{Group: "macros", Ident: "_id", Value: "10", Entities: stores,
+		Users: users.BuildIndex([]users.User{{Id: 1, Name: "Francisca Rasmussen"}})})
+
+	var buf bytes.Buffer
+	assert.Nil(t, SearchResultExport(&buf, display.FormatCSV, "macros", sr))
+	assert.Equal(t, "_id,title,active,group_id,author_id,tags\n10,Close and thank the customer,true,1,1,close;thanks\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, SearchResultExport(&buf, display.FormatJSON, "macros", sr))
+	assert.Contains(t, buf.String(), "\"group\": {\n      \"_id\": 1,")
+	assert.Contains(t, buf.String(), "\"author\": {\n      \"_id\": 1,")
+
+	assert.NotNil(t, SearchResultExport(&buf, display.FormatCSV, "ratings", sr))
+}
+
+func BenchmarkParallelScan(b *testing.B) {
+	orgs, ticketList, userList := syntheticData(b, 100)
+	scans := []Search{
+		{Group: SearchGroupOrganizations, Ident: "details", Match: match.IgnoreCase, Value: "megacorp"},
+		{Group: SearchGroupTickets, Ident: "status", Match: match.IgnoreCase, Value: "Pending"},
+		{Group: SearchGroupUsers, Ident: "role", Match: match.IgnoreCase, Value: "ADMIN"},
+	}
+	for _, s := range scans {
+		s.Organizations = organizations.BuildIndex(orgs)
+		s.Tickets = tickets.BuildIndex(ticketList)
+		s.Users = users.BuildIndex(userList)
+		matcher, err := s.matcher()
+		assert.Nil(b, err)
+		for _, workers := range []int{1, 0} {
+			s.Workers = workers
+			name := s.Group + "/Serial"
+			if workers == 0 {
+				name = s.Group + "/Parallel"
+			}
+			b.Run(name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_ = s.scanRecords(s.Ident, matcher)
+				}
+			})
+		}
+	}
+}
+
+func TestSearchErr(t *testing.T) {
+	assert.Nil(t, Search{}.Err())
+	failed := errors.New("database is locked")
+	assert.Equal(t, failed, Search{StoreErr: func() error { return failed }}.Err())
+}
+
+// matchStore a users store answering the match modes with the users it holds rather than being scanned
+type matchStore struct {
+	*store.Index
+	modes []string
+}
+
+func (m *matchStore) SearchMatch(ident string, mode string, value string) ([]interface{}, bool) {
+	m.modes = append(m.modes, mode)
+	if mode == match.Regex {
+		return nil, false
+	}
+	return []interface{}{users.User{Id: 9, Name: value}}, true
+}
+
+func TestSearchMatchStore(t *testing.T) {
+	ms := &matchStore{Index: users.BuildIndex([]users.User{{Id: 1, Name: "Rose Newton"}})}
+	s := Search{Group: SearchGroupUsers, Ident: "name", Match: match.Prefix, Value: "Ro", Users: ms}
+	assert.Nil(t, s.Compile())
+	assert.Equal(t, []users.User{{Id: 9, Name: "Ro"}}, SearchData(s).Users)
+
+	// the store is scanned when it cannot answer the mode, and exact matches use its index
+	s.Match, s.Value = match.Regex, "^Rose"
+	assert.Nil(t, s.Compile())
+	assert.Equal(t, []users.User{{Id: 1, Name: "Rose Newton"}}, SearchData(s).Users)
+	s.Match, s.Value = match.Exact, "Rose Newton"
+	assert.Equal(t, []users.User{{Id: 1, Name: "Rose Newton"}}, SearchData(s).Users)
+	assert.Equal(t, []string{match.Prefix, match.Regex}, ms.modes)
+}
@@ -0,0 +1,63 @@
+package search
+
+import (
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+)
+
+// sliceStore a store without indexes answering every lookup by scanning, standing in for stores other than the
+// in-memory index so searches are checked against both
+type sliceStore struct {
+	fields  *fields.Registry
+	records []interface{}
+}
+
+func (s sliceStore) Get(id string) (interface{}, bool) {
+	for _, rec := range s.records {
+		if s.fields.Values(rec, store.IdField)[0] == id {
+			return rec, true
+		}
+	}
+	return nil, false
+}
+
+func (s sliceStore) Search(ident string, value string) (records []interface{}) {
+	for _, rec := range s.records {
+		if s.fields.Match(rec, ident, func(v string) bool { return v == value }) {
+			records = append(records, rec)
+		}
+	}
+	return
+}
+
+func (s sliceStore) SearchText(query string, fields ...string) []interface{} {
+	return store.BuildIndex(s.fields, s.records).SearchText(query, fields...)
+}
+
+func (s sliceStore) Len() int {
+	return len(s.records)
+}
+
+func (s sliceStore) Each(start int, end int, fn func(interface{})) {
+	for _, rec := range s.records[start:end] {
+		fn(rec)
+	}
+}
+
+// orgSlice an organizations store answering every lookup by scanning
+func orgSlice(orgList []organizations.Organization) store.Store {
+	return sliceStore{fields: organizations.Fields(), records: organizations.Records(orgList)}
+}
+
+// ticketSlice a tickets store answering every lookup by scanning
+func ticketSlice(ticketList []tickets.Ticket) store.Store {
+	return sliceStore{fields: tickets.Fields(), records: tickets.Records(ticketList)}
+}
+
+// userSlice a users store answering every lookup by scanning
+func userSlice(userList []users.User) store.Store {
+	return sliceStore{fields: users.Fields(), records: users.Records(userList)}
+}
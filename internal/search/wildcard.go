@@ -0,0 +1,146 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/fulltext"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+)
+
+// RecordMatch a record of the group holding the searched value and the fields holding it, in field order
+type RecordMatch struct {
+	Group  string
+	Fields []string
+	Result SearchResult // holds the single record found, no records are linked
+}
+
+// recordMatchRecord a record match as written to JSON
+type recordMatchRecord struct {
+	Fields []string    `json:"fields"`
+	Record interface{} `json:"record"`
+}
+
+// SearchFields searches Value against every searchable field of the search group in the search match mode, the
+// wildcard field. Every record holding the value is returned once with the fields holding it, records are in the
+// order they are first found field by field. A range is only compared to the number or date fields it can be
+// compared to. A text search ranks the records holding every word across the text fields first, in order of
+// relevance, as the words may sit in different fields
+func SearchFields(s Search) (matches []RecordMatch) {
+	found := make(map[string]int)
+	r := registryOf(s.Group)
+	if s.Match == match.Text {
+		matches = s.textMatches()
+		for i, m := range matches {
+			found[m.Result.ids(m.Group)[0]] = i
+		}
+	}
+	for _, m := range s.fieldMatches(s.Group) {
+		if s.Match == match.Text && r.FullText(m.Field) {
+			// the text fields holding the words are found with the ranked records
+			continue
+		}
+		for i, id := range m.ids() {
+			if at, ok := found[id]; ok {
+				matches[at].Fields = inFieldOrder(r, append(matches[at].Fields, m.Field))
+				continue
+			}
+			found[id] = len(matches)
+			matches = append(matches, RecordMatch{Group: m.Group, Fields: []string{m.Field}, Result: m.Result.at(m.Group, i)})
+		}
+	}
+	return
+}
+
+// textMatches returns the records holding every word of Value across the text fields of the search group ranked by
+// relevance, each with the text fields holding a word of it
+func (s Search) textMatches() (matches []RecordMatch) {
+	r := registryOf(s.Group)
+	holds := func(v string) bool {
+		return fulltext.ContainsAny(v, s.Value)
+	}
+	for _, rec := range s.storeOf(s.Group).SearchText(s.Value) {
+		m := RecordMatch{Group: s.Group}
+		for _, field := range r.FullTextNames() {
+			if r.Match(rec, field, holds) {
+				m.Fields = append(m.Fields, field)
+			}
+		}
+		m.Result.add(rec)
+		matches = append(matches, m)
+	}
+	return
+}
+
+// inFieldOrder returns the names in the order of the fields of the registry
+func inFieldOrder(r *fields.Registry, names []string) (ordered []string) {
+	held := make(map[string]bool, len(names))
+	for _, name := range names {
+		held[name] = true
+	}
+	for _, name := range r.Names() {
+		if held[name] {
+			ordered = append(ordered, name)
+		}
+	}
+	return
+}
+
+// at returns the result holding only the record found in the group at position i
+func (sr SearchResult) at(group string, i int) (result SearchResult) {
+	result.add(sr.found(group)[i])
+	return
+}
+
+// record returns the record holding the value
+func (m RecordMatch) record() interface{} {
+	return m.Result.found(m.Group)[0]
+}
+
+// displayMatches returns the record matches as displayed, each record summarised on one line
+func displayMatches(matches []RecordMatch) []display.RecordMatch {
+	records := make([]display.RecordMatch, 0, len(matches))
+	for _, m := range matches {
+		records = append(records, display.RecordMatch{Id: m.Result.ids(m.Group)[0], Record: m.Result.summaries(m.Group)[0], Fields: m.Fields})
+	}
+	return records
+}
+
+// RecordMatchesDisplayFormat displays the records of a wildcard search and the fields holding the value in the
+// output format
+func RecordMatchesDisplayFormat(format string, matches []RecordMatch) {
+	switch format {
+	case display.FormatJSON:
+		display.DisplayJSON(RecordMatchRecords(matches))
+	case display.FormatCSV, display.FormatTSV:
+		if err := RecordMatchesExport(os.Stdout, format, matches); err != nil {
+			display.ExportFailed(err)
+		}
+	default:
+		display.DisplayRecordMatches(displayMatches(matches))
+	}
+}
+
+// RecordMatchRecords returns the records of a wildcard search, each with the fields holding the value
+func RecordMatchRecords(matches []RecordMatch) interface{} {
+	records := make([]recordMatchRecord, 0, len(matches))
+	for _, m := range matches {
+		records = append(records, recordMatchRecord{Fields: m.Fields, Record: m.record()})
+	}
+	return records
+}
+
+// RecordMatchesExport writes the records of a wildcard search to w as JSON, or as CSV or TSV with one row per record
+// holding its id and the fields holding the value
+func RecordMatchesExport(w io.Writer, format string, matches []RecordMatch) error {
+	if !display.ExportFormat(format) {
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+	if format == display.FormatJSON {
+		return display.WriteJSON(w, RecordMatchRecords(matches))
+	}
+	return display.WriteRecordMatchesDelimited(w, format, displayMatches(matches))
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordMatchFields returns the id of each record match followed by the fields holding the value
+func recordMatchFields(matches []RecordMatch) (fields [][]string) {
+	for _, m := range matches {
+		fields = append(fields, append(m.Result.ids(m.Group), m.Fields...))
+	}
+	return
+}
+
+func TestSearchFields(t *testing.T) {
+	base := allTestSearch(t)
+	tests := []struct {
+		test    string
+		group   string
+		match   string
+		value   string
+		matches [][]string
+	}{
+		{
+			test:  "FieldsOfEachRecord",
+			group: SearchGroupUsers,
+			value: "2",
+			matches: [][]string{
+				{"2", "_id"},
+			},
+		},
+		{
+			test:  "RecordInSeveralFields",
+			group: "macros",
+			value: "2",
+			matches: [][]string{
+				{"11", "group_id", "author_id"},
+				{"12", "group_id"},
+			},
+		},
+		{
+			test:  "OnlyTheGroup",
+			group: SearchGroupOrganizations,
+			value: "2",
+		},
+		{
+			test:  "Substring",
+			group: SearchGroupTickets,
+			match: match.Substring,
+			value: "korea",
+			matches: [][]string{
+				{"436bf9b0", "subject"},
+			},
+		},
+		{
+			test:  "NumberRangeSkipsTextFields",
+			group: SearchGroupUsers,
+			match: match.Range,
+			value: "1..101",
+			matches: [][]string{
+				{"1", "_id", "organization_id"},
+				{"2", "_id"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		s := base
+		s.Group = tt.group
+		s.Ident = WildcardField
+		s.Match = tt.match
+		s.Value = tt.value
+		if !assert.Nil(t, s.Compile(), tt.test) {
+			continue
+		}
+		assert.Equal(t, tt.matches, recordMatchFields(SearchFields(s)), tt.test)
+	}
+
+	assert.NotNil(t, (&Search{Group: SearchGroupUsers, Ident: WildcardField, Match: match.Range, Value: "high"}).Compile())
+}
+
+func TestSearchFieldsText(t *testing.T) {
+	s := Search{
+		Group: SearchGroupTickets,
+		Ident: WildcardField,
+		Match: match.Text,
+		Value: "catastrophe nostrud",
+		Tickets: tickets.BuildIndex([]tickets.Ticket{
+			{Id: "a", Subject: "A Catastrophe in Korea", Description: "Nostrud ea dolor"},
+			{Id: "b", Subject: "A Drama in Portugal", Description: "Nostrud"},
+			{Id: "c", Subject: "A Nuisance in Ghana", Description: "Nostrud catastrophe nostrud"},
+			{Id: "d", Type: "Nostrud catastrophe", Subject: "A Drama in Micronesia"},
+			{Id: "e", Type: "Catastrophe nostrud", Subject: "Catastrophe in Nostrud"},
+		}),
+	}
+	// the words held across the text fields are ranked first, then the records holding them in another field
+	assert.Equal(t, [][]string{
+		{"e", "type", "subject"},
+		{"c", "description"},
+		{"a", "subject", "description"},
+		{"d", "type"},
+	}, recordMatchFields(SearchFields(s)))
+}
+
+func TestRecordMatchesExport(t *testing.T) {
+	s := allTestSearch(t)
+	s.Group = "macros"
+	s.Ident = WildcardField
+	s.Value = "2"
+	matches := SearchFields(s)
+
+	var buf bytes.Buffer
+	assert.Nil(t, RecordMatchesExport(&buf, display.FormatCSV, matches))
+	assert.Equal(t, "_id,fields\n11,group_id;author_id\n12,group_id\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, RecordMatchesExport(&buf, display.FormatJSON, matches[:1]))
+	assert.Contains(t, buf.String(), "\"fields\": [\n      \"group_id\",\n      \"author_id\"\n    ],\n    \"record\": {\n      \"_id\": 11,")
+
+	assert.NotNil(t, RecordMatchesExport(&buf, "xml", matches))
+}
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+	"github.com/nicholas-boyson/wordsearch/internal/query"
+	"github.com/nicholas-boyson/wordsearch/internal/search"
+)
+
+// resource paths served, each maps to a search group. The entities of the schema are served under their name
+var resources = map[string]string{
+	"users":         search.SearchGroupUsers,
+	"tickets":       search.SearchGroupTickets,
+	"organizations": search.SearchGroupOrganizations,
+}
+
+const (
+	// matchParam query parameter selecting the match mode of a search
+	matchParam = "match"
+	// queryParam query parameter holding a compound query combining fields with AND, OR and NOT
+	queryParam = "q"
+	// valueParam query parameter holding the value searched across every field of every group
+	valueParam = "value"
+	// allResource path searching a value across every group
+	allResource = "all"
+)
+
+// Server serves the search engine over HTTP returning JSON
+type Server struct {
+	base func() search.Search
+}
+
+// errorResponse body returned for any failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New returns a server searching over the data and indexes held by base
+func New(base search.Search) *Server {
+	return NewLive(func() search.Search {
+		return base
+	})
+}
+
+// NewLive returns a server searching over the data and indexes current returns at the start of each request, so the
+// data can be replaced while the server runs. A request in flight keeps searching the data it started with
+func NewLive(current func() search.Search) *Server {
+	return &Server{base: current}
+}
+
+// ServeHTTP routes the request
+//
+//	GET /{group}?{field}={value}     records of the group matching the field, &match= selects the match mode
+//	GET /{group}?*={value}           records of the group holding the value in any field, with the fields holding it
+//	GET /{group}?q={query}           records of the group holding a query such as status:pending AND priority:high
+//	GET /{group}/{id}                the record with the id and its linked records
+//	GET /organizations/{id}/{group}  the tickets or users linked to the organization
+//	GET /all?value={value}           the fields of every group holding the value, &match= selects the match mode
+//
+// {group} is users, tickets, organizations or an entity of the schema
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] == allResource && len(parts) == 1 {
+		s.searchAll(w, r)
+		return
+	}
+	group, ok := resources[parts[0]]
+	if _, schemaEntity := entity.Lookup(parts[0]); !ok && schemaEntity {
+		group, ok = parts[0], true
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown resource %q", parts[0]))
+		return
+	}
+	switch len(parts) {
+	case 1:
+		s.searchGroup(w, r, group)
+	case 2:
+		s.getRecord(w, group, parts[1])
+	case 3:
+		s.getLinked(w, group, parts[1], parts[2])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// searchGroup searches the group on the single field provided in the query string
+func (s *Server) searchGroup(w http.ResponseWriter, r *http.Request, group string) {
+	params := r.URL.Query()
+	request := s.base()
+	request.Group = group
+	request.Match = params.Get(matchParam)
+	params.Del(matchParam)
+	if !match.Valid(request.Match) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid match mode %q", request.Match))
+		return
+	}
+	if _, ok := params[queryParam]; ok {
+		if len(params) != 1 {
+			writeError(w, http.StatusBadRequest, "provide either a query or one field to search on, not both")
+			return
+		}
+		expr, err := query.Parse(params.Get(queryParam))
+		if err == nil {
+			err = search.ValidQuery(group, expr)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid query: %s", err))
+			return
+		}
+		request.Query = expr
+	} else {
+		if len(params) != 1 {
+			writeError(w, http.StatusBadRequest, "provide exactly one field to search on e.g. ?email=value")
+			return
+		}
+		for ident, values := range params {
+			request.Ident = ident
+			request.Value = values[0]
+		}
+		if !search.ValidSearchTerms(group, request.Ident) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid search term %q", request.Ident))
+			return
+		}
+	}
+	if err := request.Compile(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if request.Ident == search.WildcardField {
+		matches := search.SearchFields(request)
+		if err := request.Err(); err != nil {
+			writeSearchFailed(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, search.RecordMatchRecords(matches))
+		return
+	}
+	result := search.SearchData(request)
+	if err := request.Err(); err != nil {
+		writeSearchFailed(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, search.SearchResultRecords(group, result))
+}
+
+// searchAll searches the value provided in the query string across every field of every group
+func (s *Server) searchAll(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	request := s.base()
+	request.Group = search.SearchGroupAll
+	request.Match = params.Get(matchParam)
+	params.Del(matchParam)
+	if !match.Valid(request.Match) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid match mode %q", request.Match))
+		return
+	}
+	if _, ok := params[valueParam]; !ok || len(params) != 1 {
+		writeError(w, http.StatusBadRequest, "provide only the value to search for e.g. ?value=pending")
+		return
+	}
+	request.Value = params.Get(valueParam)
+	if err := request.Compile(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	matches := search.SearchAll(request)
+	if err := request.Err(); err != nil {
+		writeSearchFailed(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, search.FieldMatchRecords(matches))
+}
+
+// getRecord returns the single record of the group with the id
+func (s *Server) getRecord(w http.ResponseWriter, group string, id string) {
+	result, ok, err := s.find(group, id)
+	if err != nil {
+		writeSearchFailed(w, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s not found", strings.ToLower(group), id))
+		return
+	}
+	switch group {
+	case search.SearchGroupOrganizations:
+		writeJSON(w, http.StatusOK, display.OrganizationRecords(result.Organizations, result.Tickets, result.Users)[0])
+	case search.SearchGroupTickets:
+		writeJSON(w, http.StatusOK, display.TicketRecords(result.Tickets, result.Organizations, result.Users)[0])
+	case search.SearchGroupUsers:
+		writeJSON(w, http.StatusOK, display.UserRecords(result.Users, result.Organizations, result.Tickets)[0])
+	default:
+		t, _ := entity.Lookup(group)
+		writeJSON(w, http.StatusOK, display.EntityRecords(t, result.Records, result.Organizations, result.Users, result.Linked)[0])
+	}
+}
+
+// getLinked returns the records of the linked group for the record with the id
+func (s *Server) getLinked(w http.ResponseWriter, group string, id string, linked string) {
+	linkedGroup, ok := resources[linked]
+	if !ok || group != search.SearchGroupOrganizations || linkedGroup == search.SearchGroupOrganizations {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	result, ok, err := s.find(group, id)
+	if err != nil {
+		writeSearchFailed(w, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s not found", strings.ToLower(group), id))
+		return
+	}
+	if linkedGroup == search.SearchGroupTickets {
+		writeJSON(w, http.StatusOK, display.TicketRecords(result.Tickets, result.Organizations, nil))
+		return
+	}
+	writeJSON(w, http.StatusOK, display.UserRecords(result.Users, result.Organizations, nil))
+}
+
+// find searches the group by id, ok is false unless exactly one record is found and err is the error of the stores
+func (s *Server) find(group string, id string) (search.SearchResult, bool, error) {
+	request := s.base()
+	request.Group = group
+	request.Ident = "_id"
+	request.Value = id
+	result := search.SearchData(request)
+	if err := request.Err(); err != nil {
+		return search.SearchResult{}, false, err
+	}
+	return result, result.Count(group) == 1, nil
+}
+
+// writeSearchFailed reports the stores failed while searching, the results found are incomplete so none are returned
+func writeSearchFailed(w http.ResponseWriter, err error) {
+	writeError(w, http.StatusInternalServerError, fmt.Sprintf("search failed: %s", err))
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,411 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/search"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer returns a server over the bundled source data
+func newTestServer(t *testing.T) *Server {
+	orgStore, err := organizations.LoadStore("../source_data/organizations.json", jsonstream.Options{})
+	assert.Nil(t, err)
+	ticketStore, err := tickets.LoadStore("../source_data/tickets.json", jsonstream.Options{})
+	assert.Nil(t, err)
+	userStore, err := users.LoadStore("../source_data/users.json", jsonstream.Options{})
+	assert.Nil(t, err)
+	return New(search.Search{
+		Organizations: orgStore,
+		Tickets:       ticketStore,
+		Users:         userStore,
+	})
+}
+
+func TestServeHTTP(t *testing.T) {
+	srv := newTestServer(t)
+	tests := []struct {
+		test   string
+		method string
+		target string
+		status int
+		count  int
+		object bool
+		id     interface{}
+	}{
+		{
+			test:   "SearchUsersByEmail",
+			method: http.MethodGet,
+			target: "/users?email=coffeyrasmussen@flotonic.com",
+			status: http.StatusOK,
+			count:  1,
+			id:     float64(1),
+		},
+		{
+			test:   "SearchTicketsNoResult",
+			method: http.MethodGet,
+			target: "/tickets?status=unknown",
+			status: http.StatusOK,
+			count:  0,
+		},
+		{
+			test:   "SearchOrganizationsMultipleResults",
+			method: http.MethodGet,
+			target: "/organizations?details=MegaCorp",
+			status: http.StatusOK,
+			count:  9,
+			id:     float64(101),
+		},
+		{
+			test:   "SearchUsersSubstring",
+			method: http.MethodGet,
+			target: "/users?name=francisca&match=substring",
+			status: http.StatusOK,
+			count:  1,
+			id:     float64(1),
+		},
+		{
+			test:   "SearchUsersRegex",
+			method: http.MethodGet,
+			target: "/users?phone=%5E8335-&match=regex",
+			status: http.StatusOK,
+			count:  1,
+			id:     float64(1),
+		},
+		{
+			test:   "SearchInvalidPattern",
+			method: http.MethodGet,
+			target: "/users?phone=%5B8335&match=regex",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "SearchTicketsQuery",
+			method: http.MethodGet,
+			target: "/tickets?q=" + url.QueryEscape(`_id:436bf9b0-1147-4c0a-8439-6f79833bff5b OR subject:"A Nuisance in Kiribati"`),
+			status: http.StatusOK,
+			count:  2,
+			id:     "436bf9b0-1147-4c0a-8439-6f79833bff5b",
+		},
+		{
+			test:   "SearchOrganizationsRange",
+			method: http.MethodGet,
+			target: "/organizations?_id=" + url.QueryEscape("<=101") + "&match=range",
+			status: http.StatusOK,
+			count:  1,
+			id:     float64(101),
+		},
+		{
+			test:   "SearchInvalidRange",
+			method: http.MethodGet,
+			target: "/organizations?name=" + url.QueryEscape(">101") + "&match=range",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "SearchTicketsJoin",
+			method: http.MethodGet,
+			target: "/tickets?q=" + url.QueryEscape("_id:436bf9b0-1147-4c0a-8439-6f79833bff5b assignee.name:\"Harris Côpeland\""),
+			status: http.StatusOK,
+			count:  1,
+			id:     "436bf9b0-1147-4c0a-8439-6f79833bff5b",
+		},
+		{
+			test:   "SearchInvalidQuery",
+			method: http.MethodGet,
+			target: "/tickets?q=" + url.QueryEscape("status:pending AND"),
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "SearchQueryAndField",
+			method: http.MethodGet,
+			target: "/tickets?q=status:pending&priority=high",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "SearchInvalidMatchMode",
+			method: http.MethodGet,
+			target: "/users?name=francisca&match=fuzzy",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "SearchInvalidField",
+			method: http.MethodGet,
+			target: "/users?invalid=1",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "SearchWithoutField",
+			method: http.MethodGet,
+			target: "/users",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "GetTicket",
+			method: http.MethodGet,
+			target: "/tickets/436bf9b0-1147-4c0a-8439-6f79833bff5b",
+			status: http.StatusOK,
+			object: true,
+			id:     "436bf9b0-1147-4c0a-8439-6f79833bff5b",
+		},
+		{
+			test:   "GetUser",
+			method: http.MethodGet,
+			target: "/users/1",
+			status: http.StatusOK,
+			object: true,
+			id:     float64(1),
+		},
+		{
+			test:   "GetMissingOrganization",
+			method: http.MethodGet,
+			target: "/organizations/999",
+			status: http.StatusNotFound,
+		},
+		{
+			test:   "GetOrganizationTickets",
+			method: http.MethodGet,
+			target: "/organizations/101/tickets",
+			status: http.StatusOK,
+			count:  4,
+		},
+		{
+			test:   "GetOrganizationUsers",
+			method: http.MethodGet,
+			target: "/organizations/101/users",
+			status: http.StatusOK,
+			count:  4,
+		},
+		{
+			test:   "GetUserOrganizations",
+			method: http.MethodGet,
+			target: "/users/1/organizations",
+			status: http.StatusNotFound,
+		},
+		{
+			test:   "UnknownResource",
+			method: http.MethodGet,
+			target: "/groups/1",
+			status: http.StatusNotFound,
+		},
+		{
+			test:   "SearchEveryField",
+			method: http.MethodGet,
+			target: "/users?*=coffeyrasmussen@flotonic.com",
+			status: http.StatusOK,
+			count:  1,
+		},
+		{
+			test:   "SearchEveryFieldInvalidPattern",
+			method: http.MethodGet,
+			target: "/users?*=[8335&match=regex",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "SearchAll",
+			method: http.MethodGet,
+			target: "/all?value=coffeyrasmussen@flotonic.com",
+			status: http.StatusOK,
+			count:  1,
+		},
+		{
+			test:   "SearchAllNoResult",
+			method: http.MethodGet,
+			target: "/all?value=unknown",
+			status: http.StatusOK,
+			count:  0,
+		},
+		{
+			test:   "SearchAllWithoutValue",
+			method: http.MethodGet,
+			target: "/all",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "SearchAllWithField",
+			method: http.MethodGet,
+			target: "/all?value=1&name=Enthaze",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "SearchAllInvalidRange",
+			method: http.MethodGet,
+			target: "/all?value=high&match=range",
+			status: http.StatusBadRequest,
+		},
+		{
+			test:   "GetAllById",
+			method: http.MethodGet,
+			target: "/all/1",
+			status: http.StatusNotFound,
+		},
+		{
+			test:   "MethodNotAllowed",
+			method: http.MethodPost,
+			target: "/users",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		srv.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+		assert.Equal(t, tt.status, recorder.Code, tt.test)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), tt.test)
+		if tt.status != http.StatusOK {
+			var body errorResponse
+			assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body), tt.test)
+			assert.NotEmpty(t, body.Error, tt.test)
+			continue
+		}
+		if tt.object {
+			var body map[string]interface{}
+			assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body), tt.test)
+			assert.Equal(t, tt.id, body["_id"], tt.test)
+			continue
+		}
+		var body []map[string]interface{}
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body), tt.test)
+		assert.Len(t, body, tt.count, tt.test)
+		if tt.id != nil {
+			assert.Equal(t, tt.id, body[0]["_id"], tt.test)
+		}
+	}
+}
+
+func TestServeHTTPLinkedRecords(t *testing.T) {
+	srv := newTestServer(t)
+
+	recorder := httptest.NewRecorder()
+	srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/tickets/436bf9b0-1147-4c0a-8439-6f79833bff5b", nil))
+	var ticket display.TicketRecord
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &ticket))
+	assert.Equal(t, "Elma Castro", ticket.Submitter.Name)
+	assert.Equal(t, "Harris Côpeland", ticket.Assignee.Name)
+
+	recorder = httptest.NewRecorder()
+	srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/24", nil))
+	var user display.UserRecord
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &user))
+	assert.Len(t, user.AssignedTickets, 4)
+	for _, assigned := range user.AssignedTickets {
+		assert.Equal(t, 24, assigned.AssigneeId)
+	}
+	for _, submitted := range user.SubmittedTickets {
+		assert.Equal(t, 24, submitted.SubmitterId)
+	}
+}
+
+func TestServeHTTPServer(t *testing.T) {
+	ts := httptest.NewServer(newTestServer(t))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/tickets/436bf9b0-1147-4c0a-8439-6f79833bff5b")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var body map[string]interface{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&body))
+	organization, ok := body["organization"].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, float64(116), organization["_id"])
+}
+
+func TestServeHTTPSchemaEntities(t *testing.T) {
+	schema, err := entity.LoadSchema("../entity/test_files/schema.json")
+	assert.Nil(t, err)
+	entity.Use(schema)
+	defer entity.Use(nil)
+	base := search.Search{Entities: make(map[string]store.Store)}
+	for _, typ := range schema.Types {
+		base.Entities[typ.Name], err = typ.LoadStore(jsonstream.Options{})
+		assert.Nil(t, err)
+	}
+	srv := New(base)
+
+	recorder := httptest.NewRecorder()
+	srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/macros?group.name=Billing", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var macros []map[string]interface{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &macros))
+	assert.Len(t, macros, 2)
+
+	recorder = httptest.NewRecorder()
+	srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/groups/2", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var group map[string]interface{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &group))
+	assert.Equal(t, "Billing", group["name"])
+	assert.Len(t, group["macros"], 2)
+
+	recorder = httptest.NewRecorder()
+	srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/all?value=Billing", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var matches []map[string]interface{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &matches))
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, "groups", matches[0]["group"])
+		assert.Equal(t, "name", matches[0]["field"])
+		assert.Equal(t, float64(1), matches[0]["count"])
+	}
+
+	recorder = httptest.NewRecorder()
+	srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/macros?*=2", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var records []map[string]interface{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &records))
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, []interface{}{"group_id", "author_id"}, records[0]["fields"])
+		assert.Equal(t, "Ask for a refund reason", records[0]["record"].(map[string]interface{})["title"])
+	}
+
+	recorder = httptest.NewRecorder()
+	srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/groups/3", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = httptest.NewRecorder()
+	srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ratings/1", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestNewLive(t *testing.T) {
+	var current search.Search
+	srv := NewLive(func() search.Search {
+		return current
+	})
+	get := func() int {
+		recorder := httptest.NewRecorder()
+		srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		return recorder.Code
+	}
+
+	// every request searches the data current at the time
+	assert.Equal(t, http.StatusNotFound, get())
+	current.Users = users.BuildIndex([]users.User{{Id: 1, Name: "Francisca Rasmussen"}})
+	assert.Equal(t, http.StatusOK, get())
+}
+
+func TestServeHTTPStoreErr(t *testing.T) {
+	base := search.Search{
+		Users:    users.BuildIndex([]users.User{{Id: 1, Name: "Francisca Rasmussen"}}),
+		StoreErr: func() error { return errors.New("database is locked") },
+	}
+	srv := New(base)
+	for _, target := range []string{"/users?name=Francisca%20Rasmussen", "/users?*=Francisca", "/users/1", "/organizations/101/users", "/all?value=1"} {
+		recorder := httptest.NewRecorder()
+		srv.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code, target)
+		var body errorResponse
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body), target)
+		assert.Equal(t, "search failed: database is locked", body.Error, target)
+	}
+}
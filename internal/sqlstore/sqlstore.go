@@ -0,0 +1,787 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/fulltext"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+
+	// the sqlite3 driver, registered when imported
+	"github.com/mattn/go-sqlite3"
+)
+
+// kinds of column, deciding how a field is stored and how a search value is compared to it
+const (
+	kindText = iota
+	kindInt  // whole number
+	kindBool // true or false held as 1 or 0
+	kindRef  // id of a record of another table, NULL when the record has none
+	kindList // list of values e.g. tags, held one per row in a table of its own
+)
+
+// batchSize most positions selected by a single statement
+const batchSize = 500
+
+// column a searchable field of a table
+type column struct {
+	field string
+	kind  int
+	ref   string // table referenced by a kindRef column
+}
+
+// table the columns of an entity in the order of its searchable fields, the first column is the key.
+// Every row also holds its position in the loaded dataset so records are returned in load order
+type table struct {
+	name    string
+	listKey string           // column of the list tables holding the key of the row e.g. user_id
+	fields  *fields.Registry // the searchable fields of the entity, the free text fields are ranked by SearchText
+	columns []column
+}
+
+// newTable returns the table of an entity with a column per searchable field of the registry
+func newTable(name string, listKey string, registry *fields.Registry) table {
+	t := table{name: name, listKey: listKey, fields: registry}
+	for _, f := range registry.Fields() {
+		c := column{field: f.Name}
+		switch {
+		case f.Kind == fields.List:
+			c.kind = kindList
+		case f.Ref != "":
+			c.kind, c.ref = kindRef, f.Ref
+		case f.Kind == fields.Int:
+			c.kind = kindInt
+		case f.Kind == fields.Bool:
+			c.kind = kindBool
+		}
+		t.columns = append(t.columns, c)
+	}
+	return t
+}
+
+// the tables of the entities, the columns follow the searchable fields of their structs
+var (
+	orgTable    = newTable("organizations", "organization_id", organizations.Fields())
+	ticketTable = newTable("tickets", "ticket_id", tickets.Fields())
+	userTable   = newTable("users", "user_id", users.Fields())
+)
+
+// DB a SQLite database holding the users, tickets and organizations, filled from the JSON datasets by Import.
+// It is searched through the stores of a Session
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens the SQLite database file, creating it and its tables when they do not exist
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	d := &DB{db: db}
+	if err := d.createTables(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Session returns the stores of the database for a single search, so the errors of one search are not reported by another
+func (d *DB) Session() *Session {
+	return &Session{db: d}
+}
+
+// Session the stores of the database searched by a single search. The store methods cannot return errors, the first
+// statement that failed is kept and returned by Err
+type Session struct {
+	db  *DB
+	mu  sync.Mutex
+	err error
+}
+
+// Err returns the first statement of the session that failed, nil when every search ran
+func (s *Session) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// fail keeps the first error of the session for Err
+func (s *Session) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Organizations returns the organizations store of the session
+func (s *Session) Organizations() Store {
+	return Store{session: s, t: orgTable}
+}
+
+// Tickets returns the tickets store of the session
+func (s *Session) Tickets() Store {
+	return Store{session: s, t: ticketTable}
+}
+
+// Users returns the users store of the session
+func (s *Session) Users() Store {
+	return Store{session: s, t: userTable}
+}
+
+// Empty reports whether the database holds no users, tickets or organizations, as when it has just been created
+func (d *DB) Empty() (bool, error) {
+	for _, t := range []table{orgTable, ticketTable, userTable} {
+		n, err := d.count(t)
+		if err != nil || n > 0 {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Import replaces the data of the database in a single transaction, read streams the datasets into the Importer
+// and the data is kept as it was when read fails. Foreign keys are declared but not enforced so references to
+// missing records are kept as they are in the datasets
+func (d *DB) Import(read func(*Importer) error) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	im, err := newImporter(tx)
+	if err == nil {
+		err = read(im)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Importer inserts the records of the datasets as they are read, in the order they are loaded. A record whose id is
+// held by an earlier record is refused with a *jsonstream.InvalidRecord, which a lenient read rejects and reports
+type Importer struct {
+	orgs    *inserter
+	tickets *inserter
+	users   *inserter
+}
+
+// newImporter deletes the rows of every table then prepares the inserts of every table
+func newImporter(tx *sql.Tx) (*Importer, error) {
+	for _, t := range []table{ticketTable, userTable, orgTable} {
+		for _, c := range t.columns {
+			if c.kind == kindList {
+				if _, err := tx.Exec("DELETE FROM " + quote(t.list(c))); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if _, err := tx.Exec("DELETE FROM " + quote(t.name)); err != nil {
+			return nil, err
+		}
+	}
+	for _, stmt := range []string{"DELETE FROM text_terms", "DELETE FROM text_lengths"} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return nil, err
+		}
+	}
+	im := &Importer{}
+	var err error
+	if im.orgs, err = newInserter(tx, orgTable); err != nil {
+		return nil, err
+	}
+	if im.tickets, err = newInserter(tx, ticketTable); err != nil {
+		return nil, err
+	}
+	if im.users, err = newInserter(tx, userTable); err != nil {
+		return nil, err
+	}
+	return im, nil
+}
+
+// Organization inserts the next organization of the dataset
+func (im *Importer) Organization(org organizations.Organization) error {
+	if err := im.orgs.insert(org); err != nil {
+		return importErr(err, fmt.Sprintf("organization %d", org.Id))
+	}
+	return nil
+}
+
+// Ticket inserts the next ticket of the dataset
+func (im *Importer) Ticket(ticket tickets.Ticket) error {
+	if err := im.tickets.insert(ticket); err != nil {
+		return importErr(err, fmt.Sprintf("ticket %s", ticket.Id))
+	}
+	return nil
+}
+
+// User inserts the next user of the dataset
+func (im *Importer) User(user users.User) error {
+	if err := im.users.insert(user); err != nil {
+		return importErr(err, fmt.Sprintf("user %d", user.Id))
+	}
+	return nil
+}
+
+// importErr returns the error inserting the record, a record refused for its values is returned as it is so the
+// reader can reject it
+func importErr(err error, record string) error {
+	var refused *jsonstream.InvalidRecord
+	if errors.As(err, &refused) {
+		return err
+	}
+	return fmt.Errorf("%s: %s", record, err)
+}
+
+// createTables creates the tables of every entity with an index on every searchable field, and the tables
+// holding the terms of the free text fields
+func (d *DB) createTables() error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS text_terms (entity TEXT NOT NULL, field TEXT NOT NULL, term TEXT NOT NULL, position INTEGER NOT NULL, frequency INTEGER NOT NULL, PRIMARY KEY (entity, field, term, position))`,
+		`CREATE TABLE IF NOT EXISTS text_lengths (entity TEXT NOT NULL, field TEXT NOT NULL, position INTEGER NOT NULL, length INTEGER NOT NULL, PRIMARY KEY (entity, field, position))`,
+	}
+	for _, t := range []table{orgTable, userTable, ticketTable} {
+		stmts = append(stmts, t.create()...)
+	}
+	for _, stmt := range stmts {
+		if _, err := d.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// create returns the statements creating the table, its list tables and their indexes
+func (t table) create() []string {
+	key := t.columns[0]
+	defs := []string{quote(key.field) + " " + t.keyType() + " PRIMARY KEY", `"position" INTEGER NOT NULL UNIQUE`}
+	var indexes []string
+	for _, c := range t.columns[1:] {
+		switch c.kind {
+		case kindList:
+			list := t.list(c)
+			indexes = append(indexes,
+				fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s %s NOT NULL REFERENCES %s(%s), "position" INTEGER NOT NULL, "value" TEXT NOT NULL, PRIMARY KEY (%s, "position"))`,
+					quote(list), quote(t.listKey), t.keyType(), quote(t.name), quote(key.field), quote(t.listKey)),
+				fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s("value")`, quote(list+"_value"), quote(list)))
+			continue
+		case kindRef:
+			defs = append(defs, fmt.Sprintf(`%s INTEGER REFERENCES %s("_id")`, quote(c.field), quote(c.ref)))
+		case kindInt, kindBool:
+			defs = append(defs, quote(c.field)+" INTEGER NOT NULL")
+		default:
+			defs = append(defs, quote(c.field)+" TEXT NOT NULL")
+		}
+		indexes = append(indexes, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s(%s)`, quote(t.name+"_"+c.field), quote(t.name), quote(c.field)))
+	}
+	return append([]string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quote(t.name), strings.Join(defs, ", "))}, indexes...)
+}
+
+// keyType the SQL type of the key column
+func (t table) keyType() string {
+	if t.columns[0].kind == kindInt {
+		return "INTEGER"
+	}
+	return "TEXT"
+}
+
+// list returns the name of the table holding the values of a list column e.g. user_tags
+func (t table) list(c column) string {
+	return strings.TrimSuffix(t.listKey, "_id") + "_" + c.field
+}
+
+// column returns the column of a field
+func (t table) column(field string) (column, bool) {
+	for _, c := range t.columns {
+		if c.field == field {
+			return c, true
+		}
+	}
+	return column{}, false
+}
+
+// selected the columns selected for every row, the key then the columns held in the table in order
+func (t table) selected() string {
+	var names []string
+	for _, c := range t.columns {
+		if c.kind != kindList {
+			names = append(names, quote(c.field))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// scan reads the columns held in the table of the current row into record, a pointer to the entity struct
+func (t table) scan(rows *sql.Rows, record interface{}) error {
+	v := reflect.ValueOf(record).Elem()
+	var dest []interface{}
+	refs := make(map[int]*sql.NullInt64)
+	for _, f := range t.fields.Fields() {
+		switch {
+		case f.Kind == fields.List:
+			continue
+		case f.Ref != "":
+			// a record without a reference holds NULL, read as 0 as the JSON datasets do
+			refs[f.Index] = &sql.NullInt64{}
+			dest = append(dest, refs[f.Index])
+		default:
+			dest = append(dest, v.Field(f.Index).Addr().Interface())
+		}
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	for index, ref := range refs {
+		v.Field(index).SetInt(ref.Int64)
+	}
+	return nil
+}
+
+// appendList appends a value of a list field to record, a pointer to the entity struct
+func (t table) appendList(record interface{}, field string, value string) {
+	f, ok := t.fields.Field(field)
+	if !ok {
+		return
+	}
+	list := reflect.ValueOf(record).Elem().Field(f.Index)
+	list.Set(reflect.Append(list, reflect.ValueOf(value)))
+}
+
+// where returns the condition selecting the rows holding exactly value for field, ok is false when no row can
+// hold it e.g. a value that is not a number for a number field
+func (t table) where(field string, value string) (where string, args []interface{}, ok bool) {
+	c, ok := t.column(field)
+	if !ok {
+		return "", nil, false
+	}
+	switch c.kind {
+	case kindList:
+		return fmt.Sprintf(`%s IN (SELECT %s FROM %s WHERE "value" = ?)`, quote(t.columns[0].field), quote(t.listKey), quote(t.list(c))), []interface{}{value}, true
+	case kindInt, kindRef:
+		n, err := strconv.Atoi(value)
+		if err != nil || strconv.Itoa(n) != value {
+			return "", nil, false
+		}
+		if c.kind == kindRef && n == 0 {
+			// records without a reference are searched as 0, as the JSON datasets read them
+			return quote(c.field) + " IS NULL", nil, true
+		}
+		return quote(c.field) + " = ?", []interface{}{n}, true
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil || strconv.FormatBool(b) != value {
+			return "", nil, false
+		}
+		return quote(c.field) + " = ?", []interface{}{b}, true
+	default:
+		return quote(c.field) + " = ?", []interface{}{value}, true
+	}
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, the statements declare \ as the escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// whereMatch returns the condition selecting the rows whose value of field matches value in the match mode, as the
+// scan of the in-memory stores matches them. ok is false when the mode is not answered in SQL: ignorecase, text and
+// regex, ranges of timestamps, true or false fields, and values that are not ASCII, which LIKE and lower only fold
+// for ASCII letters
+func (t table) whereMatch(field string, mode string, value string) (where string, args []interface{}, ok bool) {
+	c, ok := t.column(field)
+	if !ok || c.kind == kindBool {
+		return "", nil, false
+	}
+	expr := quote(c.field)
+	switch c.kind {
+	case kindRef:
+		// records without a reference are matched as 0, as the JSON datasets read them
+		expr = "COALESCE(" + expr + ", 0)"
+	case kindList:
+		expr = `"value"`
+	}
+	switch mode {
+	case match.Prefix, match.Substring, match.Glob:
+		if !ascii(value) {
+			return "", nil, false
+		}
+	}
+	switch mode {
+	case match.Prefix:
+		where, args = expr+` LIKE ? ESCAPE '\'`, []interface{}{likeEscaper.Replace(value) + "%"}
+	case match.Substring:
+		where, args = expr+` LIKE ? ESCAPE '\'`, []interface{}{"%" + likeEscaper.Replace(value) + "%"}
+	case match.Glob:
+		// * and ? are the wildcards of GLOB too, only [ starts a character class
+		where, args = "lower("+expr+") GLOB ?", []interface{}{strings.ReplaceAll(strings.ToLower(value), "[", "[[]")}
+	case match.Range:
+		if c.kind != kindInt && c.kind != kindRef {
+			return "", nil, false
+		}
+		if where, args, ok = intRange(expr, value); !ok {
+			return "", nil, false
+		}
+	default:
+		return "", nil, false
+	}
+	if c.kind == kindList {
+		where = fmt.Sprintf(`%s IN (SELECT %s FROM %s WHERE %s)`, quote(t.columns[0].field), quote(t.listKey), quote(t.list(c)), where)
+	}
+	return where, args, true
+}
+
+// intRange returns the condition selecting the whole numbers of expr within the range, ok is false when the range
+// does not hold whole numbers
+func intRange(expr string, value string) (where string, args []interface{}, ok bool) {
+	b, err := match.ParseRange(value)
+	if err != nil {
+		return "", nil, false
+	}
+	var lower, upper int64
+	if b.HasLower {
+		if lower, err = strconv.ParseInt(b.Lower, 10, 64); err != nil {
+			return "", nil, false
+		}
+	}
+	if b.HasUpper {
+		if upper, err = strconv.ParseInt(b.Upper, 10, 64); err != nil {
+			return "", nil, false
+		}
+	}
+	if b.HasLower && b.HasUpper && b.LowerInclusive && b.UpperInclusive {
+		return expr + " BETWEEN ? AND ?", []interface{}{lower, upper}, true
+	}
+	var conds []string
+	if b.HasLower {
+		op := " > ?"
+		if b.LowerInclusive {
+			op = " >= ?"
+		}
+		conds, args = append(conds, expr+op), append(args, lower)
+	}
+	if b.HasUpper {
+		op := " < ?"
+		if b.UpperInclusive {
+			op = " <= ?"
+		}
+		conds, args = append(conds, expr+op), append(args, upper)
+	}
+	return strings.Join(conds, " AND "), args, true
+}
+
+// ascii reports whether value holds only ASCII characters
+func ascii(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// textFields the free text fields of the table in name order
+func (t table) textFields() []string {
+	names := t.fields.FullTextNames()
+	sort.Strings(names)
+	return names
+}
+
+// inserter inserts the rows of a table with statements prepared once per import, in load order
+type inserter struct {
+	t        table
+	position int // position of the next row
+	row      *sql.Stmt
+	lists    map[string]*sql.Stmt
+	terms    *sql.Stmt
+	lengths  *sql.Stmt
+}
+
+// newInserter prepares the insert statements of a table
+func newInserter(tx *sql.Tx, t table) (*inserter, error) {
+	in := &inserter{t: t, lists: make(map[string]*sql.Stmt)}
+	names := []string{`"position"`}
+	for _, c := range t.columns {
+		if c.kind == kindList {
+			stmt, err := tx.Prepare(fmt.Sprintf(`INSERT INTO %s (%s, "position", "value") VALUES (?, ?, ?)`, quote(t.list(c)), quote(t.listKey)))
+			if err != nil {
+				return nil, err
+			}
+			in.lists[c.field] = stmt
+			continue
+		}
+		names = append(names, quote(c.field))
+	}
+	var err error
+	params := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	if in.row, err = tx.Prepare(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quote(t.name), strings.Join(names, ", "), params)); err != nil {
+		return nil, err
+	}
+	if in.terms, err = tx.Prepare("INSERT INTO text_terms (entity, field, term, position, frequency) VALUES (?, ?, ?, ?, ?)"); err != nil {
+		return nil, err
+	}
+	if in.lengths, err = tx.Prepare("INSERT INTO text_lengths (entity, field, position, length) VALUES (?, ?, ?, ?)"); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// insert inserts the record, a value of the entity struct, after the rows inserted so far
+func (in *inserter) insert(record interface{}) error {
+	values := func(field string) []string {
+		return in.t.fields.Values(record, field)
+	}
+	position := in.position
+	args := []interface{}{position}
+	var key interface{}
+	for i, c := range in.t.columns {
+		if c.kind == kindList {
+			continue
+		}
+		arg, err := columnValue(c, values(c.field))
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			key = arg
+		}
+		args = append(args, arg)
+	}
+	if _, err := in.row.Exec(args...); err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			// the in-memory stores hold every record, the database keeps the first record of an id and rejects the others
+			return &jsonstream.InvalidRecord{Field: in.t.columns[0].field, Err: fmt.Errorf("%s %v is used by an earlier record", in.t.columns[0].field, key)}
+		}
+		return err
+	}
+	for _, c := range in.t.columns {
+		if c.kind == kindList {
+			for i, value := range values(c.field) {
+				if _, err := in.lists[c.field].Exec(key, i, value); err != nil {
+					return err
+				}
+			}
+		}
+		if !in.t.fields.FullText(c.field) {
+			continue
+		}
+		// every record holds a length for each text field, as the in-memory index counts them
+		var terms []string
+		for _, value := range values(c.field) {
+			terms = append(terms, fulltext.Tokenize(value)...)
+		}
+		frequencies := make(map[string]int)
+		for _, term := range terms {
+			frequencies[term]++
+		}
+		for term, frequency := range frequencies {
+			if _, err := in.terms.Exec(in.t.name, c.field, term, position, frequency); err != nil {
+				return err
+			}
+		}
+		if _, err := in.lengths.Exec(in.t.name, c.field, position, len(terms)); err != nil {
+			return err
+		}
+	}
+	in.position++
+	return nil
+}
+
+// columnValue converts the searchable value of a field to the value held in its column
+func columnValue(c column, values []string) (interface{}, error) {
+	value := ""
+	if len(values) > 0 {
+		value = values[0]
+	}
+	switch c.kind {
+	case kindInt, kindRef:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s %q is not a whole number", c.field, value)
+		}
+		if c.kind == kindRef && n == 0 {
+			return nil, nil
+		}
+		return n, nil
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s %q is not true or false", c.field, value)
+		}
+		return b, nil
+	default:
+		return value, nil
+	}
+}
+
+// count returns the number of rows of the table
+func (d *DB) count(t table) (n int, err error) {
+	err = d.db.QueryRow("SELECT COUNT(*) FROM " + quote(t.name)).Scan(&n)
+	return
+}
+
+// fetch selects the rows of the table matching where in load order, calling row for every row then list with the
+// key of the row for every list value in order
+func (d *DB) fetch(t table, where string, args []interface{}, row func(*sql.Rows) error, list func(key string, field string, value string)) error {
+	rows, err := d.db.Query(fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY "position"`, t.selected(), quote(t.name), where), args...)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for rows.Next() {
+		if err := row(rows); err != nil {
+			rows.Close()
+			return err
+		}
+		n++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || n == 0 {
+		return err
+	}
+	for _, c := range t.columns {
+		if c.kind != kindList {
+			continue
+		}
+		rows, err := d.db.Query(fmt.Sprintf(`SELECT %s, "value" FROM %s WHERE %s IN (SELECT %s FROM %s WHERE %s) ORDER BY %s, "position"`,
+			quote(t.listKey), quote(t.list(c)), quote(t.listKey), quote(t.columns[0].field), quote(t.name), where, quote(t.listKey)), args...)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var key, value string
+			if err := rows.Scan(&key, &value); err != nil {
+				rows.Close()
+				return err
+			}
+			list(key, c.field, value)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// positions returns the condition selecting the rows at the positions
+func positions(batch []int) (string, []interface{}) {
+	args := make([]interface{}, len(batch))
+	for i, position := range batch {
+		args[i] = position
+	}
+	return `"position" IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(batch)), ", ") + ")", args
+}
+
+// searchText returns the positions of the rows holding every query term in the text fields ranked by relevance,
+// all text fields when none are given. The rows and scores are those of the in-memory index
+func (d *DB) searchText(t table, query string, fields []string) ([]int, error) {
+	if len(fields) == 0 {
+		fields = t.textFields()
+	}
+	n, err := d.count(t)
+	if err != nil {
+		return nil, err
+	}
+	terms := fulltext.QueryTerms(query)
+	scores := make(map[int]float64)
+	held := make(map[int]map[string]bool)
+	for _, field := range fields {
+		var docs, total int
+		err := d.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(length), 0) FROM text_lengths WHERE entity = ? AND field = ?", t.name, field).Scan(&docs, &total)
+		if err != nil {
+			return nil, err
+		}
+		if docs == 0 {
+			continue
+		}
+		avgLength := float64(total) / float64(docs)
+		for _, term := range terms {
+			postings, err := d.postings(t, field, term)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range postings {
+				scores[p.position] += fulltext.Score(float64(n), float64(len(postings)), float64(p.frequency), float64(p.length), avgLength)
+				fulltext.Hold(held, p.position, term)
+			}
+		}
+	}
+	var ranked []int
+	for _, hit := range fulltext.Rank(fulltext.HoldingAll(scores, held, terms)) {
+		ranked = append(ranked, hit.Doc)
+	}
+	return ranked, nil
+}
+
+// posting a row holding a term in a text field
+type posting struct {
+	position  int
+	frequency int
+	length    int
+}
+
+// postings returns the rows holding the term in the text field
+func (d *DB) postings(t table, field string, term string) (postings []posting, err error) {
+	rows, err := d.db.Query(`SELECT t.position, t.frequency, l.length FROM text_terms t
+		JOIN text_lengths l ON l.entity = t.entity AND l.field = t.field AND l.position = t.position
+		WHERE t.entity = ? AND t.field = ? AND t.term = ?`, t.name, field, term)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p posting
+		if err := rows.Scan(&p.position, &p.frequency, &p.length); err != nil {
+			return nil, err
+		}
+		postings = append(postings, p)
+	}
+	return postings, rows.Err()
+}
+
+// ranked calls find with the ranked positions in batches of ascending positions, which find returns in load
+// order, then returns the index of every found record in rank order
+func ranked(positionList []int, find func(where string, args []interface{}) int) []int {
+	sorted := append([]int(nil), positionList...)
+	sort.Ints(sorted)
+	found := make(map[int]int, len(sorted))
+	for start := 0; start < len(sorted); start += batchSize {
+		end := start + batchSize
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+		where, args := positions(sorted[start:end])
+		first := find(where, args)
+		for i, position := range sorted[start:end] {
+			found[position] = first + i
+		}
+	}
+	order := make([]int, 0, len(positionList))
+	for _, position := range positionList {
+		order = append(order, found[position])
+	}
+	return order
+}
+
+// quote quotes an identifier
+func quote(name string) string {
+	return `"` + name + `"`
+}
@@ -0,0 +1,355 @@
+package sqlstore
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/query"
+	"github.com/nicholas-boyson/wordsearch/internal/search"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+// importLists imports the records of the lists into the database
+func importLists(db *DB, orgList []organizations.Organization, ticketList []tickets.Ticket, userList []users.User) error {
+	return db.Import(func(im *Importer) error {
+		for _, org := range orgList {
+			if err := im.Organization(org); err != nil {
+				return err
+			}
+		}
+		for _, ticket := range ticketList {
+			if err := im.Ticket(ticket); err != nil {
+				return err
+			}
+		}
+		for _, user := range userList {
+			if err := im.User(user); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// importSourceData imports the bundled source data into a new database, returning the in-memory stores loaded
+// from the same files to compare against
+func importSourceData(t *testing.T) (*DB, *store.Index, *store.Index, *store.Index) {
+	orgList, err := organizations.LoadOrganizations("../source_data/organizations.json")
+	assert.Nil(t, err)
+	ticketList, err := tickets.LoadTickets("../source_data/tickets.json")
+	assert.Nil(t, err)
+	userList, err := users.LoadUsers("../source_data/users.json")
+	assert.Nil(t, err)
+
+	db, err := Open(filepath.Join(t.TempDir(), "wordsearch.db"))
+	assert.Nil(t, err)
+	empty, err := db.Empty()
+	assert.Nil(t, err)
+	assert.True(t, empty)
+	assert.Nil(t, importLists(db, orgList, ticketList, userList))
+	empty, err = db.Empty()
+	assert.Nil(t, err)
+	assert.False(t, empty)
+	return db, organizations.BuildIndex(orgList), tickets.BuildIndex(ticketList), users.BuildIndex(userList)
+}
+
+func TestStoresMatchIndex(t *testing.T) {
+	db, orgIndex, ticketIndex, userIndex := importSourceData(t)
+	defer db.Close()
+	session := db.Session()
+	orgStore, ticketStore, userStore := session.Organizations(), session.Tickets(), session.Users()
+
+	assert.Equal(t, orgIndex.Len(), orgStore.Len())
+	assert.Equal(t, ticketIndex.Len(), ticketStore.Len())
+	assert.Equal(t, userIndex.Len(), userStore.Len())
+
+	var orgList []organizations.Organization
+	orgStore.Each(0, orgStore.Len(), func(org interface{}) {
+		orgList = append(orgList, org.(organizations.Organization))
+	})
+	var ticketList []tickets.Ticket
+	ticketStore.Each(0, ticketStore.Len(), func(ticket interface{}) {
+		ticketList = append(ticketList, ticket.(tickets.Ticket))
+	})
+	var userList []users.User
+	userStore.Each(0, userStore.Len(), func(user interface{}) {
+		userList = append(userList, user.(users.User))
+	})
+	assert.Equal(t, orgIndex.Search("_id", "101")[0], orgList[0])
+	assert.Equal(t, ticketIndex.Search("_id", "436bf9b0-1147-4c0a-8439-6f79833bff5b"), tickets.Records(ticketList[:1]))
+	assert.Equal(t, userIndex.Search("_id", "1"), users.Records(userList[:1]))
+
+	// every value of every record is found the same way in both stores
+	for _, org := range orgList {
+		for _, c := range orgTable.columns {
+			for _, value := range organizations.FieldValues(org, c.field) {
+				assert.Equal(t, orgIndex.Search(c.field, value), orgStore.Search(c.field, value), c.field+" "+value)
+			}
+		}
+		found, ok := orgStore.Get(strconv.Itoa(org.Id))
+		assert.True(t, ok)
+		assert.Equal(t, org, found)
+	}
+	for _, ticket := range ticketList {
+		for _, c := range ticketTable.columns {
+			for _, value := range tickets.FieldValues(ticket, c.field) {
+				assert.Equal(t, ticketIndex.Search(c.field, value), ticketStore.Search(c.field, value), c.field+" "+value)
+			}
+		}
+		found, ok := ticketStore.Get(ticket.Id)
+		assert.True(t, ok)
+		assert.Equal(t, ticket, found)
+	}
+	for _, user := range userList {
+		for _, c := range userTable.columns {
+			for _, value := range users.FieldValues(user, c.field) {
+				assert.Equal(t, userIndex.Search(c.field, value), userStore.Search(c.field, value), c.field+" "+value)
+			}
+		}
+		found, ok := userStore.Get(strconv.Itoa(user.Id))
+		assert.True(t, ok)
+		assert.Equal(t, user, found)
+	}
+
+	_, ok := userStore.Get("9999")
+	assert.False(t, ok)
+	assert.Nil(t, userStore.Search("_id", "01"))
+	assert.Nil(t, userStore.Search("active", "yes"))
+	assert.Nil(t, userStore.Search("unknown", "1"))
+	assert.Nil(t, session.Err())
+}
+
+func TestStoresSearchText(t *testing.T) {
+	db, orgIndex, ticketIndex, userIndex := importSourceData(t)
+	defer db.Close()
+	session := db.Session()
+
+	for _, query := range []string{"catastrophe", "problem in korea", "the", "nostrud ad sit"} {
+		assert.Equal(t, ticketIndex.SearchText(query), session.Tickets().SearchText(query), query)
+		assert.Equal(t, ticketIndex.SearchText(query, "subject"), session.Tickets().SearchText(query, "subject"), query)
+	}
+	assert.Equal(t, orgIndex.SearchText("megacorp"), session.Organizations().SearchText("megacorp"))
+	assert.Equal(t, userIndex.SearchText("happy"), session.Users().SearchText("happy"))
+	assert.Nil(t, session.Users().SearchText("the"))
+	assert.Nil(t, session.Err())
+}
+
+// ticketIds returns the ids of the tickets sorted, so searches ranking the same tickets differently compare equal
+func ticketIds(ticketList []tickets.Ticket) []string {
+	ids := make([]string, 0, len(ticketList))
+	for _, ticket := range ticketList {
+		ids = append(ids, ticket.Id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestTextFieldMatchesQueryTerm(t *testing.T) {
+	db, _, ticketIndex, _ := importSourceData(t)
+	defer db.Close()
+	session := db.Session()
+
+	for _, ticketStore := range []store.Store{ticketIndex, session.Tickets()} {
+		for _, value := range []string{"catastrophe korea", "catastrophe", "problem in korea", "the"} {
+			field := search.Search{Group: search.SearchGroupTickets, Ident: "subject", Match: match.Text, Value: value, Tickets: ticketStore}
+			term := search.Search{Group: search.SearchGroupTickets, Match: match.Text, Query: query.Term{Field: "subject", Value: value}, Tickets: ticketStore}
+			assert.Equal(t, ticketIds(search.SearchData(term).Tickets), ticketIds(search.SearchData(field).Tickets), value)
+		}
+		field := search.Search{Group: search.SearchGroupTickets, Ident: "subject", Match: match.Text, Value: "catastrophe korea", Tickets: ticketStore}
+		assert.Len(t, search.SearchData(field).Tickets, 2)
+	}
+	assert.Nil(t, session.Err())
+}
+
+func TestSearchDataOverDatabase(t *testing.T) {
+	db, orgIndex, ticketIndex, userIndex := importSourceData(t)
+	defer db.Close()
+	session := db.Session()
+
+	searches := []search.Search{
+		{Group: search.SearchGroupOrganizations, Ident: "_id", Value: "101"},
+		{Group: search.SearchGroupTickets, Ident: "subject", Match: match.Substring, Value: "korea"},
+		{Group: search.SearchGroupTickets, Ident: "assignee.role", Value: "admin"},
+		{Group: search.SearchGroupUsers, Ident: "last_login_at", Match: match.Range, Value: "<2014"},
+	}
+	for _, s := range searches {
+		indexed := s
+		indexed.Organizations, indexed.Tickets, indexed.Users = orgIndex, ticketIndex, userIndex
+		s.Organizations, s.Tickets, s.Users = session.Organizations(), session.Tickets(), session.Users()
+		assert.Nil(t, s.Compile())
+		assert.Nil(t, indexed.Compile())
+		assert.Equal(t, search.SearchData(indexed), search.SearchData(s), s.Ident)
+	}
+	assert.Nil(t, session.Err())
+}
+
+func TestSearchMatchOverDatabase(t *testing.T) {
+	db, orgIndex, ticketIndex, userIndex := importSourceData(t)
+	defer db.Close()
+	session := db.Session()
+
+	searches := []search.Search{
+		{Group: search.SearchGroupUsers, Ident: "name", Match: match.Prefix, Value: "rose"},
+		{Group: search.SearchGroupUsers, Ident: "email", Match: match.Substring, Value: "FLOTONIC"},
+		{Group: search.SearchGroupUsers, Ident: "phone", Match: match.Glob, Value: "8?35-*"},
+		{Group: search.SearchGroupUsers, Ident: "tags", Match: match.Prefix, Value: "spring"},
+		{Group: search.SearchGroupUsers, Ident: "_id", Match: match.Prefix, Value: "1"},
+		{Group: search.SearchGroupUsers, Ident: "organization_id", Match: match.Range, Value: ">=119"},
+		{Group: search.SearchGroupUsers, Ident: "organization_id", Match: match.Range, Value: "<=101"},
+		{Group: search.SearchGroupUsers, Ident: "_id", Match: match.Range, Value: "between 10 and 20"},
+		{Group: search.SearchGroupUsers, Ident: "signature", Match: match.Substring, Value: "100%_"},
+		{Group: search.SearchGroupTickets, Ident: "subject", Match: match.Glob, Value: "a catastrophe in [*"},
+		{Group: search.SearchGroupTickets, Ident: "subject", Match: match.Glob, Value: "*korea*"},
+		{Group: search.SearchGroupTickets, Ident: "assignee_id", Match: match.Range, Value: "<1"},
+		{Group: search.SearchGroupTickets, Ident: "organization_id", Match: match.Prefix, Value: "0"},
+		{Group: search.SearchGroupOrganizations, Ident: "domain_names", Match: match.Substring, Value: "ZEN"},
+		{Group: search.SearchGroupOrganizations, Ident: "details", Match: match.Glob, Value: "*corp"},
+	}
+	for _, s := range searches {
+		indexed := s
+		indexed.Organizations, indexed.Tickets, indexed.Users = orgIndex, ticketIndex, userIndex
+		s.Organizations, s.Tickets, s.Users = session.Organizations(), session.Tickets(), session.Users()
+		assert.Nil(t, s.Compile())
+		assert.Nil(t, indexed.Compile())
+		assert.Equal(t, search.SearchData(indexed), search.SearchData(s), s.Ident+" "+s.Value)
+	}
+
+	// the modes are answered in SQL, modes and values SQL cannot match as the scan does are left to the scan
+	_, ok := session.Users().SearchMatch("email", match.Substring, "flotonic")
+	assert.True(t, ok)
+	_, ok = session.Tickets().SearchMatch("organization_id", match.Range, "101..110")
+	assert.True(t, ok)
+	_, ok = session.Users().SearchMatch("name", match.Prefix, "Harris Cô")
+	assert.False(t, ok)
+	_, ok = session.Users().SearchMatch("created_at", match.Range, "<2016")
+	assert.False(t, ok)
+	_, ok = session.Users().SearchMatch("active", match.Prefix, "t")
+	assert.False(t, ok)
+	_, ok = session.Users().SearchMatch("name", match.Regex, "^Rose")
+	assert.False(t, ok)
+	assert.Nil(t, session.Err())
+}
+
+func TestImportReplacesData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wordsearch.db")
+	db, err := Open(path)
+	assert.Nil(t, err)
+	orgList := []organizations.Organization{{Id: 101, Name: "Enthaze", Tags: []string{"Fulton"}}}
+	userList := []users.User{{Id: 1, Name: "Francisca Rasmussen", OrganizationId: 101}}
+	ticketList := []tickets.Ticket{{Id: "a", Subject: "A Catastrophe in Korea (North)", SubmitterId: 1, AssigneeId: 555}}
+	assert.Nil(t, importLists(db, orgList, ticketList, userList))
+	assert.Nil(t, importLists(db, orgList, ticketList[:0], userList))
+	assert.Nil(t, db.Close())
+
+	// the data persists once the database is closed
+	db, err = Open(path)
+	assert.Nil(t, err)
+	defer db.Close()
+	session := db.Session()
+	assert.Equal(t, 0, session.Tickets().Len())
+	assert.Equal(t, organizations.Records(orgList), session.Organizations().Search("tags", "Fulton"))
+	assert.Equal(t, users.Records(userList), session.Users().Search("organization_id", "101"))
+}
+
+func TestImportFailedKeepsData(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "wordsearch.db"))
+	assert.Nil(t, err)
+	defer db.Close()
+	session := db.Session()
+	userList := []users.User{{Id: 1, Name: "Francisca Rasmussen"}}
+	assert.Nil(t, importLists(db, nil, nil, userList))
+
+	// a read failing part way through the datasets leaves the data imported before
+	failed := errors.New("loading tickets: record 2: unexpected EOF")
+	assert.Equal(t, failed, db.Import(func(im *Importer) error {
+		assert.Nil(t, im.User(users.User{Id: 2, Name: "Cross Barlow"}))
+		return failed
+	}))
+	assert.Equal(t, users.Records(userList), session.Users().Search("_id", "1"))
+	assert.Equal(t, 1, session.Users().Len())
+
+	// a duplicate id refuses the record, a read that does not reject it fails the import
+	err = importLists(db, nil, nil, append(userList, userList...))
+	var refused *jsonstream.InvalidRecord
+	if assert.True(t, errors.As(err, &refused)) {
+		assert.Equal(t, "_id", refused.Field)
+		assert.Equal(t, "_id 1 is used by an earlier record", err.Error())
+	}
+	assert.Equal(t, 1, session.Users().Len())
+	assert.Nil(t, session.Err())
+}
+
+func TestImportDuplicateId(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "wordsearch.db"))
+	assert.Nil(t, err)
+	defer db.Close()
+	session := db.Session()
+	ticketList := []tickets.Ticket{
+		{Id: "a", Subject: "A Catastrophe in Korea (North)", Tags: []string{"Ohio"}},
+		{Id: "a", Subject: "A Drama in Portugal", Tags: []string{"Utah"}},
+		{Id: "b", Subject: "A Problem in Ethiopia"},
+	}
+
+	// the duplicate is skipped and the records after it keep the load order
+	var rejected []error
+	assert.Nil(t, db.Import(func(im *Importer) error {
+		for _, ticket := range ticketList {
+			var refused *jsonstream.InvalidRecord
+			if err := im.Ticket(ticket); errors.As(err, &refused) {
+				rejected = append(rejected, err)
+			} else if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	assert.Equal(t, []error{&jsonstream.InvalidRecord{Field: "_id", Err: errors.New("_id a is used by an earlier record")}}, rejected)
+	var loaded []tickets.Ticket
+	session.Tickets().Each(0, session.Tickets().Len(), func(ticket interface{}) {
+		loaded = append(loaded, ticket.(tickets.Ticket))
+	})
+	assert.Equal(t, []tickets.Ticket{ticketList[0], ticketList[2]}, loaded)
+	assert.Nil(t, session.Tickets().Search("tags", "Utah"))
+	assert.Nil(t, session.Err())
+}
+
+func TestImportReferences(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "wordsearch.db"))
+	assert.Nil(t, err)
+	defer db.Close()
+	session := db.Session()
+	ticketList := []tickets.Ticket{
+		{Id: "a", SubmitterId: 1, AssigneeId: 555},
+		{Id: "b", SubmitterId: 1},
+	}
+	assert.Nil(t, importLists(db, nil, ticketList, []users.User{{Id: 1}}))
+
+	// a missing reference is searched as 0 and a reference to a missing record is kept
+	assert.Equal(t, tickets.Records(ticketList[1:]), session.Tickets().Search("assignee_id", "0"))
+	assert.Equal(t, tickets.Records(ticketList[:1]), session.Tickets().Search("assignee_id", "555"))
+	assert.Equal(t, tickets.Records(ticketList), session.Tickets().Search("organization_id", "0"))
+}
+
+func TestSessionErr(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "wordsearch.db"))
+	assert.Nil(t, err)
+	session := db.Session()
+	assert.Equal(t, 0, session.Users().Len())
+	assert.Nil(t, session.Err())
+	assert.Nil(t, db.Close())
+
+	// a failed statement is reported by the session that ran it and not by the sessions of other searches
+	failed := db.Session()
+	assert.Nil(t, failed.Tickets().Search("status", "open"))
+	assert.NotNil(t, failed.Err())
+	assert.Nil(t, db.Session().Err())
+}
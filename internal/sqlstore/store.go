@@ -0,0 +1,110 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"reflect"
+
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+)
+
+// Store the records of a table of the database, searched with SQL
+type Store struct {
+	session *Session
+	t       table
+}
+
+// Store is a store.MatchStore, the match modes are answered in SQL
+var _ store.MatchStore = Store{}
+
+// Get returns the record with the id, ok is false when there is none
+func (s Store) Get(id string) (interface{}, bool) {
+	where, args, ok := s.t.where(s.t.columns[0].field, id)
+	if !ok {
+		return nil, false
+	}
+	records := s.find(where, args...)
+	if len(records) == 0 {
+		return nil, false
+	}
+	return records[0], true
+}
+
+// Search returns the records holding exactly value for ident in load order
+func (s Store) Search(ident string, value string) []interface{} {
+	where, args, ok := s.t.where(ident, value)
+	if !ok {
+		return nil
+	}
+	return s.find(where, args...)
+}
+
+// SearchMatch returns the records whose value of ident matches value in the match mode in load order, ok is false
+// when the mode is not answered in SQL and the records are scanned instead
+func (s Store) SearchMatch(ident string, mode string, value string) ([]interface{}, bool) {
+	where, args, ok := s.t.whereMatch(ident, mode, value)
+	if !ok {
+		return nil, false
+	}
+	return s.find(where, args...), true
+}
+
+// SearchText returns the records holding every query word in the text fields ranked by relevance
+func (s Store) SearchText(query string, fields ...string) []interface{} {
+	positionList, err := s.session.db.searchText(s.t, query, fields)
+	if err != nil {
+		s.session.fail(err)
+		return nil
+	}
+	var found []interface{}
+	var records []interface{}
+	for _, i := range ranked(positionList, func(where string, args []interface{}) int {
+		first := len(found)
+		found = append(found, s.find(where, args...)...)
+		return first
+	}) {
+		records = append(records, found[i])
+	}
+	return records
+}
+
+// Len returns the number of records
+func (s Store) Len() int {
+	n, err := s.session.db.count(s.t)
+	if err != nil {
+		s.session.fail(err)
+	}
+	return n
+}
+
+// Each calls fn with the records from position start up to end in load order
+func (s Store) Each(start int, end int, fn func(interface{})) {
+	for _, rec := range s.find(`"position" >= ? AND "position" < ?`, start, end) {
+		fn(rec)
+	}
+}
+
+// find returns the records of the rows matching where in load order
+func (s Store) find(where string, args ...interface{}) []interface{} {
+	var rows []reflect.Value
+	keys := make(map[string]int)
+	err := s.session.db.fetch(s.t, where, args, func(r *sql.Rows) error {
+		record := reflect.New(s.t.fields.Type())
+		if err := s.t.scan(r, record.Interface()); err != nil {
+			return err
+		}
+		keys[s.t.fields.Values(record.Interface(), s.t.columns[0].field)[0]] = len(rows)
+		rows = append(rows, record)
+		return nil
+	}, func(key string, field string, value string) {
+		s.t.appendList(rows[keys[key]].Interface(), field, value)
+	})
+	if err != nil {
+		s.session.fail(err)
+		return nil
+	}
+	var records []interface{}
+	for _, record := range rows {
+		records = append(records, record.Elem().Interface())
+	}
+	return records
+}
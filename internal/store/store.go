@@ -0,0 +1,114 @@
+package store
+
+import (
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/fulltext"
+)
+
+// IdField the field identifying a record, every entity has one
+const IdField = "_id"
+
+// Store is the source of the records of an entity searched by the search package. A record is a value of the
+// registry of the entity: its struct for the built in entities, a record map for the schema entities.
+// BuildIndex holds the records in memory, other stores such as a database or a test mock only need to implement Store
+type Store interface {
+	// Get returns the record with the id, ok is false when there is none
+	Get(id string) (interface{}, bool)
+	// Search returns the records holding exactly value for ident in load order
+	Search(ident string, value string) []interface{}
+	// SearchText returns the records holding every query word in the text fields ranked by relevance, all text
+	// fields when none are given
+	SearchText(query string, fields ...string) []interface{}
+	// Len returns the number of records
+	Len() int
+	// Each calls fn with the records from position start up to end in load order, scans split a store into chunks
+	Each(start int, end int, fn func(interface{}))
+}
+
+// MatchStore is a Store answering the match modes other than exact with its own search rather than being scanned,
+// as a database does. ok is false when it cannot answer the mode for the field, the store is then scanned
+type MatchStore interface {
+	Store
+	// SearchMatch returns the records whose value of ident matches value in the match mode in load order
+	SearchMatch(ident string, mode string, value string) (records []interface{}, ok bool)
+}
+
+// Index is the in-memory Store
+var _ Store = (*Index)(nil)
+
+// Index holds the position of every record keyed by searchable field and value
+type Index struct {
+	records []interface{}
+	values  map[string]map[string][]int
+	text    *fulltext.Index
+}
+
+// BuildIndex builds an index over every searchable field of the registry, built once after the records are loaded
+func BuildIndex(registry *fields.Registry, records []interface{}) *Index {
+	idents := registry.Names()
+	textFields := registry.FullTextNames()
+	idx := &Index{
+		records: records,
+		values:  make(map[string]map[string][]int, len(idents)),
+		text:    fulltext.NewIndex(),
+	}
+	for _, ident := range idents {
+		idx.values[ident] = make(map[string][]int)
+	}
+	for i, rec := range records {
+		for _, ident := range idents {
+			values := idx.values[ident]
+			for _, value := range registry.Values(rec, ident) {
+				positions := values[value]
+				if len(positions) > 0 && positions[len(positions)-1] == i {
+					// value repeated within the same record e.g. duplicate tags
+					continue
+				}
+				values[value] = append(positions, i)
+			}
+		}
+		for _, ident := range textFields {
+			for _, value := range registry.Values(rec, ident) {
+				idx.text.Add(i, ident, value)
+			}
+		}
+	}
+	return idx
+}
+
+// Get returns the record with the id, ok is false when there is none
+func (idx *Index) Get(id string) (interface{}, bool) {
+	positions := idx.values[IdField][id]
+	if len(positions) == 0 {
+		return nil, false
+	}
+	return idx.records[positions[0]], true
+}
+
+// Search return slice of records that exactly match provided ident and value
+func (idx *Index) Search(ident string, value string) (records []interface{}) {
+	for _, i := range idx.values[ident][value] {
+		records = append(records, idx.records[i])
+	}
+	return
+}
+
+// SearchText return slice of records holding every query word in the text fields ranked by relevance, all text fields when none are given
+func (idx *Index) SearchText(query string, fields ...string) (records []interface{}) {
+	for _, hit := range idx.text.Search(query, fields...) {
+		records = append(records, idx.records[hit.Doc])
+	}
+	return
+}
+
+// Len returns the number of records
+func (idx *Index) Len() int {
+	return len(idx.records)
+}
+
+// Each calls fn with the records from position start up to end in load order
+func (idx *Index) Each(start int, end int, fn func(interface{})) {
+	for _, rec := range idx.records[start:end] {
+		fn(rec)
+	}
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/stretchr/testify/assert"
+)
+
+// record a record of every kind of field
+type record struct {
+	Id      int      `json:"_id"`
+	Name    string   `json:"name"`
+	Details string   `json:"details" search:"text"`
+	Active  bool     `json:"active"`
+	Tags    []string `json:"tags"`
+}
+
+func TestIndex(t *testing.T) {
+	records := []interface{}{
+		record{Id: 1, Name: "Enthaze", Details: "MegaCorp", Active: true, Tags: []string{"Fulton", "Fulton"}},
+		record{Id: 2, Name: "Nutralab", Details: "Non profit", Tags: []string{"Fulton", "Cherry"}},
+		record{Id: 3, Name: "Zentix", Details: "Artisan MegaCorp corp", Active: true},
+	}
+	var idx Store = BuildIndex(fields.New(record{}), records)
+
+	found, ok := idx.Get("2")
+	assert.True(t, ok)
+	assert.Equal(t, records[1], found)
+	_, ok = idx.Get("9")
+	assert.False(t, ok)
+
+	assert.Equal(t, records[1:2], idx.Search("name", "Nutralab"))
+	assert.Equal(t, []interface{}{records[0], records[2]}, idx.Search("active", "true"))
+	// a value repeated within a record finds it once
+	assert.Equal(t, records[:2], idx.Search("tags", "Fulton"))
+	assert.Nil(t, idx.Search("name", "nutralab"))
+	assert.Nil(t, idx.Search("unknown", "1"))
+
+	assert.Equal(t, []interface{}{records[0], records[2]}, idx.SearchText("megacorp"))
+	assert.Equal(t, records[2:], idx.SearchText("megacorp artisan", "details"))
+	assert.Nil(t, idx.SearchText("missing"))
+
+	assert.Equal(t, 3, idx.Len())
+	var each []interface{}
+	idx.Each(1, 3, func(rec interface{}) {
+		each = append(each, rec)
+	})
+	assert.Equal(t, records[1:], each)
+}
+
+func TestIndexRecordMaps(t *testing.T) {
+	registry := fields.Define([]fields.Field{{Name: "_id", Kind: fields.Int}, {Name: "title", Kind: fields.Text, FullText: true}})
+	records := []interface{}{
+		map[string]interface{}{"_id": 10, "title": "Close and thank"},
+		map[string]interface{}{"_id": 11, "title": "Ask for a refund reason"},
+	}
+	idx := BuildIndex(registry, records)
+
+	found, ok := idx.Get("11")
+	assert.True(t, ok)
+	assert.Equal(t, records[1], found)
+	assert.Equal(t, records[:1], idx.Search("title", "Close and thank"))
+	assert.Equal(t, records[1:], idx.SearchText("refunds"))
+}
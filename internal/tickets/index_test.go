@@ -0,0 +1,139 @@
+package tickets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexSearch(t *testing.T) {
+	ticketList := []Ticket{
+		{Id: "436bf9b0-1147-4c0a-8439-6f79833bff5b", Status: "pending", SubmitterId: 38, OrganizationId: 116, Tags: []string{"Ohio", "Ohio"}},
+		{Id: "1a227508-9f39-427c-8f57-1b72f3fab87c", Status: "hold", SubmitterId: 71, OrganizationId: 112, Tags: []string{"Idaho", "Ohio"}},
+		{Id: "2217c7dc-7371-4401-8738-0a8a8aedc08d", Status: "hold", SubmitterId: 38, OrganizationId: 116, HasIncidents: true},
+	}
+	idx := BuildIndex(ticketList)
+
+	tests := []struct {
+		test   string
+		ident  string
+		value  string
+		result []Ticket
+	}{
+		{
+			test:   "StringIdent",
+			ident:  "_id",
+			value:  "1a227508-9f39-427c-8f57-1b72f3fab87c",
+			result: []Ticket{ticketList[1]},
+		},
+		{
+			test:   "IntIdentMultipleResults",
+			ident:  "submitter_id",
+			value:  "38",
+			result: []Ticket{ticketList[0], ticketList[2]},
+		},
+		{
+			test:   "BoolIdent",
+			ident:  "has_incidents",
+			value:  "true",
+			result: []Ticket{ticketList[2]},
+		},
+		{
+			test:   "TagsDuplicateTagReturnedOnce",
+			ident:  "tags",
+			value:  "Ohio",
+			result: []Ticket{ticketList[0], ticketList[1]},
+		},
+		{
+			test:   "NoResult",
+			ident:  "status",
+			value:  "open",
+			result: nil,
+		},
+		{
+			test:   "InvalidIdent",
+			ident:  "invalid",
+			value:  "hold",
+			result: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		result := idx.Search(tt.ident, tt.value)
+		assert.Equal(t, Records(tt.result), result, tt.test)
+	}
+}
+
+func TestIndexSearchText(t *testing.T) {
+	ticketList := []Ticket{
+		{Id: "a", Subject: "A Catastrophe in Korea (North)", Description: "Nostrud ad sit velit cupidatat"},
+		{Id: "b", Subject: "A Catastrophe in Micronesia", Description: "Aliquip excepteur fugiat"},
+		{Id: "c", Subject: "A Drama in Portugal", Description: "Korea is not mentioned in the subject"},
+	}
+	idx := BuildIndex(ticketList)
+
+	tests := []struct {
+		test   string
+		query  string
+		fields []string
+		result []Ticket
+	}{
+		{
+			test:   "EveryWordInSubject",
+			query:  "catastrophes korea",
+			fields: []string{"subject"},
+			result: []Ticket{ticketList[0]},
+		},
+		{
+			test:   "RankedBySubject",
+			query:  "catastrophes",
+			fields: []string{"subject"},
+			result: []Ticket{ticketList[1], ticketList[0]},
+		},
+		{
+			test:   "Description",
+			query:  "korea",
+			fields: []string{"description"},
+			result: []Ticket{ticketList[2]},
+		},
+		{
+			test:   "AllTextFields",
+			query:  "korea",
+			result: []Ticket{ticketList[2], ticketList[0]},
+		},
+		{
+			test:   "NoResult",
+			query:  "hungary",
+			result: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		result := idx.SearchText(tt.query, tt.fields...)
+		assert.Equal(t, Records(tt.result), result, tt.test)
+	}
+	assert.True(t, registry.FullText("subject"))
+	assert.False(t, registry.FullText("status"))
+}
+
+func TestIndexMatchesSearchTickets(t *testing.T) {
+	tickets, err := LoadTickets("../source_data/tickets.json")
+	assert.Nil(t, err)
+	idx := BuildIndex(tickets)
+	for _, ident := range registry.Names() {
+		for _, ticket := range tickets {
+			for _, value := range FieldValues(ticket, ident) {
+				assert.Equal(t, Records(SearchTickets(tickets, ident, value)), idx.Search(ident, value))
+			}
+		}
+	}
+}
+
+func BenchmarkTicketsIndexSearch(b *testing.B) {
+	tickets, err := LoadTickets("../source_data/tickets.json")
+	assert.Nil(b, err)
+	idx := BuildIndex(tickets)
+	for i := 0; i < b.N; i++ {
+		_ = idx.Search("organization_id", "125")
+	}
+}
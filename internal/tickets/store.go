@@ -0,0 +1,33 @@
+package tickets
+
+import (
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+)
+
+// Records returns the tickets as the records of a store, in order
+func Records(tickets []Ticket) (records []interface{}) {
+	for _, ticket := range tickets {
+		records = append(records, ticket)
+	}
+	return
+}
+
+// BuildIndex builds an index over every searchable field of the tickets, built once after LoadTickets
+func BuildIndex(tickets []Ticket) *store.Index {
+	return store.BuildIndex(registry, Records(tickets))
+}
+
+// LoadStore streams the tickets file into an indexed in-memory store, opts reports progress and rejects invalid tickets.
+// An empty path loads the bundled source data
+func LoadStore(dataFilePath string, opts jsonstream.Options) (*store.Index, error) {
+	var records []interface{}
+	err := StreamTickets(dataFilePath, opts, func(ticket Ticket) error {
+		records = append(records, ticket)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store.BuildIndex(registry, records), nil
+}
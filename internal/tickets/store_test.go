@@ -0,0 +1,40 @@
+package tickets
+
+import (
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexStore(t *testing.T) {
+	ticketList := []Ticket{{Id: "a", Subject: "A Catastrophe in Korea (North)"}, {Id: "b", Subject: "A Drama in Portugal"}, {Id: "c", Subject: "A Problem in Malawi"}}
+	var idx store.Store = BuildIndex(ticketList)
+
+	found, ok := idx.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, ticketList[1], found)
+	_, ok = idx.Get("z")
+	assert.False(t, ok)
+
+	assert.Equal(t, 3, idx.Len())
+	var each []interface{}
+	idx.Each(1, 3, func(record interface{}) {
+		each = append(each, record)
+	})
+	assert.Equal(t, Records(ticketList[1:]), each)
+}
+
+func TestLoadStore(t *testing.T) {
+	records := 0
+	store, err := LoadStore("test_files/good_tickets.json", jsonstream.Options{Progress: func(p jsonstream.Progress) {
+		records = p.Records
+	}})
+	assert.Nil(t, err)
+	assert.True(t, store.Len() > 0)
+	assert.Equal(t, store.Len(), records)
+
+	_, err = LoadStore("test_files/missing.json", jsonstream.Options{})
+	assert.NotNil(t, err)
+}
@@ -2,10 +2,10 @@ package tickets
 
 import (
 	"encoding/json"
-	"io"
-	"os"
 	"path/filepath"
-	"strconv"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
 )
 
 // Ticket defines the ticket
@@ -13,140 +13,107 @@ type Ticket struct {
 	Id             string   `json:"_id"`
 	URL            string   `json:"url"`
 	ExternalId     string   `json:"external_id"`
-	CreatedAt      string   `json:"created_at"`
+	CreatedAt      string   `json:"created_at" search:"time"`
 	Type           string   `json:"type"`
-	Subject        string   `json:"subject"`
-	Description    string   `json:"description"`
+	Subject        string   `json:"subject" search:"text"`
+	Description    string   `json:"description" search:"text"`
 	Priority       string   `json:"priority"`
 	Status         string   `json:"status"`
-	SubmitterId    int      `json:"submitter_id"`
-	AssigneeId     int      `json:"assignee_id"`
-	OrganizationId int      `json:"organization_id"`
+	SubmitterId    int      `json:"submitter_id" search:"ref=users,inverse=submitted"`
+	AssigneeId     int      `json:"assignee_id" search:"ref=users,inverse=assigned"`
+	OrganizationId int      `json:"organization_id" search:"ref=organizations"`
 	Tags           []string `json:"tags"`
 	HasIncidents   bool     `json:"has_incidents"`
-	DueAt          string   `json:"due_at"`
+	DueAt          string   `json:"due_at" search:"time"`
 	Via            string   `json:"via"`
 }
 
 const ticketFilePath = "internal/source_data/tickets.json"
 
-// LoadTickets process to load the tickets datastore into a slice
-func LoadTickets(testFilePath string) ([]Ticket, error) {
-	//open the files
-	absPath, err := filepath.Abs(ticketFilePath)
-	if err != nil {
-		return nil, err
-	}
-	filePath := absPath
-	if testFilePath != "" {
-		filePath = testFilePath
-	}
-	ticketsFilePtr, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer func() (err error) {
-		// ensure we close resource
-		if rErr := ticketsFilePtr.Close(); rErr != nil {
-			return rErr
-		}
-		return nil
-	}()
+// registry the searchable fields of a ticket, read from the json and search tags of Ticket
+var registry = fields.New(Ticket{})
 
-	decoder := json.NewDecoder(ticketsFilePtr)
+// LoadTickets process to load the tickets datastore into a slice, an empty path loads the bundled source data
+func LoadTickets(dataFilePath string) ([]Ticket, error) {
 	var tickets []Ticket
-	err = decoder.Decode(&tickets)
-	if err != nil && err != io.EOF {
-		// return error when the JSON is invalid and not empty
+	err := StreamTickets(dataFilePath, jsonstream.Options{}, func(ticket Ticket) error {
+		tickets = append(tickets, ticket)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	return tickets, nil
 }
 
+// StreamTickets reads the tickets file one ticket at a time without holding the whole file in memory, calling fn
+// with every ticket, opts reports progress and rejects invalid tickets. The file holds a JSON array or one JSON ticket per
+// line and may be gzip compressed, an empty path reads the bundled source data
+func StreamTickets(dataFilePath string, opts jsonstream.Options, fn func(Ticket) error) error {
+	filePath := dataFilePath
+	if filePath == "" {
+		absPath, err := filepath.Abs(ticketFilePath)
+		if err != nil {
+			return err
+		}
+		filePath = absPath
+	}
+	return jsonstream.ReadFile(filePath, opts, func(decoder *json.Decoder) error {
+		var ticket Ticket
+		if err := decoder.Decode(&ticket); err != nil {
+			return err
+		}
+		return fn(ticket)
+	})
+}
+
 //SearchTickets return slice of tickets that match provided ident and value
-func SearchTickets(tickets []Ticket, ident string, value string) (ticketList []Ticket) {
+func SearchTickets(tickets []Ticket, ident string, value string) []Ticket {
+	return SearchTicketsMatch(tickets, ident, func(v string) bool {
+		return v == value
+	})
+}
+
+// SearchTicketsMatch return slice of tickets with a value for ident accepted by match
+func SearchTicketsMatch(tickets []Ticket, ident string, match func(string) bool) (ticketList []Ticket) {
+	if !ValidSearchTerms(ident) {
+		// Invalid ident so return
+		return
+	}
 	for _, ticket := range tickets {
-		switch ident {
-		case "_id":
-			if ticket.Id == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "url":
-			if ticket.URL == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "external_id":
-			if ticket.ExternalId == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "created_at":
-			if ticket.CreatedAt == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "type":
-			if ticket.Type == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "subject":
-			if ticket.Subject == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "description":
-			if ticket.Description == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "priority":
-			if ticket.Priority == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "status":
-			if ticket.Status == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "submitter_id":
-			if strconv.Itoa(ticket.SubmitterId) == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "assignee_id":
-			if strconv.Itoa(ticket.AssigneeId) == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "organization_id":
-			if strconv.Itoa(ticket.OrganizationId) == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "tags":
-			for _, tag := range ticket.Tags {
-				if tag == value {
-					ticketList = append(ticketList, ticket)
-				}
-			}
-		case "has_incidents":
-			if strconv.FormatBool(ticket.HasIncidents) == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "due_at":
-			if ticket.DueAt == value {
-				ticketList = append(ticketList, ticket)
-			}
-		case "via":
-			if ticket.Via == value {
-				ticketList = append(ticketList, ticket)
-			}
-		default:
-			return
+		if MatchTicket(ticket, ident, match) {
+			ticketList = append(ticketList, ticket)
 		}
 	}
 	return
 }
 
-// ValidSearchTerms checks an ident against a list of valid options and returns true if it exists
+// MatchTicket reports whether any value of the ticket for ident is accepted by match, tags are matched one by one
+func MatchTicket(ticket Ticket, ident string, match func(string) bool) bool {
+	return registry.Match(ticket, ident, match)
+}
+
+// FieldValues returns the searchable string values of a ticket for an ident
+func FieldValues(ticket Ticket, ident string) []string {
+	return registry.Values(ticket, ident)
+}
+
+// ValidSearchTerms checks an ident against the searchable fields and returns true if it exists
 func ValidSearchTerms(ident string) bool {
-	validIdents := []string{"_id", "url", "external_id", "created_at", "type", "subject", "description", "priority", "status", "submitter_id", "assignee_id", "organization_id", "tags", "has_incidents", "due_at", "via"}
-	for _, v := range validIdents {
-		if v == ident {
-			return true
-		}
-	}
-	return false
+	return registry.Has(ident)
+}
+
+// IntField reports whether ident holds whole numbers
+func IntField(ident string) bool {
+	return registry.Is(ident, fields.Int)
+}
+
+// TimeField reports whether ident holds timestamps
+func TimeField(ident string) bool {
+	return registry.Is(ident, fields.Time)
+}
+
+// Fields returns the searchable fields of a ticket
+func Fields() *fields.Registry {
+	return registry
 }
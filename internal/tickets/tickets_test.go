@@ -2,6 +2,7 @@ package tickets
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,7 +27,7 @@ func TestLoadTickets(t *testing.T) {
 		{
 			test:         "InvalidTicketFile1",
 			testFilePath: "test_files/invalid_json_tickets.json",
-			err:          errors.New("json: cannot unmarshal string into Go struct field Ticket.has_incidents of type bool"),
+			err:          errors.New("record 0 field has_incidents: json: cannot unmarshal string into Go struct field Ticket.has_incidents of type bool"),
 		},
 		{
 			test:         "GoodTicketFile",
@@ -109,6 +110,15 @@ func TestValidSearchTerms(t *testing.T) {
 	}
 }
 
+func TestFieldTypes(t *testing.T) {
+	assert.True(t, IntField("assignee_id"))
+	assert.False(t, IntField("due_at"))
+	assert.True(t, TimeField("due_at"))
+	assert.False(t, TimeField("assignee_id"))
+	assert.False(t, IntField("_id"))
+	assert.False(t, TimeField("_id"))
+}
+
 func TestSearchTickets(t *testing.T) {
 	tests := []struct {
 		test   string
@@ -597,3 +607,55 @@ func BenchmarkTicketsLoadThenSearch(b *testing.B) {
 		_ = SearchTickets(tickets, "organization_id", "125")
 	}
 }
+
+func TestSearchTicketsMatch(t *testing.T) {
+	input := []Ticket{
+		{Id: "a", Subject: "A Catastrophe in Korea (North)", Tags: []string{"Ohio", "Pennsylvania"}},
+		{Id: "b", Subject: "A Catastrophe in Micronesia", Tags: []string{"Idaho"}},
+	}
+	tests := []struct {
+		test   string
+		ident  string
+		match  func(string) bool
+		result []Ticket
+	}{
+		{
+			test:  "Prefix",
+			ident: "subject",
+			match: func(v string) bool {
+				return strings.HasPrefix(v, "A Catastrophe in Korea")
+			},
+			result: input[:1],
+		},
+		{
+			test:  "MultiValueMatchedOnce",
+			ident: "tags",
+			match: func(v string) bool {
+				return true
+			},
+			result: input,
+		},
+		{
+			test:  "MultiValueSubstring",
+			ident: "tags",
+			match: func(v string) bool {
+				return strings.Contains(strings.ToLower(v), "penn")
+			},
+			result: input[:1],
+		},
+		{
+			test:  "InvalidIdent",
+			ident: "invalid",
+			match: func(v string) bool {
+				return true
+			},
+			result: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		result := SearchTicketsMatch(input, tt.ident, tt.match)
+		assert.Equal(t, tt.result, result, tt.test)
+		assert.Equal(t, len(tt.result) > 0, MatchTicket(input[0], tt.ident, tt.match), tt.test)
+	}
+}
@@ -0,0 +1,45 @@
+package timestamp
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layout the layout of every timestamp in the source data e.g. 2016-04-28T11:19:34 -10:00
+const Layout = "2006-01-02T15:04:05 -07:00"
+
+// boundLayouts layouts accepted for range bounds without an offset, from the most to the least precise
+var boundLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02", "2006-01", "2006"}
+
+// Parse parses a timestamp from the source data keeping its offset, RFC 3339 is also accepted
+func Parse(value string) (time.Time, error) {
+	t, err := time.Parse(Layout, value)
+	if err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a timestamp like 2016-04-28T11:19:34 -10:00", value)
+}
+
+// ParseBound parses a range bound, either a full timestamp with an offset or a date and time without one
+// such as 2016-08-01 or 2014. zoned is false when the bound has no offset, the bound is then returned in UTC
+// and is meant to be compared with the local time of each record using Wall
+func ParseBound(value string) (t time.Time, zoned bool, err error) {
+	if t, err := Parse(value); err == nil {
+		return t, true, nil
+	}
+	for _, layout := range boundLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a date like 2016-08-01 or a timestamp like 2016-04-28T11:19:34 -10:00", value)
+}
+
+// Wall returns the local date and time of t as a UTC time, so records in different offsets compare on the
+// date and time written in the data
+func Wall(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,77 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse("2016-04-28T11:19:34 -10:00")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2016, 4, 28, 21, 19, 34, 0, time.UTC), parsed.UTC())
+	_, offset := parsed.Zone()
+	assert.Equal(t, -10*60*60, offset)
+
+	parsed, err = Parse("2016-04-28T11:19:34+02:00")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2016, 4, 28, 9, 19, 34, 0, time.UTC), parsed.UTC())
+
+	_, err = Parse("2016-04-28")
+	assert.EqualError(t, err, `"2016-04-28" is not a timestamp like 2016-04-28T11:19:34 -10:00`)
+	_, err = Parse("")
+	assert.NotNil(t, err)
+}
+
+func TestParseBound(t *testing.T) {
+	tests := []struct {
+		test   string
+		input  string
+		result time.Time
+		zoned  bool
+	}{
+		{
+			test:   "Timestamp",
+			input:  "2016-04-28T11:19:34 -10:00",
+			result: time.Date(2016, 4, 28, 21, 19, 34, 0, time.UTC),
+			zoned:  true,
+		},
+		{
+			test:   "DateAndTime",
+			input:  "2016-04-28T11:19:34",
+			result: time.Date(2016, 4, 28, 11, 19, 34, 0, time.UTC),
+		},
+		{
+			test:   "Date",
+			input:  "2016-08-01",
+			result: time.Date(2016, 8, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			test:   "Month",
+			input:  "2016-08",
+			result: time.Date(2016, 8, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			test:   "Year",
+			input:  "2014",
+			result: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		result, zoned, err := ParseBound(tt.input)
+		assert.Nil(t, err, tt.test)
+		assert.True(t, tt.result.Equal(result), tt.test)
+		assert.Equal(t, tt.zoned, zoned, tt.test)
+	}
+
+	_, _, err := ParseBound("yesterday")
+	assert.EqualError(t, err, `"yesterday" is not a date like 2016-08-01 or a timestamp like 2016-04-28T11:19:34 -10:00`)
+}
+
+func TestWall(t *testing.T) {
+	parsed, err := Parse("2016-07-31T20:00:00 -10:00")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2016, 7, 31, 20, 0, 0, 0, time.UTC), Wall(parsed))
+}
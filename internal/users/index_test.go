@@ -0,0 +1,102 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexSearch(t *testing.T) {
+	userList := []User{
+		{Id: 1, Name: "Francisca Rasmussen", Active: true, OrganizationId: 119, Tags: []string{"Springville", "Sutton", "Sutton"}},
+		{Id: 2, Name: "Cross Barlow", Active: true, OrganizationId: 106, Tags: []string{"Foxworth", "Sutton"}},
+		{Id: 3, Name: "Ingrid Wagner", Active: false, OrganizationId: 106},
+	}
+	idx := BuildIndex(userList)
+
+	tests := []struct {
+		test   string
+		ident  string
+		value  string
+		result []User
+	}{
+		{
+			test:   "IntIdent",
+			ident:  "_id",
+			value:  "2",
+			result: []User{userList[1]},
+		},
+		{
+			test:   "StringIdent",
+			ident:  "name",
+			value:  "Ingrid Wagner",
+			result: []User{userList[2]},
+		},
+		{
+			test:   "BoolIdentMultipleResults",
+			ident:  "active",
+			value:  "true",
+			result: []User{userList[0], userList[1]},
+		},
+		{
+			test:   "TagsDuplicateTagReturnedOnce",
+			ident:  "tags",
+			value:  "Sutton",
+			result: []User{userList[0], userList[1]},
+		},
+		{
+			test:   "EmptyValue",
+			ident:  "alias",
+			value:  "",
+			result: userList,
+		},
+		{
+			test:   "NoResult",
+			ident:  "organization_id",
+			value:  "101",
+			result: nil,
+		},
+		{
+			test:   "InvalidIdent",
+			ident:  "invalid",
+			value:  "1",
+			result: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		result := idx.Search(tt.ident, tt.value)
+		assert.Equal(t, Records(tt.result), result, tt.test)
+	}
+}
+
+func TestIndexSearchText(t *testing.T) {
+	input := []User{{Id: 1, Signature: "Don't Worry Be Happy!"}, {Id: 2, Signature: "Happiness is a warm cup"}}
+	idx := BuildIndex(input)
+	assert.Equal(t, Records([]User{input[0]}), idx.SearchText("happy", "signature"))
+	assert.Nil(t, idx.SearchText("missing"))
+	assert.True(t, registry.FullText("signature"))
+	assert.False(t, registry.FullText("_id"))
+}
+
+func TestIndexMatchesSearchUsers(t *testing.T) {
+	users, err := LoadUsers("../source_data/users.json")
+	assert.Nil(t, err)
+	idx := BuildIndex(users)
+	for _, ident := range registry.Names() {
+		for _, user := range users {
+			for _, value := range FieldValues(user, ident) {
+				assert.Equal(t, Records(SearchUsers(users, ident, value)), idx.Search(ident, value))
+			}
+		}
+	}
+}
+
+func BenchmarkUsersIndexSearch(b *testing.B) {
+	users, err := LoadUsers("../source_data/users.json")
+	assert.Nil(b, err)
+	idx := BuildIndex(users)
+	for i := 0; i < b.N; i++ {
+		_ = idx.Search("organization_id", "125")
+	}
+}
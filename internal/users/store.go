@@ -0,0 +1,33 @@
+package users
+
+import (
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+)
+
+// Records returns the users as the records of a store, in order
+func Records(users []User) (records []interface{}) {
+	for _, user := range users {
+		records = append(records, user)
+	}
+	return
+}
+
+// BuildIndex builds an index over every searchable field of the users, built once after LoadUsers
+func BuildIndex(users []User) *store.Index {
+	return store.BuildIndex(registry, Records(users))
+}
+
+// LoadStore streams the users file into an indexed in-memory store, opts reports progress and rejects invalid users.
+// An empty path loads the bundled source data
+func LoadStore(dataFilePath string, opts jsonstream.Options) (*store.Index, error) {
+	var records []interface{}
+	err := StreamUsers(dataFilePath, opts, func(user User) error {
+		records = append(records, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store.BuildIndex(registry, records), nil
+}
@@ -0,0 +1,40 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexStore(t *testing.T) {
+	userList := []User{{Id: 1, Name: "Francisca Rasmussen"}, {Id: 2, Name: "Cross Barlow"}, {Id: 3, Name: "Ingrid Wagner"}}
+	var idx store.Store = BuildIndex(userList)
+
+	found, ok := idx.Get("2")
+	assert.True(t, ok)
+	assert.Equal(t, userList[1], found)
+	_, ok = idx.Get("9")
+	assert.False(t, ok)
+
+	assert.Equal(t, 3, idx.Len())
+	var each []interface{}
+	idx.Each(1, 3, func(record interface{}) {
+		each = append(each, record)
+	})
+	assert.Equal(t, Records(userList[1:]), each)
+}
+
+func TestLoadStore(t *testing.T) {
+	records := 0
+	store, err := LoadStore("test_files/good_users.json", jsonstream.Options{Progress: func(p jsonstream.Progress) {
+		records = p.Records
+	}})
+	assert.Nil(t, err)
+	assert.True(t, store.Len() > 0)
+	assert.Equal(t, store.Len(), records)
+
+	_, err = LoadStore("test_files/missing.json", jsonstream.Options{})
+	assert.NotNil(t, err)
+}
@@ -2,10 +2,10 @@ package users
 
 import (
 	"encoding/json"
-	"io"
-	"os"
 	"path/filepath"
-	"strconv"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
 )
 
 //User defines the user
@@ -15,17 +15,17 @@ type User struct {
 	ExternalId     string   `json:"external_id"`
 	Name           string   `json:"name"`
 	Alias          string   `json:"alias"`
-	CreatedAt      string   `json:"created_at"`
+	CreatedAt      string   `json:"created_at" search:"time"`
 	Active         bool     `json:"active"`
 	Verified       bool     `json:"verified"`
 	Shared         bool     `json:"shared"`
 	Locale         string   `json:"locale"`
 	Timezone       string   `json:"timezone"`
-	LastLoginAt    string   `json:"last_login_at"`
+	LastLoginAt    string   `json:"last_login_at" search:"time"`
 	Email          string   `json:"email"`
 	Phone          string   `json:"phone"`
-	Signature      string   `json:"signature"`
-	OrganizationId int      `json:"organization_id"`
+	Signature      string   `json:"signature" search:"text"`
+	OrganizationId int      `json:"organization_id" search:"ref=organizations"`
 	Tags           []string `json:"tags"`
 	Suspended      bool     `json:"suspended"`
 	Role           string   `json:"role"`
@@ -33,135 +33,90 @@ type User struct {
 
 const usersFilePath = "internal/source_data/users.json"
 
-// LoadUsers process to load the users datastore into a slice
-func LoadUsers(testFilePath string) ([]User, error) {
-	//open the files
-	absPath, err := filepath.Abs(usersFilePath)
-	if err != nil {
-		return nil, err
-	}
-	filePath := absPath
-	if testFilePath != "" {
-		filePath = testFilePath
-	}
-	usersFilePtr, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer func() (err error) {
-		// ensure we close resource
-		if rErr := usersFilePtr.Close(); rErr != nil {
-			return rErr
-		}
-		return nil
-	}()
+// registry the searchable fields of a user, read from the json and search tags of User
+var registry = fields.New(User{})
 
-	decoder := json.NewDecoder(usersFilePtr)
+// LoadUsers process to load the users datastore into a slice, an empty path loads the bundled source data
+func LoadUsers(dataFilePath string) ([]User, error) {
 	var users []User
-	err = decoder.Decode(&users)
-	if err != nil && err != io.EOF {
-		// return error when the JSON is invalid and not empty
+	err := StreamUsers(dataFilePath, jsonstream.Options{}, func(user User) error {
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
+// StreamUsers reads the users file one user at a time without holding the whole file in memory, calling fn
+// with every user, opts reports progress and rejects invalid users. The file holds a JSON array or one JSON user per
+// line and may be gzip compressed, an empty path reads the bundled source data
+func StreamUsers(dataFilePath string, opts jsonstream.Options, fn func(User) error) error {
+	filePath := dataFilePath
+	if filePath == "" {
+		absPath, err := filepath.Abs(usersFilePath)
+		if err != nil {
+			return err
+		}
+		filePath = absPath
+	}
+	return jsonstream.ReadFile(filePath, opts, func(decoder *json.Decoder) error {
+		var user User
+		if err := decoder.Decode(&user); err != nil {
+			return err
+		}
+		return fn(user)
+	})
+}
+
 //SearchUsers return slice of users that match provided ident and value
-func SearchUsers(users []User, ident string, value string) (userList []User) {
+func SearchUsers(users []User, ident string, value string) []User {
+	return SearchUsersMatch(users, ident, func(v string) bool {
+		return v == value
+	})
+}
+
+// SearchUsersMatch return slice of users with a value for ident accepted by match
+func SearchUsersMatch(users []User, ident string, match func(string) bool) (userList []User) {
+	if !ValidSearchTerms(ident) {
+		// Invalid ident so return
+		return
+	}
 	for _, user := range users {
-		switch ident {
-		case "_id":
-			if strconv.Itoa(user.Id) == value {
-				userList = append(userList, user)
-			}
-		case "url":
-			if user.URL == value {
-				userList = append(userList, user)
-			}
-		case "external_id":
-			if user.ExternalId == value {
-				userList = append(userList, user)
-			}
-		case "name":
-			if user.Name == value {
-				userList = append(userList, user)
-			}
-		case "alias":
-			if user.Alias == value {
-				userList = append(userList, user)
-			}
-		case "created_at":
-			if user.CreatedAt == value {
-				userList = append(userList, user)
-			}
-		case "active":
-			if strconv.FormatBool(user.Active) == value {
-				userList = append(userList, user)
-			}
-		case "verified":
-			if strconv.FormatBool(user.Verified) == value {
-				userList = append(userList, user)
-			}
-		case "shared":
-			if strconv.FormatBool(user.Shared) == value {
-				userList = append(userList, user)
-			}
-		case "locale":
-			if user.Locale == value {
-				userList = append(userList, user)
-			}
-		case "timezone":
-			if user.Timezone == value {
-				userList = append(userList, user)
-			}
-		case "last_login_at":
-			if user.LastLoginAt == value {
-				userList = append(userList, user)
-			}
-		case "email":
-			if user.Email == value {
-				userList = append(userList, user)
-			}
-		case "phone":
-			if user.Phone == value {
-				userList = append(userList, user)
-			}
-		case "signature":
-			if user.Signature == value {
-				userList = append(userList, user)
-			}
-		case "organization_id":
-			if strconv.Itoa(user.OrganizationId) == value {
-				userList = append(userList, user)
-			}
-		case "tags":
-			for _, tag := range user.Tags {
-				if tag == value {
-					userList = append(userList, user)
-				}
-			}
-		case "suspended":
-			if strconv.FormatBool(user.Suspended) == value {
-				userList = append(userList, user)
-			}
-		case "role":
-			if user.Role == value {
-				userList = append(userList, user)
-			}
-		default:
-			return
+		if MatchUser(user, ident, match) {
+			userList = append(userList, user)
 		}
 	}
 	return
 }
 
-// ValidSearchTerms checks an ident against a list of valid options and returns true if it exists
+// MatchUser reports whether any value of the user for ident is accepted by match, tags are matched one by one
+func MatchUser(user User, ident string, match func(string) bool) bool {
+	return registry.Match(user, ident, match)
+}
+
+// FieldValues returns the searchable string values of a user for an ident
+func FieldValues(user User, ident string) []string {
+	return registry.Values(user, ident)
+}
+
+// ValidSearchTerms checks an ident against the searchable fields and returns true if it exists
 func ValidSearchTerms(ident string) bool {
-	validIdents := []string{"_id", "url", "external_id", "name", "alias", "created_at", "active", "verified", "shared", "locale", "timezone", "last_login_at", "email", "phone", "signature", "organization_id", "tags", "suspended", "role"}
-	for _, v := range validIdents {
-		if v == ident {
-			return true
-		}
-	}
-	return false
+	return registry.Has(ident)
+}
+
+// IntField reports whether ident holds whole numbers
+func IntField(ident string) bool {
+	return registry.Is(ident, fields.Int)
+}
+
+// TimeField reports whether ident holds timestamps
+func TimeField(ident string) bool {
+	return registry.Is(ident, fields.Time)
+}
+
+// Fields returns the searchable fields of a user
+func Fields() *fields.Registry {
+	return registry
 }
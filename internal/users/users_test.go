@@ -2,6 +2,7 @@ package users
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,7 +27,7 @@ func TestLoadUsers(t *testing.T) {
 		{
 			test:         "InvalidUsersFile1",
 			testFilePath: "test_files/invalid_json_Users.json",
-			err:          errors.New("json: cannot unmarshal string into Go struct field User.shared of type bool"),
+			err:          errors.New("record 0 field shared: json: cannot unmarshal string into Go struct field User.shared of type bool"),
 		},
 		{
 			test:         "GoodUsersFile",
@@ -115,6 +116,15 @@ func TestValidSearchTerms(t *testing.T) {
 	}
 }
 
+func TestFieldTypes(t *testing.T) {
+	assert.True(t, IntField("organization_id"))
+	assert.False(t, IntField("last_login_at"))
+	assert.True(t, TimeField("last_login_at"))
+	assert.False(t, TimeField("organization_id"))
+	assert.False(t, IntField("name"))
+	assert.False(t, TimeField("name"))
+}
+
 func TestSearchUsers(t *testing.T) {
 	tests := []struct {
 		test   string
@@ -669,3 +679,55 @@ func BenchmarkUsersLoadThenSearch(b *testing.B) {
 		_ = SearchUsers(users, "organization_id", "125")
 	}
 }
+
+func TestSearchUsersMatch(t *testing.T) {
+	input := []User{
+		{Id: 1, Name: "Francisca Rasmussen", Tags: []string{"Springville", "Sutton"}},
+		{Id: 2, Name: "Cross Barlow", Tags: []string{"Foxworth"}},
+	}
+	tests := []struct {
+		test   string
+		ident  string
+		match  func(string) bool
+		result []User
+	}{
+		{
+			test:  "Prefix",
+			ident: "name",
+			match: func(v string) bool {
+				return strings.HasPrefix(v, "Fran")
+			},
+			result: input[:1],
+		},
+		{
+			test:  "MultiValueMatchedOnce",
+			ident: "tags",
+			match: func(v string) bool {
+				return true
+			},
+			result: input,
+		},
+		{
+			test:  "MultiValueSubstring",
+			ident: "tags",
+			match: func(v string) bool {
+				return strings.Contains(strings.ToLower(v), "sut")
+			},
+			result: input[:1],
+		},
+		{
+			test:  "InvalidIdent",
+			ident: "invalid",
+			match: func(v string) bool {
+				return true
+			},
+			result: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		result := SearchUsersMatch(input, tt.ident, tt.match)
+		assert.Equal(t, tt.result, result, tt.test)
+		assert.Equal(t, len(tt.result) > 0, MatchUser(input[0], tt.ident, tt.match), tt.test)
+	}
+}
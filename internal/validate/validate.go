@@ -0,0 +1,229 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nicholas-boyson/wordsearch/internal/fields"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/timestamp"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+)
+
+// the checks a problem is found by
+const (
+	CheckInvalidRecord    = "invalid_record"
+	CheckDuplicateId      = "duplicate_id"
+	CheckMissingReference = "missing_reference"
+	CheckInvalidDate      = "invalid_date"
+	CheckSharedDomain     = "shared_domain"
+)
+
+// how serious a problem is, only errors fail a validation
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// entity names as used in the data file names and the references between entities
+const (
+	entityOrganizations = "organizations"
+	entityTickets       = "tickets"
+	entityUsers         = "users"
+)
+
+// recordNames the name of a single record of each entity
+var recordNames = map[string]string{
+	entityOrganizations: "organization",
+	entityTickets:       "ticket",
+	entityUsers:         "user",
+}
+
+// Problem a data quality problem of a record, Id is empty when the problem is not about a single record
+type Problem struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Entity   string `json:"entity"`
+	Id       string `json:"_id,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Report the problems found in the data and the number of records checked
+type Report struct {
+	Organizations int       `json:"organizations"`
+	Tickets       int       `json:"tickets"`
+	Users         int       `json:"users"`
+	Errors        int       `json:"errors"`
+	Warnings      int       `json:"warnings"`
+	Problems      []Problem `json:"problems"`
+}
+
+// add adds a problem to the report, counting it by severity
+func (r *Report) add(p Problem) {
+	if p.Severity == SeverityWarning {
+		r.Warnings++
+	} else {
+		r.Errors++
+	}
+	r.Problems = append(r.Problems, p)
+}
+
+// Failed reports whether any error was found, warnings alone do not fail a validation
+func (r *Report) Failed() bool {
+	return r.Errors > 0
+}
+
+// Reject returns a func adding the records of the entity that could not be read to the report, to be used as the
+// Reject option when the data file is read
+func (r *Report) Reject(entity string) func(jsonstream.RecordError) {
+	return func(err jsonstream.RecordError) {
+		r.add(Problem{Check: CheckInvalidRecord, Severity: SeverityError, Entity: entity, Field: err.Field, Message: err.Error()})
+	}
+}
+
+// Check checks the loaded records of every entity, adding the problems found to the report: duplicate ids,
+// references to missing records, timestamps that cannot be parsed and domains shared by organizations.
+// The references and timestamps are the fields tagged as such in the field registry of each entity, a reference
+// of 0 is no reference
+func (r *Report) Check(orgList []organizations.Organization, ticketList []tickets.Ticket, userList []users.User) {
+	r.Organizations, r.Tickets, r.Users = len(orgList), len(ticketList), len(userList)
+	ids := map[string]map[int]bool{
+		entityOrganizations: make(map[int]bool, len(orgList)),
+		entityUsers:         make(map[int]bool, len(userList)),
+	}
+	for _, org := range orgList {
+		if ids[entityOrganizations][org.Id] {
+			r.duplicateId(entityOrganizations, strconv.Itoa(org.Id))
+		}
+		ids[entityOrganizations][org.Id] = true
+	}
+	for _, user := range userList {
+		if ids[entityUsers][user.Id] {
+			r.duplicateId(entityUsers, strconv.Itoa(user.Id))
+		}
+		ids[entityUsers][user.Id] = true
+	}
+
+	for _, org := range orgList {
+		r.checkFields(entityOrganizations, strconv.Itoa(org.Id), organizations.Fields(), org, ids)
+	}
+	r.checkDomains(orgList)
+	for _, user := range userList {
+		r.checkFields(entityUsers, strconv.Itoa(user.Id), users.Fields(), user, ids)
+	}
+	ticketIds := make(map[string]bool, len(ticketList))
+	for _, ticket := range ticketList {
+		if ticketIds[ticket.Id] {
+			r.duplicateId(entityTickets, ticket.Id)
+		}
+		ticketIds[ticket.Id] = true
+		r.checkFields(entityTickets, ticket.Id, tickets.Fields(), ticket, ids)
+	}
+}
+
+// checkFields adds the references of a record to missing records and its timestamps that cannot be parsed,
+// ids holds the ids of the records of each entity referenced
+func (r *Report) checkFields(entity string, id string, registry *fields.Registry, record interface{}, ids map[string]map[int]bool) {
+	for _, f := range registry.Fields() {
+		switch {
+		case f.Ref != "":
+			for _, value := range registry.Values(record, f.Name) {
+				r.checkReference(entity, id, f.Name, value, ids[f.Ref], recordNames[f.Ref])
+			}
+		case f.Kind == fields.Time:
+			r.checkDates(entity, id, f.Name, registry.Values(record, f.Name))
+		}
+	}
+}
+
+// duplicateId adds a record sharing its id with an earlier record
+func (r *Report) duplicateId(entity string, id string) {
+	r.add(Problem{Check: CheckDuplicateId, Severity: SeverityError, Entity: entity, Id: id, Field: "_id", Value: id,
+		Message: fmt.Sprintf("_id %s is used by an earlier record", id)})
+}
+
+// checkReference adds a reference to a record missing from ids, named target
+func (r *Report) checkReference(entity string, id string, field string, value string, ids map[int]bool, target string) {
+	ref, err := strconv.Atoi(value)
+	if err != nil || ref == 0 || ids[ref] {
+		return
+	}
+	r.add(Problem{Check: CheckMissingReference, Severity: SeverityError, Entity: entity, Id: id, Field: field, Value: value,
+		Message: fmt.Sprintf("%s %d refers to no %s", field, ref, target)})
+}
+
+// checkDates adds the values of a timestamp field that cannot be parsed, an empty value is no timestamp
+func (r *Report) checkDates(entity string, id string, field string, values []string) {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if _, err := timestamp.Parse(value); err != nil {
+			r.add(Problem{Check: CheckInvalidDate, Severity: SeverityError, Entity: entity, Id: id, Field: field, Value: value,
+				Message: fmt.Sprintf("%s %s", field, err)})
+		}
+	}
+}
+
+// checkDomains adds the domain names held by more than one organization, in the order the domains are first found.
+// An organization listing a domain more than once is counted once for it
+func (r *Report) checkDomains(orgList []organizations.Organization) {
+	var domains []string
+	orgsByDomain := make(map[string][]string)
+	held := make(map[string]map[string]bool)
+	for _, org := range orgList {
+		id := strconv.Itoa(org.Id)
+		for _, domain := range org.DomainNames {
+			key := strings.ToLower(domain)
+			if _, ok := held[key]; !ok {
+				domains = append(domains, key)
+				held[key] = make(map[string]bool)
+			}
+			if !held[key][id] {
+				held[key][id] = true
+				orgsByDomain[key] = append(orgsByDomain[key], id)
+			}
+		}
+	}
+	for _, domain := range domains {
+		if ids := orgsByDomain[domain]; len(ids) > 1 {
+			r.add(Problem{Check: CheckSharedDomain, Severity: SeverityWarning, Entity: entityOrganizations, Field: "domain_names", Value: domain,
+				Message: fmt.Sprintf("domain_names %s is shared by organizations %s", domain, strings.Join(ids, ", "))})
+		}
+	}
+}
+
+// WriteText writes the problems one per line followed by a summary
+func (r *Report) WriteText(w io.Writer) error {
+	for _, p := range r.Problems {
+		record := p.Entity
+		if p.Id != "" {
+			record += " " + p.Id
+		}
+		if _, err := fmt.Fprintf(w, "%-7s %s: %s\n", p.Severity, record, p.Message); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "Checked %d organizations, %d tickets and %d users: %d errors, %d warnings\n",
+		r.Organizations, r.Tickets, r.Users, r.Errors, r.Warnings)
+	return err
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	report := *r
+	if report.Problems == nil {
+		// no problems is written as an empty list rather than null
+		report.Problems = []Problem{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
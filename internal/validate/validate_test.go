@@ -0,0 +1,110 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	orgList := []organizations.Organization{
+		{Id: 101, DomainNames: []string{"kage.com", "ecratic.com"}, CreatedAt: "2016-05-21T11:10:28 -10:00"},
+		{Id: 102, DomainNames: []string{"Kage.com"}, CreatedAt: "yesterday"},
+		{Id: 101},
+	}
+	userList := []users.User{
+		{Id: 1, OrganizationId: 101, CreatedAt: "2016-04-15T05:19:46 -10:00", LastLoginAt: "2013-08-04T01:03:27Z"},
+		{Id: 2, OrganizationId: 999},
+		{Id: 3},
+	}
+	ticketList := []tickets.Ticket{
+		{Id: "a", OrganizationId: 101, SubmitterId: 1, AssigneeId: 2, DueAt: "2016-08-15T05:37:32 -10:00"},
+		{Id: "b", OrganizationId: 555, SubmitterId: 555, AssigneeId: 0, DueAt: "2016-13-01"},
+		{Id: "a"},
+	}
+
+	var report Report
+	report.Check(orgList, ticketList, userList)
+	assert.Equal(t, []Problem{
+		{Check: CheckDuplicateId, Severity: SeverityError, Entity: "organizations", Id: "101", Field: "_id", Value: "101",
+			Message: "_id 101 is used by an earlier record"},
+		{Check: CheckInvalidDate, Severity: SeverityError, Entity: "organizations", Id: "102", Field: "created_at", Value: "yesterday",
+			Message: `created_at "yesterday" is not a timestamp like 2016-04-28T11:19:34 -10:00`},
+		{Check: CheckSharedDomain, Severity: SeverityWarning, Entity: "organizations", Field: "domain_names", Value: "kage.com",
+			Message: "domain_names kage.com is shared by organizations 101, 102"},
+		{Check: CheckMissingReference, Severity: SeverityError, Entity: "users", Id: "2", Field: "organization_id", Value: "999",
+			Message: "organization_id 999 refers to no organization"},
+		{Check: CheckMissingReference, Severity: SeverityError, Entity: "tickets", Id: "b", Field: "submitter_id", Value: "555",
+			Message: "submitter_id 555 refers to no user"},
+		{Check: CheckMissingReference, Severity: SeverityError, Entity: "tickets", Id: "b", Field: "organization_id", Value: "555",
+			Message: "organization_id 555 refers to no organization"},
+		{Check: CheckInvalidDate, Severity: SeverityError, Entity: "tickets", Id: "b", Field: "due_at", Value: "2016-13-01",
+			Message: `due_at "2016-13-01" is not a timestamp like 2016-04-28T11:19:34 -10:00`},
+		{Check: CheckDuplicateId, Severity: SeverityError, Entity: "tickets", Id: "a", Field: "_id", Value: "a",
+			Message: "_id a is used by an earlier record"},
+	}, report.Problems)
+	assert.Equal(t, 7, report.Errors)
+	assert.Equal(t, 1, report.Warnings)
+	assert.Equal(t, []int{3, 3, 3}, []int{report.Organizations, report.Tickets, report.Users})
+	assert.True(t, report.Failed())
+}
+
+func TestCheckWarningsOnly(t *testing.T) {
+	var report Report
+	report.Check([]organizations.Organization{{Id: 1, DomainNames: []string{"a.com"}}, {Id: 2, DomainNames: []string{"a.com"}}}, nil, nil)
+	assert.Equal(t, 1, report.Warnings)
+	assert.False(t, report.Failed())
+}
+
+func TestCheckDomainListedTwice(t *testing.T) {
+	var report Report
+	report.Check([]organizations.Organization{
+		{Id: 101, DomainNames: []string{"kage.com", "Kage.com"}},
+		{Id: 101, DomainNames: []string{"kage.com"}},
+		{Id: 102, DomainNames: []string{"ecratic.com", "ecratic.com", "kage.com"}},
+	}, nil, nil)
+
+	// an organization listing a domain twice does not share it with itself
+	var shared []string
+	for _, p := range report.Problems {
+		if p.Check == CheckSharedDomain {
+			shared = append(shared, p.Message)
+		}
+	}
+	assert.Equal(t, []string{"domain_names kage.com is shared by organizations 101, 102"}, shared)
+}
+
+func TestReject(t *testing.T) {
+	var report Report
+	report.Reject("users")(jsonstream.RecordError{Index: 4, Field: "shared", Err: errors.New("invalid")})
+	assert.Equal(t, []Problem{{Check: CheckInvalidRecord, Severity: SeverityError, Entity: "users", Field: "shared",
+		Message: "record 4 field shared: invalid"}}, report.Problems)
+	assert.True(t, report.Failed())
+}
+
+func TestWrite(t *testing.T) {
+	var report Report
+	report.Check(nil, []tickets.Ticket{{Id: "a", AssigneeId: 5}}, nil)
+
+	var buf bytes.Buffer
+	assert.Nil(t, report.WriteText(&buf))
+	assert.Equal(t, "error   tickets a: assignee_id 5 refers to no user\nChecked 0 organizations, 1 tickets and 0 users: 1 errors, 0 warnings\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, report.WriteJSON(&buf))
+	var decoded Report
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report, decoded)
+
+	// a report without problems holds an empty list
+	buf.Reset()
+	assert.Nil(t, (&Report{}).WriteJSON(&buf))
+	assert.Contains(t, buf.String(), `"problems": []`)
+}
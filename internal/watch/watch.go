@@ -0,0 +1,102 @@
+package watch
+
+import (
+	"os"
+	"time"
+)
+
+// stamp what a file looked like when it was last polled, a missing file has the zero stamp
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher polls files for changes, calling changed once a change has settled
+type Watcher struct {
+	paths    []string
+	interval time.Duration
+	changed  func() error
+	stat     func(string) (os.FileInfo, error)
+	last     []stamp
+	pending  []stamp
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New returns a watcher polling the files every interval once started, the files are compared with how they look now.
+// When changed fails the change is not taken as seen and changed is called again at the next poll
+func New(paths []string, interval time.Duration, changed func() error) *Watcher {
+	w := &Watcher{
+		paths:    paths,
+		interval: interval,
+		changed:  changed,
+		stat:     os.Stat,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	w.last = w.stamps()
+	return w
+}
+
+// Start polls the files in the background until Stop is called
+func (w *Watcher) Start() {
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.Poll()
+			}
+		}
+	}()
+}
+
+// Stop stops polling, waiting for a reload in progress to finish
+func (w *Watcher) Stop() {
+	close(w.stop)
+	<-w.done
+}
+
+// Poll checks the files once, calling changed when a file has changed and looks the same as at the previous poll,
+// so a file still being written is not read until the writes stop. The files are only taken as seen once changed
+// succeeds, a failed change such as a half written file is retried at the next poll
+func (w *Watcher) Poll() {
+	current := w.stamps()
+	if equal(current, w.last) {
+		w.pending = nil
+		return
+	}
+	if w.pending == nil || !equal(current, w.pending) {
+		w.pending = current
+		return
+	}
+	if err := w.changed(); err != nil {
+		// the files have settled, so the next poll calls changed again unless they change meanwhile
+		return
+	}
+	w.last, w.pending = current, nil
+}
+
+// stamps returns how each file looks now
+func (w *Watcher) stamps() []stamp {
+	stamps := make([]stamp, len(w.paths))
+	for i, path := range w.paths {
+		if info, err := w.stat(path); err == nil {
+			stamps[i] = stamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+func equal(a []stamp, b []stamp) bool {
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package watch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoll(t *testing.T) {
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.json")
+	tickets := filepath.Join(dir, "tickets.json")
+	assert.Nil(t, os.WriteFile(users, []byte("[]"), 0644))
+	changes := 0
+	w := New([]string{users, tickets}, time.Second, func() error {
+		changes++
+		return nil
+	})
+
+	w.Poll()
+	assert.Equal(t, 0, changes)
+
+	// a change is reported once the file has looked the same for a poll
+	assert.Nil(t, os.WriteFile(users, []byte("[{}]"), 0644))
+	w.Poll()
+	assert.Equal(t, 0, changes)
+	assert.Nil(t, os.WriteFile(users, []byte("[{}, {}]"), 0644))
+	w.Poll()
+	assert.Equal(t, 0, changes)
+	w.Poll()
+	assert.Equal(t, 1, changes)
+	w.Poll()
+	assert.Equal(t, 1, changes)
+
+	// a file appearing or its modification time changing is a change
+	assert.Nil(t, os.WriteFile(tickets, []byte("[]"), 0644))
+	w.Poll()
+	w.Poll()
+	assert.Equal(t, 2, changes)
+	later := time.Now().Add(time.Hour)
+	assert.Nil(t, os.Chtimes(users, later, later))
+	w.Poll()
+	w.Poll()
+	assert.Equal(t, 3, changes)
+
+	// a change undone before it settles is no change
+	assert.Nil(t, os.Remove(tickets))
+	w.Poll()
+	assert.Nil(t, os.WriteFile(tickets, []byte("[]"), 0644))
+	assert.Nil(t, os.Chtimes(tickets, w.last[1].modTime, w.last[1].modTime))
+	w.Poll()
+	w.Poll()
+	assert.Equal(t, 3, changes)
+}
+
+func TestPollRetriesFailedChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	assert.Nil(t, os.WriteFile(path, []byte("[]"), 0644))
+	calls := 0
+	failing := true
+	w := New([]string{path}, time.Second, func() error {
+		calls++
+		if failing {
+			return errors.New("unexpected end of JSON input")
+		}
+		return nil
+	})
+
+	// a half written file fails to reload and is tried again at every poll until it reloads
+	assert.Nil(t, os.WriteFile(path, []byte("[{"), 0644))
+	w.Poll()
+	w.Poll()
+	assert.Equal(t, 1, calls)
+	w.Poll()
+	assert.Equal(t, 2, calls)
+	failing = false
+	w.Poll()
+	assert.Equal(t, 3, calls)
+	w.Poll()
+	assert.Equal(t, 3, calls)
+}
+
+func TestStartStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	changed := make(chan struct{}, 1)
+	w := New([]string{path}, time.Millisecond, func() error {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	w.Start()
+	defer w.Stop()
+	assert.Nil(t, os.WriteFile(path, []byte("[]"), 0644))
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not reported")
+	}
+}
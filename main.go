@@ -2,46 +2,206 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/nicholas-boyson/wordsearch/internal/config"
 	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
 	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/query"
 	"github.com/nicholas-boyson/wordsearch/internal/search"
+	"github.com/nicholas-boyson/wordsearch/internal/sqlstore"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
 	"github.com/nicholas-boyson/wordsearch/internal/tickets"
 	"github.com/nicholas-boyson/wordsearch/internal/users"
 )
 
 const exitSearch = "quit"
 
-var orgList []organizations.Organization
-var ticketList []tickets.Ticket
-var userList []users.User
+// errQueryAll a query names the fields of one group so it cannot search every group
+var errQueryAll = errors.New("a query searches a single group, pick a group other than All")
 
-func init() {
-	// load the data on start up and hold in memory
+// exit codes for a single search run from the command line
+const (
+	exitFound    = 0
+	exitNoResult = 1
+	exitUsage    = 2
+	exitFailed   = 3 // the stores failed while searching, as a database that cannot be read
+)
+
+// outputFormat format the search results are displayed in
+var outputFormat = display.FormatText
+
+// outputFile file a single search writes its results to, empty displays the results
+var outputFile string
+
+// scanWorkers number of goroutines scanning the data in the match modes answered by a scan, 0 uses one per CPU
+var scanWorkers int
+
+// loadData loads the schema and the data on start up, replacing the data searched once it has loaded. The schema is
+// read once, a reload reads the data files of its entities again
+func loadData(cfg config.Config) error {
+	schema, err := entity.LoadSchema(cfg.Schema)
+	if err != nil {
+		return fmt.Errorf("loading schema: %s", err)
+	}
+	entity.Use(schema)
+	d, err := readData(cfg)
+	if err != nil {
+		return err
+	}
+	data.Store(d)
+	return nil
+}
+
+// readData reads the data into indexed stores held in memory, so exact match searches do not scan the data.
+// When a database is configured the data is searched in the database instead. Invalid records are skipped and
+// reported unless strictLoad is set. The entities of the schema are always read from their data files
+func readData(cfg config.Config) (*dataset, error) {
+	if cfg.Database != "" {
+		return loadDatabase(cfg)
+	}
+	d := &dataset{}
 	var err error
-	orgList, err = organizations.LoadOrganizations("")
+	orgReport := &loadReport{name: "organizations"}
+	progress := newLoadProgress(os.Stderr, orgReport.name)
+	d.organizations, err = organizations.LoadStore(cfg.OrganizationsPath(), orgReport.options(progress, strictLoad))
+	progress.done()
 	if err != nil {
-		fmt.Printf("Failed to load organizations: %s", err.Error())
-		os.Exit(1)
+		return nil, fmt.Errorf("loading organizations: %s", err)
 	}
-	ticketList, err = tickets.LoadTickets("")
+	ticketReport := &loadReport{name: "tickets"}
+	progress = newLoadProgress(os.Stderr, ticketReport.name)
+	d.tickets, err = tickets.LoadStore(cfg.TicketsPath(), ticketReport.options(progress, strictLoad))
+	progress.done()
 	if err != nil {
-		fmt.Printf("Failed to load tickets: %s", err.Error())
-		os.Exit(1)
+		return nil, fmt.Errorf("loading tickets: %s", err)
 	}
-	userList, err = users.LoadUsers("")
+	userReport := &loadReport{name: "users"}
+	progress = newLoadProgress(os.Stderr, userReport.name)
+	d.users, err = users.LoadStore(cfg.UsersPath(), userReport.options(progress, strictLoad))
+	progress.done()
 	if err != nil {
-		fmt.Printf("Failed to load users: %s", err.Error())
-		os.Exit(1)
+		return nil, fmt.Errorf("loading users: %s", err)
+	}
+	entityReports, err := readEntities(d)
+	if err != nil {
+		return nil, err
 	}
+	writeLoadReport(os.Stderr, append([]*loadReport{orgReport, ticketReport, userReport}, entityReports...)...)
+	return d, nil
+}
+
+// readEntities reads the data file of every schema entity into an indexed store of the dataset
+func readEntities(d *dataset) ([]*loadReport, error) {
+	d.entities = make(map[string]store.Store)
+	var reports []*loadReport
+	for _, t := range entity.Types() {
+		report := &loadReport{name: t.Name}
+		progress := newLoadProgress(os.Stderr, report.name)
+		store, err := t.LoadStore(report.options(progress, strictLoad))
+		progress.done()
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %s", t.Name, err)
+		}
+		d.entities[t.Name] = store
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// loadDatabase opens the SQLite database searched with SQL, importing the source data when the database is empty
+func loadDatabase(cfg config.Config) (d *dataset, err error) {
+	db, err := sqlstore.Open(cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %s", err)
+	}
+	defer func() {
+		if err != nil {
+			db.Close()
+		}
+	}()
+	empty, err := db.Empty()
+	if err != nil {
+		return nil, fmt.Errorf("reading database: %s", err)
+	}
+	if empty {
+		// the records are inserted as they are read, so the data files are never held in memory
+		err = db.Import(func(im *sqlstore.Importer) error {
+			orgReport := &loadReport{name: "organizations"}
+			progress := newLoadProgress(os.Stderr, orgReport.name)
+			err := organizations.StreamOrganizations(cfg.OrganizationsPath(), orgReport.options(progress, strictLoad), im.Organization)
+			progress.done()
+			if err != nil {
+				return fmt.Errorf("loading organizations: %s", err)
+			}
+			ticketReport := &loadReport{name: "tickets"}
+			progress = newLoadProgress(os.Stderr, ticketReport.name)
+			err = tickets.StreamTickets(cfg.TicketsPath(), ticketReport.options(progress, strictLoad), im.Ticket)
+			progress.done()
+			if err != nil {
+				return fmt.Errorf("loading tickets: %s", err)
+			}
+			userReport := &loadReport{name: "users"}
+			progress = newLoadProgress(os.Stderr, userReport.name)
+			err = users.StreamUsers(cfg.UsersPath(), userReport.options(progress, strictLoad), im.User)
+			progress.done()
+			if err != nil {
+				return fmt.Errorf("loading users: %s", err)
+			}
+			writeLoadReport(os.Stderr, orgReport, ticketReport, userReport)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("importing into database: %s", err)
+		}
+	}
+	session := db.Session()
+	d = &dataset{organizations: session.Organizations(), tickets: session.Tickets(), users: session.Users(), db: db}
+	entityReports, err := readEntities(d)
+	if err != nil {
+		return nil, err
+	}
+	writeLoadReport(os.Stderr, entityReports...)
+	return d, nil
+}
+
+// newSearch returns a search request over the data loaded last, the search keeps its stores when the data is reloaded.
+// A search of the database has a session of its own, so Err reports only the statements of that search that failed
+func newSearch() search.Search {
+	d := currentData()
+	s := search.Search{
+		Organizations: d.organizations,
+		Tickets:       d.tickets,
+		Users:         d.users,
+		Entities:      d.entities,
+		Workers:       scanWorkers,
+	}
+	if d.db != nil {
+		session := d.db.Session()
+		s.Organizations, s.Tickets, s.Users = session.Organizations(), session.Tickets(), session.Users()
+		s.StoreErr = session.Err
+	}
+	return s
 }
 
 func process(scanner *bufio.Scanner) error {
 	// Display welcome message
 	display.Welcome()
 	quit := false
+	// the last search is held so it can be exported
+	var lastGroup string
+	var lastIdent string
+	var lastResult search.SearchResult
+	var lastMatches []search.FieldMatch
+	var lastRecordMatches []search.RecordMatch
 	for !quit {
 		// While the user has not quit repeat the search
 		display.SelectSearchOptions()
@@ -53,37 +213,23 @@ func process(scanner *bufio.Scanner) error {
 		switch scanner.Text() {
 		case "1":
 			// fresh search
-			var searchRequest = search.Search{}
-			searchRequest.Organizations = orgList
-			searchRequest.Tickets = ticketList
-			searchRequest.Users = userList
-
-			knownGroup := false
-			for !knownGroup {
-				// prompt user for group to search on
-				display.SelectGroupOptions()
-				scanner.Scan()
-				if err := scanner.Err(); err != nil {
-					return fmt.Errorf("reading input: %s", err)
-				}
-				knownGroup = true
-				// repeat if group is unknown or input is quit
-				switch scanner.Text() {
-				case "1":
-					searchRequest.Group = search.SearchGroupUsers
-				case "2":
-					searchRequest.Group = search.SearchGroupTickets
-				case "3":
-					searchRequest.Group = search.SearchGroupOrganizations
-				case exitSearch:
-					// quit the search
-					quit = true
-				default:
-					knownGroup = false
-				}
+			var searchRequest = newSearch()
+
+			var err error
+			if searchRequest.Group, quit, err = selectGroup(scanner); err != nil {
+				return err
 			}
 
-			if !quit {
+			if !quit && searchRequest.Group == search.SearchGroupAll {
+				// a search across every group takes no search term
+				var ran bool
+				if lastMatches, ran, quit, err = searchAllGroups(scanner, searchRequest); err != nil {
+					return err
+				}
+				if ran {
+					lastGroup, lastIdent = search.SearchGroupAll, ""
+				}
+			} else if !quit {
 				// request user to provide search term
 				display.EnterSearchTerm()
 				scanner.Scan()
@@ -95,20 +241,57 @@ func process(scanner *bufio.Scanner) error {
 					// if the input is not quit validate search term based on search group
 					if search.ValidSearchTerms(searchRequest.Group, scanner.Text()) {
 						searchRequest.Ident = scanner.Text()
-						// prompt user to search value blank is allowed
-						display.EnterSearchValue()
+						// prompt user for the match mode blank is exact
+						display.EnterMatchMode()
 						scanner.Scan()
 						if err := scanner.Err(); err != nil {
 							return fmt.Errorf("reading input: %s", err)
 						}
 
-						if scanner.Text() != exitSearch {
-							// if the input is not quit then perform search
-							searchRequest.Value = scanner.Text()
-							searchResult := search.SearchData(searchRequest)
-							search.SearchResultDisplay(searchRequest.Group, searchResult)
-						} else {
+						if scanner.Text() == exitSearch {
 							quit = true
+						} else if !match.Valid(scanner.Text()) {
+							// inform user of the invalid match mode, take the user back to the start of the search
+							display.InvalidMatchMode()
+						} else {
+							searchRequest.Match = scanner.Text()
+							// prompt user to search value blank is allowed
+							display.EnterSearchValue()
+							scanner.Scan()
+							if err := scanner.Err(); err != nil {
+								return fmt.Errorf("reading input: %s", err)
+							}
+
+							if scanner.Text() != exitSearch {
+								// if the input is not quit then perform search
+								searchRequest.Value = scanner.Text()
+								if err := searchRequest.Compile(); err != nil {
+									// inform user of the invalid pattern, take the user back to the start of the search
+									display.InvalidSearchValue(err)
+									break
+								}
+								if searchRequest.Ident == search.WildcardField {
+									// the wildcard field searches every field and reports the fields matched
+									recordMatches := search.SearchFields(searchRequest)
+									if err := searchRequest.Err(); err != nil {
+										display.SearchFailed(err)
+										break
+									}
+									lastRecordMatches = recordMatches
+									search.RecordMatchesDisplayFormat(outputFormat, lastRecordMatches)
+								} else {
+									searchResult := search.SearchData(searchRequest)
+									if err := searchRequest.Err(); err != nil {
+										display.SearchFailed(err)
+										break
+									}
+									lastResult = searchResult
+									search.SearchResultDisplayFormat(outputFormat, searchRequest.Group, lastResult)
+								}
+								lastGroup, lastIdent = searchRequest.Group, searchRequest.Ident
+							} else {
+								quit = true
+							}
 						}
 					} else {
 						// inform user of the invalid term, take the user back to the start of the search
@@ -121,6 +304,107 @@ func process(scanner *bufio.Scanner) error {
 		case "2":
 			// display a list of searchable field to the user
 			display.ListSearchableFields()
+		case "3":
+			// export the results of the last search
+			if lastGroup == "" {
+				display.NoSearchToExport()
+				break
+			}
+			display.EnterExportFormat()
+			scanner.Scan()
+			if err := scanner.Err(); err != nil {
+				return fmt.Errorf("reading input: %s", err)
+			}
+			if scanner.Text() == exitSearch {
+				quit = true
+				break
+			}
+			format := scanner.Text()
+			if !display.ExportFormat(format) {
+				display.InvalidExportFormat()
+				break
+			}
+			// prompt user for the file, blank writes the export to the screen
+			display.EnterExportFile()
+			scanner.Scan()
+			if err := scanner.Err(); err != nil {
+				return fmt.Errorf("reading input: %s", err)
+			}
+			if scanner.Text() == exitSearch {
+				quit = true
+				break
+			}
+			var err error
+			switch {
+			case lastGroup == search.SearchGroupAll:
+				err = exportMatches(scanner.Text(), format, lastMatches)
+			case lastIdent == search.WildcardField:
+				err = exportRecordMatches(scanner.Text(), format, lastRecordMatches)
+			default:
+				err = exportResult(scanner.Text(), format, lastGroup, lastResult)
+			}
+			if err != nil {
+				display.ExportFailed(err)
+			} else if scanner.Text() != "" {
+				display.ExportComplete(scanner.Text())
+			}
+		case "4":
+			// search with a query combining fields
+			searchRequest := newSearch()
+			var err error
+			if searchRequest.Group, quit, err = selectGroup(scanner); err != nil {
+				return err
+			}
+			if quit {
+				break
+			}
+			if searchRequest.Group == search.SearchGroupAll {
+				display.InvalidQuery(errQueryAll)
+				break
+			}
+			display.EnterQuery()
+			scanner.Scan()
+			if err := scanner.Err(); err != nil {
+				return fmt.Errorf("reading input: %s", err)
+			}
+			if scanner.Text() == exitSearch {
+				quit = true
+				break
+			}
+			expr, err := parseQuery(searchRequest.Group, scanner.Text())
+			if err != nil {
+				// inform user of the invalid query, take the user back to the start of the search
+				display.InvalidQuery(err)
+				break
+			}
+			searchRequest.Query = expr
+			// prompt user for the match mode applied to every field of the query
+			display.EnterMatchMode()
+			scanner.Scan()
+			if err := scanner.Err(); err != nil {
+				return fmt.Errorf("reading input: %s", err)
+			}
+			if scanner.Text() == exitSearch {
+				quit = true
+				break
+			}
+			if !match.Valid(scanner.Text()) {
+				display.InvalidMatchMode()
+				break
+			}
+			searchRequest.Match = scanner.Text()
+			if err := searchRequest.Compile(); err != nil {
+				display.InvalidSearchValue(err)
+				break
+			}
+			searchResult := search.SearchData(searchRequest)
+			if err := searchRequest.Err(); err != nil {
+				display.SearchFailed(err)
+				break
+			}
+			search.SearchResultDisplayFormat(outputFormat, searchRequest.Group, searchResult)
+			lastGroup, lastIdent = searchRequest.Group, ""
+			lastResult = searchResult
 		case exitSearch:
 			// exit search option
 			quit = true
@@ -135,10 +419,385 @@ func process(scanner *bufio.Scanner) error {
 	return nil
 }
 
-// main function start the scanner
+// searchAllGroups prompts the user for the match mode and value of a search across every group and displays the
+// fields holding the value, ran is false when no search was run and quit is true when the user quits
+func searchAllGroups(scanner *bufio.Scanner, searchRequest search.Search) (matches []search.FieldMatch, ran bool, quit bool, err error) {
+	// prompt user for the match mode blank is exact
+	display.EnterMatchMode()
+	scanner.Scan()
+	if err := scanner.Err(); err != nil {
+		return nil, false, false, fmt.Errorf("reading input: %s", err)
+	}
+	if scanner.Text() == exitSearch {
+		return nil, false, true, nil
+	}
+	if !match.Valid(scanner.Text()) {
+		// inform user of the invalid match mode, take the user back to the start of the search
+		display.InvalidMatchMode()
+		return nil, false, false, nil
+	}
+	searchRequest.Match = scanner.Text()
+	// prompt user to search value blank is allowed
+	display.EnterSearchValue()
+	scanner.Scan()
+	if err := scanner.Err(); err != nil {
+		return nil, false, false, fmt.Errorf("reading input: %s", err)
+	}
+	if scanner.Text() == exitSearch {
+		return nil, false, true, nil
+	}
+	searchRequest.Value = scanner.Text()
+	if err := searchRequest.Compile(); err != nil {
+		display.InvalidSearchValue(err)
+		return nil, false, false, nil
+	}
+	matches = search.SearchAll(searchRequest)
+	if err := searchRequest.Err(); err != nil {
+		display.SearchFailed(err)
+		return nil, false, false, nil
+	}
+	search.FieldMatchesDisplayFormat(outputFormat, matches)
+	return matches, true, false, nil
+}
+
+// selectGroup prompts the user for the group to search until a known group is picked, quit is true when the user quits
+func selectGroup(scanner *bufio.Scanner) (group string, quit bool, err error) {
+	for {
+		// prompt user for group to search on
+		display.SelectGroupOptions()
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", false, fmt.Errorf("reading input: %s", err)
+			}
+			// input ended, nothing more to search
+			return "", true, nil
+		}
+		// repeat if group is unknown or input is quit
+		switch scanner.Text() {
+		case "0":
+			return search.SearchGroupAll, false, nil
+		case "1":
+			return search.SearchGroupUsers, false, nil
+		case "2":
+			return search.SearchGroupTickets, false, nil
+		case "3":
+			return search.SearchGroupOrganizations, false, nil
+		case exitSearch:
+			// quit the search
+			return "", true, nil
+		default:
+			// the schema entities follow the built in groups
+			types := entity.Types()
+			if n, err := strconv.Atoi(scanner.Text()); err == nil && n >= 4 && n < 4+len(types) {
+				return types[n-4].Name, false, nil
+			}
+		}
+	}
+}
+
+// groupNames lists the groups that can be searched, the schema entities follow users, tickets and organizations and
+// all searches every group
+func groupNames() string {
+	names := []string{"users", "tickets", "organizations"}
+	for _, t := range entity.Types() {
+		names = append(names, t.Name)
+	}
+	names = append(names, "all")
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// parseQuery parses a compound query and checks its fields belong to the group
+func parseQuery(group string, input string) (query.Expr, error) {
+	expr, err := query.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := search.ValidQuery(group, expr); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// searchOnce performs a single search without prompting and returns the process exit code
+func searchOnce(group string, ident string, matchMode string, value string) int {
+	searchRequest := newSearch()
+	var ok bool
+	if searchRequest.Group, ok = search.ParseGroup(group); !ok {
+		fmt.Printf("Unknown group %q, expected %s\n", group, groupNames())
+		return exitUsage
+	}
+	if searchRequest.Group == search.SearchGroupAll {
+		if ident != "" {
+			fmt.Println("-group all searches every field, leave out -field")
+			return exitUsage
+		}
+		if !match.Valid(matchMode) {
+			display.InvalidMatchMode()
+			return exitUsage
+		}
+		searchRequest.Match = matchMode
+		searchRequest.Value = value
+		return runAllOnce(searchRequest)
+	}
+	if ident == "" && matchMode == match.Text {
+		// a text search without a field ranks the words across every text field as the wildcard field does
+		ident = search.WildcardField
+	}
+	if !search.ValidSearchTerms(searchRequest.Group, ident) {
+		display.InvalidSearchTerm()
+		return exitUsage
+	}
+	if !match.Valid(matchMode) {
+		display.InvalidMatchMode()
+		return exitUsage
+	}
+	searchRequest.Ident = ident
+	searchRequest.Match = matchMode
+	searchRequest.Value = value
+	if ident == search.WildcardField {
+		return runFieldsOnce(searchRequest)
+	}
+	return runOnce(searchRequest)
+}
+
+// queryOnce performs a single compound query search without prompting and returns the process exit code
+func queryOnce(group string, input string, matchMode string) int {
+	searchRequest := newSearch()
+	var ok bool
+	if searchRequest.Group, ok = search.ParseGroup(group); !ok {
+		fmt.Printf("Unknown group %q, expected %s\n", group, groupNames())
+		return exitUsage
+	}
+	if searchRequest.Group == search.SearchGroupAll {
+		display.InvalidQuery(errQueryAll)
+		return exitUsage
+	}
+	expr, err := parseQuery(searchRequest.Group, input)
+	if err != nil {
+		display.InvalidQuery(err)
+		return exitUsage
+	}
+	if !match.Valid(matchMode) {
+		display.InvalidMatchMode()
+		return exitUsage
+	}
+	searchRequest.Query = expr
+	searchRequest.Match = matchMode
+	return runOnce(searchRequest)
+}
+
+// runOnce runs a validated search, writing or displaying the result, and returns the process exit code
+func runOnce(searchRequest search.Search) int {
+	if err := searchRequest.Compile(); err != nil {
+		display.InvalidSearchValue(err)
+		return exitUsage
+	}
+	searchResult := search.SearchData(searchRequest)
+	if err := searchRequest.Err(); err != nil {
+		display.SearchFailed(err)
+		return exitFailed
+	}
+	if outputFile != "" {
+		if err := exportResult(outputFile, outputFormat, searchRequest.Group, searchResult); err != nil {
+			display.ExportFailed(err)
+			return exitUsage
+		}
+	} else {
+		search.SearchResultDisplayFormat(outputFormat, searchRequest.Group, searchResult)
+	}
+	if searchResult.Count(searchRequest.Group) == 0 {
+		return exitNoResult
+	}
+	return exitFound
+}
+
+// runAllOnce runs a validated search across every group, writing or displaying the fields holding the value, and
+// returns the process exit code
+func runAllOnce(searchRequest search.Search) int {
+	if err := searchRequest.Compile(); err != nil {
+		display.InvalidSearchValue(err)
+		return exitUsage
+	}
+	matches := search.SearchAll(searchRequest)
+	if err := searchRequest.Err(); err != nil {
+		display.SearchFailed(err)
+		return exitFailed
+	}
+	if outputFile != "" {
+		if err := exportMatches(outputFile, outputFormat, matches); err != nil {
+			display.ExportFailed(err)
+			return exitUsage
+		}
+	} else {
+		search.FieldMatchesDisplayFormat(outputFormat, matches)
+	}
+	if len(matches) == 0 {
+		return exitNoResult
+	}
+	return exitFound
+}
+
+// runFieldsOnce runs a validated search of the wildcard field, writing or displaying the records holding the value
+// with the fields holding it, and returns the process exit code
+func runFieldsOnce(searchRequest search.Search) int {
+	if err := searchRequest.Compile(); err != nil {
+		display.InvalidSearchValue(err)
+		return exitUsage
+	}
+	matches := search.SearchFields(searchRequest)
+	if err := searchRequest.Err(); err != nil {
+		display.SearchFailed(err)
+		return exitFailed
+	}
+	if outputFile != "" {
+		if err := exportRecordMatches(outputFile, outputFormat, matches); err != nil {
+			display.ExportFailed(err)
+			return exitUsage
+		}
+	} else {
+		search.RecordMatchesDisplayFormat(outputFormat, matches)
+	}
+	if len(matches) == 0 {
+		return exitNoResult
+	}
+	return exitFound
+}
+
+// exportResult writes the search result to a file, an empty path writes to stdout
+func exportResult(path string, format string, group string, sr search.SearchResult) error {
+	return writeExport(path, func(w io.Writer) error {
+		return search.SearchResultExport(w, format, group, sr)
+	})
+}
+
+// exportMatches writes the fields holding the value of a search across every group to a file, an empty path writes to stdout
+func exportMatches(path string, format string, matches []search.FieldMatch) error {
+	return writeExport(path, func(w io.Writer) error {
+		return search.FieldMatchesExport(w, format, matches)
+	})
+}
+
+// exportRecordMatches writes the records of a wildcard search with the fields holding the value to a file, an empty
+// path writes to stdout
+func exportRecordMatches(path string, format string, matches []search.RecordMatch) error {
+	return writeExport(path, func(w io.Writer) error {
+		return search.RecordMatchesExport(w, format, matches)
+	})
+}
+
+// writeExport creates the export file and writes the export to it, an empty path writes to stdout
+func writeExport(path string, write func(io.Writer) error) (err error) {
+	if path == "" {
+		return write(os.Stdout)
+	}
+	exportFilePtr, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// ensure we close resource and report a failed flush to disk
+		if cErr := exportFilePtr.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}()
+	return write(exportFilePtr)
+}
+
+// dataFlags registers the data location flags on fs, the returned func resolves the config once fs is parsed
+func dataFlags(fs *flag.FlagSet) func() (config.Config, error) {
+	configPath := fs.String("config", "", "JSON config file with the data locations, defaults to $"+config.EnvConfig)
+	var flags config.Config
+	fs.StringVar(&flags.DataDir, "data-dir", "", "directory holding users.json, tickets.json and organizations.json, defaults to $"+config.EnvDataDir)
+	fs.StringVar(&flags.Users, "users", "", "users file or directory, defaults to $"+config.EnvUsers)
+	fs.StringVar(&flags.Tickets, "tickets", "", "tickets file or directory, defaults to $"+config.EnvTickets)
+	fs.StringVar(&flags.Organizations, "organizations", "", "organizations file or directory, defaults to $"+config.EnvOrganizations)
+	fs.StringVar(&flags.Schema, "schema", "", "JSON schema file describing more entities to search, such as groups or macros, defaults to $"+config.EnvSchema)
+	fs.StringVar(&flags.Database, "database", "", "SQLite database file to search, the data files are imported when it is empty, defaults to $"+config.EnvDatabase)
+	fs.BoolVar(&strictLoad, "strict", false, "fail to load the data at the first invalid record instead of skipping invalid records")
+	return func() (config.Config, error) {
+		return config.Resolve(flags, *configPath, os.Getenv)
+	}
+}
+
+// loadConfiguredData resolves the data config and loads the data, exiting when either fails
+func loadConfiguredData(resolveConfig func() (config.Config, error)) config.Config {
+	cfg, err := resolveConfig()
+	if err != nil {
+		fmt.Printf("Failed to read config: %s", err.Error())
+		os.Exit(1)
+	}
+	if err := loadData(cfg); err != nil {
+		fmt.Printf("Failed to load data: %s", err.Error())
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// watchFlag registers the flag reloading the data files when they change, for the commands that keep running
+func watchFlag(fs *flag.FlagSet) {
+	fs.DurationVar(&watchInterval, "watch", 0, "check the data files for changes this often and reload them e.g. 10s, 0 never reloads")
+}
+
+// workersFlag registers the flag setting the number of goroutines scanning the data
+func workersFlag(fs *flag.FlagSet) {
+	fs.IntVar(&scanWorkers, "workers", 0, "goroutines scanning the data for prefix, substring, glob, regex and range searches, 0 uses one per CPU and 1 scans serially")
+}
+
+// startWatching watches the data files when -watch is set, exiting when they cannot be watched
+func startWatching(cfg config.Config) (stop func()) {
+	stop, err := watchData(cfg)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(exitUsage)
+	}
+	return stop
+}
+
+// main function start the scanner, run a single search when a group is provided, or run a sub command
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		os.Exit(serve(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validateData(os.Args[2:]))
+	}
+
+	group := flag.String("group", "", "group to search without prompting: users, tickets, organizations, an entity of the schema or all to search every field of every group")
+	field := flag.String("field", "", "field to search on, used with -group, * searches every field of the group")
+	value := flag.String("value", "", "value to search for, used with -group")
+	queryText := flag.String("query", "", "query combining fields with AND, OR and NOT e.g. 'status:pending AND priority:high', used with -group instead of -field and -value")
+	matchMode := flag.String("match", match.Exact, "how field values are compared to -value: "+strings.Join(match.Modes, ", "))
+	flag.StringVar(&outputFormat, "format", display.FormatText, "search result format: text, json, csv or tsv")
+	flag.StringVar(&outputFile, "out", "", "file the single search results are written to, requires -format json, csv or tsv")
+	resolveConfig := dataFlags(flag.CommandLine)
+	watchFlag(flag.CommandLine)
+	workersFlag(flag.CommandLine)
+	flag.Parse()
+	if !display.ValidFormat(outputFormat) {
+		fmt.Printf("Unknown format %q, expected text, json, csv or tsv\n", outputFormat)
+		os.Exit(exitUsage)
+	}
+	if outputFile != "" && !display.ExportFormat(outputFormat) {
+		fmt.Printf("Format %q cannot be written to a file, expected json, csv or tsv\n", outputFormat)
+		os.Exit(exitUsage)
+	}
+
+	cfg := loadConfiguredData(resolveConfig)
+	if *queryText != "" {
+		if *field != "" || *value != "" {
+			fmt.Println("Use either -query or -field and -value, not both")
+			os.Exit(exitUsage)
+		}
+		os.Exit(queryOnce(*group, *queryText, *matchMode))
+	}
+	if *group != "" {
+		os.Exit(searchOnce(*group, *field, *matchMode, *value))
+	}
+
+	stop := startWatching(cfg)
 	scanner := bufio.NewScanner(os.Stdin)
 	err := process(scanner)
+	stop()
 	if err != nil {
 		fmt.Printf("Hit an input error: %s", err.Error())
 		os.Exit(1)
@@ -3,11 +3,32 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/nicholas-boyson/wordsearch/internal/config"
+	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/match"
+	"github.com/nicholas-boyson/wordsearch/internal/search"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestMain(m *testing.M) {
+	// load the bundled source data used by the searches
+	if err := loadData(config.Config{}); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
 func TestProcess(t *testing.T) {
 	tests := []struct {
 		test  string
@@ -21,6 +42,74 @@ func TestProcess(t *testing.T) {
 			test:  "ListOptionsThenQuit",
 			bytes: []byte("2\nquit\n"),
 		},
+		{
+			test:  "ExportWithoutSearchThenQuit",
+			bytes: []byte("3\nquit\n"),
+		},
+		{
+			test:  "SearchThenExportToScreen",
+			bytes: []byte("1\n1\n_id\n\n1\n3\ncsv\n\nquit\n"),
+		},
+		{
+			test:  "SearchThenInvalidExportFormat",
+			bytes: []byte("1\n1\n_id\n\n1\n3\nxml\nquit\n"),
+		},
+		{
+			test:  "SearchSubstringThenQuit",
+			bytes: []byte("1\n1\nname\nsubstring\nfrancisca\nquit\n"),
+		},
+		{
+			test:  "SearchRegexThenQuit",
+			bytes: []byte("1\n1\nphone\nregex\n^8335-\nquit\n"),
+		},
+		{
+			test:  "InvalidPatternThenQuit",
+			bytes: []byte("1\n1\nphone\nregex\n[8335\nquit\n"),
+		},
+		{
+			test:  "QueryThenExportToScreen",
+			bytes: []byte("4\n2\nstatus:pending AND (priority:high OR priority:urgent) NOT via:chat\n\n3\ncsv\n\nquit\n"),
+		},
+		{
+			test:  "InvalidQueryThenQuit",
+			bytes: []byte("4\n2\nstatus:pending AND\nquit\n"),
+		},
+		{
+			test:  "QueryUnknownFieldThenQuit",
+			bytes: []byte("4\n1\nstatus:pending\nquit\n"),
+		},
+		{
+			test:  "QueryQuitAtGroup",
+			bytes: []byte("4\nquit\n"),
+		},
+		{
+			test:  "SearchRangeThenQuit",
+			bytes: []byte("1\n2\ndue_at\nrange\nbetween 2016-07-01 and 2016-07-31\nquit\n"),
+		},
+		{
+			test:  "SearchJoinThenQuit",
+			bytes: []byte("1\n3\nusers.suspended\n\ntrue\nquit\n"),
+		},
+		{
+			test:  "InvalidMatchModeThenQuit",
+			bytes: []byte("1\n1\nname\nfuzzy\nquit\n"),
+		},
+		{
+			test:  "SearchAllThenExportToScreen",
+			bytes: []byte("1\n0\n\n101\n3\ncsv\n\nquit\n"),
+		},
+		{
+			test:  "SearchAllInvalidRangeThenQuit",
+			bytes: []byte("1\n0\nrange\nhigh\nquit\n"),
+		},
+		{
+			test:  "QueryAllThenQuit",
+			bytes: []byte("4\n0\nquit\n"),
+		},
+		{
+			test:  "SearchWildcardThenExportToScreen",
+			bytes: []byte("1\n1\n*\nsubstring\nfrancisca\n3\ncsv\n\nquit\n"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -33,3 +122,537 @@ func TestProcess(t *testing.T) {
 		assert.Nil(t, err)
 	}
 }
+
+func TestSearchOnce(t *testing.T) {
+	tests := []struct {
+		test   string
+		group  string
+		ident  string
+		mode   string
+		value  string
+		result int
+	}{
+		{
+			test:   "Found",
+			group:  "users",
+			ident:  "email",
+			value:  "coffeyrasmussen@flotonic.com",
+			result: exitFound,
+		},
+		{
+			test:   "FoundGroupIgnoresCase",
+			group:  "Organizations",
+			ident:  "_id",
+			value:  "101",
+			result: exitFound,
+		},
+		{
+			test:   "NoResult",
+			group:  "tickets",
+			ident:  "status",
+			value:  "unknown",
+			result: exitNoResult,
+		},
+		{
+			test:   "FoundSubstring",
+			group:  "users",
+			ident:  "name",
+			mode:   match.Substring,
+			value:  "francisca",
+			result: exitFound,
+		},
+		{
+			test:   "NoResultExactCase",
+			group:  "users",
+			ident:  "name",
+			mode:   match.Exact,
+			value:  "francisca rasmussen",
+			result: exitNoResult,
+		},
+		{
+			test:   "FoundRegex",
+			group:  "users",
+			ident:  "email",
+			mode:   match.Regex,
+			value:  "@(flotonic|zentix)\\.com$",
+			result: exitFound,
+		},
+		{
+			test:   "FoundRange",
+			group:  "tickets",
+			ident:  "due_at",
+			mode:   match.Range,
+			value:  "<2016-08-01",
+			result: exitFound,
+		},
+		{
+			test:   "FoundJoin",
+			group:  "tickets",
+			ident:  "assignee.role",
+			value:  "admin",
+			result: exitFound,
+		},
+		{
+			test:   "InvalidJoin",
+			group:  "tickets",
+			ident:  "assignee.status",
+			value:  "open",
+			result: exitUsage,
+		},
+		{
+			test:   "InvalidRange",
+			group:  "tickets",
+			ident:  "status",
+			mode:   match.Range,
+			value:  "<2016-08-01",
+			result: exitUsage,
+		},
+		{
+			test:   "InvalidPattern",
+			group:  "users",
+			ident:  "phone",
+			mode:   match.Regex,
+			value:  "[8335",
+			result: exitUsage,
+		},
+		{
+			test:   "InvalidMatchMode",
+			group:  "users",
+			ident:  "name",
+			mode:   "fuzzy",
+			value:  "francisca",
+			result: exitUsage,
+		},
+		{
+			test:   "UnknownGroup",
+			group:  "groups",
+			ident:  "_id",
+			value:  "1",
+			result: exitUsage,
+		},
+		{
+			test:   "InvalidField",
+			group:  "users",
+			ident:  "invalid",
+			value:  "1",
+			result: exitUsage,
+		},
+		{
+			test:   "FoundWildcard",
+			group:  "users",
+			ident:  "*",
+			value:  "coffeyrasmussen@flotonic.com",
+			result: exitFound,
+		},
+		{
+			test:   "NoResultWildcard",
+			group:  "organizations",
+			ident:  "*",
+			value:  "coffeyrasmussen@flotonic.com",
+			result: exitNoResult,
+		},
+		{
+			test:   "WildcardInvalidPattern",
+			group:  "tickets",
+			ident:  "*",
+			mode:   match.Regex,
+			value:  "[8335",
+			result: exitUsage,
+		},
+		{
+			test:   "FoundTextWithoutField",
+			group:  "tickets",
+			mode:   match.Text,
+			value:  "catastrophe nostrud",
+			result: exitFound,
+		},
+		{
+			test:   "NoResultTextOneField",
+			group:  "tickets",
+			ident:  "subject",
+			mode:   match.Text,
+			value:  "catastrophe nostrud",
+			result: exitNoResult,
+		},
+		{
+			test:   "FoundAll",
+			group:  "all",
+			value:  "Francisca Rasmussen",
+			result: exitFound,
+		},
+		{
+			test:   "NoResultAll",
+			group:  "all",
+			value:  "unknown",
+			result: exitNoResult,
+		},
+		{
+			test:   "AllWithField",
+			group:  "all",
+			ident:  "name",
+			value:  "Francisca Rasmussen",
+			result: exitUsage,
+		},
+		{
+			test:   "AllInvalidRange",
+			group:  "all",
+			mode:   match.Range,
+			value:  "high",
+			result: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		result := searchOnce(tt.group, tt.ident, tt.mode, tt.value)
+		assert.Equal(t, tt.result, result, tt.test)
+	}
+}
+
+func TestQueryOnce(t *testing.T) {
+	tests := []struct {
+		test   string
+		group  string
+		query  string
+		mode   string
+		result int
+	}{
+		{
+			test:   "Found",
+			group:  "tickets",
+			query:  "status:pending AND (priority:high OR priority:urgent) NOT via:chat",
+			result: exitFound,
+		},
+		{
+			test:   "FoundIgnoreCase",
+			group:  "users",
+			query:  "role:ADMIN name:\"francisca rasmussen\"",
+			mode:   match.IgnoreCase,
+			result: exitFound,
+		},
+		{
+			test:   "NoResult",
+			group:  "tickets",
+			query:  "status:pending NOT status:pending",
+			result: exitNoResult,
+		},
+		{
+			test:   "FoundRangeTerm",
+			group:  "users",
+			query:  "role:admin last_login_at:<2014",
+			result: exitFound,
+		},
+		{
+			test:   "FoundJoinTerm",
+			group:  "organizations",
+			query:  "users.suspended:true AND tickets.priority:urgent",
+			result: exitFound,
+		},
+		{
+			test:   "InvalidRangeTerm",
+			group:  "users",
+			query:  "role:admin last_login_at:<yesterday",
+			result: exitUsage,
+		},
+		{
+			test:   "InvalidQuery",
+			group:  "tickets",
+			query:  "(status:pending",
+			result: exitUsage,
+		},
+		{
+			test:   "UnknownField",
+			group:  "organizations",
+			query:  "status:pending",
+			result: exitUsage,
+		},
+		{
+			test:   "InvalidMatchMode",
+			group:  "tickets",
+			query:  "status:pending",
+			mode:   "fuzzy",
+			result: exitUsage,
+		},
+		{
+			test:   "UnknownGroup",
+			group:  "groups",
+			query:  "status:pending",
+			result: exitUsage,
+		},
+		{
+			test:   "AllGroups",
+			group:  "all",
+			query:  "status:pending",
+			result: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		result := queryOnce(tt.group, tt.query, tt.mode)
+		assert.Equal(t, tt.result, result, tt.test)
+	}
+}
+
+func TestExportResult(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.csv")
+	sr := search.SearchResult{Users: []users.User{{Id: 1, Name: "Francisca Rasmussen"}}}
+	err := exportResult(path, display.FormatCSV, search.SearchGroupUsers, sr)
+	assert.Nil(t, err)
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "1,,,Francisca Rasmussen,")
+
+	err = exportResult(filepath.Join(t.TempDir(), "missing", "users.csv"), display.FormatCSV, search.SearchGroupUsers, sr)
+	assert.NotNil(t, err)
+}
+
+func TestExportRecordMatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fields.csv")
+	matches := []search.RecordMatch{{Group: search.SearchGroupUsers, Fields: []string{"name", "alias"}, Result: search.SearchResult{Users: []users.User{{Id: 1, Name: "Francisca Rasmussen"}}}}}
+	assert.Nil(t, exportRecordMatches(path, display.FormatCSV, matches))
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "_id,fields\n1,name;alias\n", string(data))
+}
+
+func TestExportMatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "all.csv")
+	matches := []search.FieldMatch{{Group: search.SearchGroupUsers, Field: "name", Result: search.SearchResult{Users: []users.User{{Id: 1, Name: "Francisca Rasmussen"}}}}}
+	assert.Nil(t, exportMatches(path, display.FormatCSV, matches))
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "group,field,_id\nusers,name,1\n", string(data))
+}
+
+func TestLoadDatabase(t *testing.T) {
+	defer func() {
+		// searches in the other tests run over the source data held in memory
+		assert.Nil(t, loadData(config.Config{}))
+	}()
+	cfg := config.Config{Database: filepath.Join(t.TempDir(), "wordsearch.db")}
+	assert.Nil(t, loadData(cfg))
+	assert.Equal(t, exitFound, searchOnce("users", "_id", match.Exact, "1"))
+	assert.Equal(t, exitFound, queryOnce("tickets", "assignee.role:admin AND status:pending", match.Exact))
+
+	// the imported data is kept, an empty data directory is not read again
+	cfg.DataDir = t.TempDir()
+	assert.Nil(t, loadData(cfg))
+	assert.Equal(t, exitFound, searchOnce("organizations", "name", match.Prefix, "Ent"))
+
+	cfg.Database = filepath.Join(t.TempDir(), "other.db")
+	assert.NotNil(t, loadData(cfg))
+
+	// the failed import left the database empty, so the data files are imported once they can be read
+	cfg.DataDir = ""
+	assert.Nil(t, loadData(cfg))
+	assert.Equal(t, exitFound, searchOnce("tickets", "status", match.Exact, "pending"))
+
+	// a database that cannot be searched fails the search rather than reporting no results
+	assert.Nil(t, currentData().db.Close())
+	assert.Equal(t, exitFailed, searchOnce("users", "_id", match.Exact, "1"))
+	assert.Equal(t, exitFailed, searchOnce("all", "", match.Exact, "1"))
+	assert.Equal(t, exitFailed, searchOnce("users", "*", match.Exact, "1"))
+	assert.Equal(t, exitFailed, queryOnce("tickets", "status:pending", match.Exact))
+}
+
+func TestLoadProgress(t *testing.T) {
+	var buf bytes.Buffer
+	progress := newLoadProgress(&buf, "tickets")
+	now := progress.start
+	progress.now = func() time.Time {
+		return now
+	}
+
+	// a load finishing within progressDelay shows nothing
+	progress.report(jsonstream.Progress{Records: 10, Read: 100, Size: 400})
+	now = now.Add(progressDelay)
+	progress.report(jsonstream.Progress{Records: 20, Read: 200, Size: 400})
+	progress.report(jsonstream.Progress{Records: 30, Read: 300, Size: 400})
+	now = now.Add(progressInterval)
+	progress.report(jsonstream.Progress{Records: 40, Read: 400})
+	progress.done()
+	assert.Equal(t, "\rLoading tickets:  50% (20 records)\rLoading tickets: 40 records\n", buf.String())
+
+	buf.Reset()
+	progress = newLoadProgress(&buf, "users")
+	progress.report(jsonstream.Progress{Records: 10})
+	progress.done()
+	assert.Equal(t, "", buf.String())
+}
+
+func TestLoadLenient(t *testing.T) {
+	defer func() {
+		strictLoad = false
+		assert.Nil(t, loadData(config.Config{}))
+	}()
+	path := filepath.Join(t.TempDir(), "users.json")
+	data := "{\"_id\": 1, \"name\": \"Francisca Rasmussen\"}\n{\"_id\": 2, \"shared\": \"no\"}\n{\"_id\": 3, \"name\": \"Ingrid Wagner\"}\n{\"_id\": 4, \"name\": \n"
+	assert.Nil(t, os.WriteFile(path, []byte(data), 0644))
+	cfg := config.Config{Users: path}
+
+	// the invalid user and the line that is not valid JSON are skipped and the others are searched
+	assert.Nil(t, loadData(cfg))
+	assert.Equal(t, 2, currentData().users.Len())
+	assert.Equal(t, exitFound, searchOnce("users", "_id", match.Exact, "3"))
+	assert.Equal(t, exitNoResult, searchOnce("users", "_id", match.Exact, "2"))
+
+	strictLoad = true
+	err := loadData(cfg)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "loading users: record 1 field shared: ")
+}
+
+func TestLoadDatabaseDuplicateId(t *testing.T) {
+	defer func() {
+		strictLoad = false
+		assert.Nil(t, loadData(config.Config{}))
+	}()
+	path := filepath.Join(t.TempDir(), "users.json")
+	data := "{\"_id\": 1, \"name\": \"Francisca Rasmussen\"}\n{\"_id\": 1, \"name\": \"Cross Barlow\"}\n{\"_id\": 3, \"name\": \"Ingrid Wagner\"}\n"
+	assert.Nil(t, os.WriteFile(path, []byte(data), 0644))
+
+	// the duplicate is rejected as an invalid record, the records around it are imported
+	cfg := config.Config{Users: path, Database: filepath.Join(t.TempDir(), "wordsearch.db")}
+	assert.Nil(t, loadData(cfg))
+	assert.Equal(t, 2, currentData().users.Len())
+	assert.Equal(t, exitFound, searchOnce("users", "name", match.Exact, "Ingrid Wagner"))
+	assert.Equal(t, exitNoResult, searchOnce("users", "name", match.Exact, "Cross Barlow"))
+
+	strictLoad = true
+	cfg.Database = filepath.Join(t.TempDir(), "strict.db")
+	err := loadData(cfg)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "loading users: record 1 field _id: _id 1 is used by an earlier record")
+}
+
+func TestLoadReport(t *testing.T) {
+	var buf bytes.Buffer
+	orgReport := &loadReport{name: "organizations", loaded: 25}
+	userReport := &loadReport{name: "users", loaded: 75}
+	writeLoadReport(&buf, orgReport, userReport)
+	assert.Equal(t, "Loaded organizations: 25 loaded, 0 rejected\nLoaded users: 75 loaded, 0 rejected\n", buf.String())
+	buf.Reset()
+
+	opts := userReport.options(newLoadProgress(&buf, userReport.name), false)
+	for i := 0; i < maxReportedErrors+2; i++ {
+		opts.Reject(jsonstream.RecordError{Index: i, Field: "shared", Err: fmt.Errorf("invalid")})
+	}
+	opts.Progress(jsonstream.Progress{Records: 73, Rejected: maxReportedErrors + 2})
+	assert.Nil(t, userReport.options(newLoadProgress(&buf, userReport.name), true).Reject)
+	writeLoadReport(&buf, orgReport, userReport)
+	report := buf.String()
+	assert.True(t, strings.HasPrefix(report, "Loaded organizations: 25 loaded, 0 rejected\nLoaded users: 73 loaded, 12 rejected\n  record 0 field shared: invalid\n"), report)
+	assert.True(t, strings.HasSuffix(report, "  record 9 field shared: invalid\n  and 2 more\n"), report)
+}
+
+func TestValidateData(t *testing.T) {
+	// the bundled data refers to users and an organization that do not exist
+	report, err := checkData(config.Config{}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, report.Errors)
+	var buf bytes.Buffer
+	assert.Equal(t, exitInvalid, writeValidation(&buf, display.FormatJSON, report))
+	assert.Contains(t, buf.String(), `"check": "missing_reference"`)
+
+	path := filepath.Join(t.TempDir(), "users.json")
+	data := "{\"_id\": 1, \"organization_id\": 101}\n{\"_id\": 2, \"shared\": \"no\"}\n"
+	assert.Nil(t, os.WriteFile(path, []byte(data), 0644))
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "tickets.json"), []byte("[]"), 0644))
+	cfg := config.Config{DataDir: dir, Users: path, Organizations: "internal/source_data/organizations.json"}
+	report, err = checkData(cfg, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, "record 1 field shared: json: cannot unmarshal string into Go struct field User.shared of type bool", report.Problems[0].Message)
+	buf.Reset()
+	assert.Equal(t, exitInvalid, writeValidation(&buf, display.FormatText, report))
+
+	// strict fails at the invalid record
+	_, err = checkData(cfg, true)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, os.WriteFile(path, []byte(data[:strings.Index(data, "\n")]), 0644))
+	report, err = checkData(cfg, false)
+	assert.Nil(t, err)
+	buf.Reset()
+	assert.Equal(t, exitValid, writeValidation(&buf, display.FormatText, report))
+	assert.Equal(t, "Checked 25 organizations, 0 tickets and 1 users: 0 errors, 0 warnings\n", buf.String())
+}
+
+func TestReloadData(t *testing.T) {
+	defer func() {
+		assert.Nil(t, loadData(config.Config{}))
+	}()
+	path := filepath.Join(t.TempDir(), "users.json")
+	assert.Nil(t, os.WriteFile(path, []byte(`[{"_id": 1, "name": "Francisca Rasmussen"}]`), 0644))
+	cfg := config.Config{Users: path}
+	assert.Nil(t, loadData(cfg))
+	inFlight := newSearch()
+
+	var buf bytes.Buffer
+	assert.Nil(t, os.WriteFile(path, []byte(`[{"_id": 1, "name": "Francisca Rasmussen"}, {"_id": 2, "name": "Cross Barlow"}]`), 0644))
+	assert.Nil(t, reloadData(cfg, &buf))
+	assert.Equal(t, "Reloaded 25 organizations, 200 tickets and 2 users\n", buf.String())
+	assert.Equal(t, exitFound, searchOnce("users", "_id", match.Exact, "2"))
+	// a search started before the reload keeps the data it started with
+	assert.Equal(t, 1, inFlight.Users.Len())
+
+	// a failed reload keeps the data searched
+	buf.Reset()
+	assert.Nil(t, os.WriteFile(path, []byte(`[{"_id": 1, "name": "Francisca`), 0644))
+	assert.NotNil(t, reloadData(cfg, &buf))
+	assert.True(t, strings.HasPrefix(buf.String(), "Reloading data failed, searching the previous data: loading users: "), buf.String())
+	assert.Equal(t, 2, currentData().users.Len())
+}
+
+func TestSchemaEntities(t *testing.T) {
+	defer func() {
+		assert.Nil(t, loadData(config.Config{}))
+	}()
+	cfg := config.Config{Schema: "internal/entity/test_files/schema.json"}
+	assert.Nil(t, loadData(cfg))
+	assert.Equal(t, 3, currentData().entities["macros"].Len())
+
+	// macros are the fifth group, following users, tickets, organizations and groups
+	var stdin bytes.Buffer
+	stdin.WriteString("1\n5\ngroup.name\n\nBilling\n3\ncsv\n\n1\n4\n_id\n\n1\nquit\n")
+	assert.Nil(t, process(bufio.NewScanner(&stdin)))
+
+	assert.Equal(t, exitFound, searchOnce("macros", "tags", match.Exact, "thanks"))
+	assert.Equal(t, exitNoResult, searchOnce("groups", "name", match.Exact, "Sales"))
+	assert.Equal(t, exitUsage, searchOnce("ratings", "_id", match.Exact, "1"))
+	assert.Equal(t, exitFound, queryOnce("macros", "active:true AND group.organization.name:Nutralab", match.Exact))
+	assert.Equal(t, "users, tickets, organizations, groups, macros or all", groupNames())
+
+	var buf bytes.Buffer
+	assert.Nil(t, reloadData(cfg, &buf))
+	assert.Equal(t, "Reloaded 25 organizations, 200 tickets, 75 users, 2 groups and 3 macros\n", buf.String())
+
+	assert.NotNil(t, loadData(config.Config{Schema: "internal/entity/test_files/invalid_schema.json"}))
+}
+
+func TestWatchData(t *testing.T) {
+	defer func() {
+		watchInterval = 0
+	}()
+	stop, err := watchData(config.Config{})
+	assert.Nil(t, err)
+	stop()
+
+	watchInterval = time.Millisecond
+	_, err = watchData(config.Config{Database: filepath.Join(t.TempDir(), "wordsearch.db")})
+	assert.NotNil(t, err)
+	stop, err = watchData(config.Config{})
+	assert.Nil(t, err)
+	stop()
+}
+
+func TestWorkersFlag(t *testing.T) {
+	defer func() {
+		scanWorkers = 0
+	}()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	workersFlag(fs)
+	assert.Nil(t, fs.Parse([]string{"-workers", "3"}))
+	assert.Equal(t, 3, newSearch().Workers)
+	assert.Equal(t, exitFound, searchOnce("users", "name", match.Substring, "francisca"))
+}
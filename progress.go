@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+)
+
+// progressDelay how long a load runs before its progress is shown, so loading small data files shows nothing
+const progressDelay = time.Second
+
+// progressInterval how often the progress of a load is updated
+const progressInterval = 200 * time.Millisecond
+
+// loadProgress shows the progress of loading a data file on a single line, once the load has run for progressDelay
+type loadProgress struct {
+	w     io.Writer
+	name  string
+	now   func() time.Time
+	start time.Time
+	last  time.Time
+	shown bool
+}
+
+// newLoadProgress starts timing the load of the named data file, the progress is written to w
+func newLoadProgress(w io.Writer, name string) *loadProgress {
+	return &loadProgress{w: w, name: name, now: time.Now, start: time.Now()}
+}
+
+// report writes the progress over the previous progress, at most once every progressInterval
+func (p *loadProgress) report(progress jsonstream.Progress) {
+	now := p.now()
+	if now.Sub(p.start) < progressDelay || now.Sub(p.last) < progressInterval {
+		return
+	}
+	p.last = now
+	p.shown = true
+	if progress.Size > 0 {
+		fmt.Fprintf(p.w, "\rLoading %s: %3.0f%% (%d records)", p.name, progress.Fraction()*100, progress.Records)
+	} else {
+		fmt.Fprintf(p.w, "\rLoading %s: %d records", p.name, progress.Records)
+	}
+}
+
+// done ends the progress line when progress was shown
+func (p *loadProgress) done() {
+	if p.shown {
+		fmt.Fprintln(p.w)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nicholas-boyson/wordsearch/internal/config"
+	"github.com/nicholas-boyson/wordsearch/internal/entity"
+	"github.com/nicholas-boyson/wordsearch/internal/sqlstore"
+	"github.com/nicholas-boyson/wordsearch/internal/store"
+	"github.com/nicholas-boyson/wordsearch/internal/watch"
+)
+
+// watchInterval how often the data files are checked for changes, 0 does not watch them
+var watchInterval time.Duration
+
+// dataset the stores searched, replaced as a whole when the data files are reloaded
+type dataset struct {
+	organizations store.Store
+	tickets       store.Store
+	users         store.Store
+	entities      map[string]store.Store // stores of the schema entities by entity name
+	db            *sqlstore.DB           // database the stores search, nil when the data is held in memory
+}
+
+// data holds the *dataset searched. A reload stores a new dataset rather than changing the stores, so a search in
+// flight keeps searching the stores it started with
+var data atomic.Value
+
+// currentData returns the data loaded last, a dataset without stores when nothing is loaded
+func currentData() *dataset {
+	if d, ok := data.Load().(*dataset); ok {
+		return d
+	}
+	return &dataset{}
+}
+
+// reloadData reads the data files again and swaps them in once they have all loaded, writing the outcome to w.
+// When the files fail to load the data searched is kept
+func reloadData(cfg config.Config, w io.Writer) error {
+	d, err := readData(cfg)
+	if err != nil {
+		fmt.Fprintf(w, "Reloading data failed, searching the previous data: %s\n", err)
+		return err
+	}
+	data.Store(d)
+	counts := []string{
+		fmt.Sprintf("%d organizations", d.organizations.Len()),
+		fmt.Sprintf("%d tickets", d.tickets.Len()),
+		fmt.Sprintf("%d users", d.users.Len()),
+	}
+	for _, t := range entity.Types() {
+		counts = append(counts, fmt.Sprintf("%d %s", d.entities[t.Name].Len(), t.Name))
+	}
+	fmt.Fprintf(w, "Reloaded %s and %s\n", strings.Join(counts[:len(counts)-1], ", "), counts[len(counts)-1])
+	return nil
+}
+
+// watchData reloads the data files whenever they change, once watchInterval is set. The returned func stops
+// watching. The data of a database is not reloaded as it is not read from the data files
+func watchData(cfg config.Config) (stop func(), err error) {
+	if watchInterval <= 0 {
+		return func() {}, nil
+	}
+	if cfg.Database != "" {
+		return nil, fmt.Errorf("--watch reloads the data files and cannot be used with --database")
+	}
+	paths := []string{cfg.OrganizationsPath(), cfg.TicketsPath(), cfg.UsersPath()}
+	for _, t := range entity.Types() {
+		paths = append(paths, t.File)
+	}
+	watcher := watch.New(paths, watchInterval, func() error {
+		return reloadData(cfg, os.Stderr)
+	})
+	watcher.Start()
+	return watcher.Stop, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+)
+
+// maxReportedErrors how many rejected records of a data file are listed in the load report
+const maxReportedErrors = 10
+
+// strictLoad stops loading at the first invalid record instead of skipping it, for validating data dumps
+var strictLoad bool
+
+// loadReport counts the records of a data file that were loaded and rejected
+type loadReport struct {
+	name     string
+	loaded   int
+	rejected int
+	errors   []jsonstream.RecordError
+}
+
+// options returns the options reading the data file, reporting progress to progress and skipping invalid records
+// unless strict
+func (r *loadReport) options(progress *loadProgress, strict bool) jsonstream.Options {
+	opts := jsonstream.Options{Progress: func(p jsonstream.Progress) {
+		r.loaded = p.Records
+		progress.report(p)
+	}}
+	if !strict {
+		opts.Reject = r.reject
+	}
+	return opts
+}
+
+// reject counts a rejected record, keeping the first maxReportedErrors to list
+func (r *loadReport) reject(err jsonstream.RecordError) {
+	r.rejected++
+	if len(r.errors) < maxReportedErrors {
+		r.errors = append(r.errors, err)
+	}
+}
+
+// writeLoadReport writes the loaded and rejected records of every data file to w, listing the rejected records with
+// their position and field
+func writeLoadReport(w io.Writer, reports ...*loadReport) {
+	for _, r := range reports {
+		fmt.Fprintf(w, "Loaded %s: %d loaded, %d rejected\n", r.name, r.loaded, r.rejected)
+		for _, err := range r.errors {
+			fmt.Fprintf(w, "  %s\n", err)
+		}
+		if more := r.rejected - len(r.errors); more > 0 {
+			fmt.Fprintf(w, "  and %d more\n", more)
+		}
+	}
+}
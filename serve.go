@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/nicholas-boyson/wordsearch/internal/server"
+)
+
+// serve runs the HTTP JSON API over the loaded data until the server stops, returning the process exit code
+func serve(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address the HTTP server listens on")
+	resolveConfig := dataFlags(fs)
+	watchFlag(fs)
+	workersFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	cfg := loadConfiguredData(resolveConfig)
+	defer startWatching(cfg)()
+	fmt.Printf("Serving search on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, server.NewLive(newSearch)); err != nil {
+		fmt.Fprintf(os.Stderr, "Server stopped: %s\n", err.Error())
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nicholas-boyson/wordsearch/internal/config"
+	"github.com/nicholas-boyson/wordsearch/internal/display"
+	"github.com/nicholas-boyson/wordsearch/internal/jsonstream"
+	"github.com/nicholas-boyson/wordsearch/internal/organizations"
+	"github.com/nicholas-boyson/wordsearch/internal/tickets"
+	"github.com/nicholas-boyson/wordsearch/internal/users"
+	"github.com/nicholas-boyson/wordsearch/internal/validate"
+)
+
+// exit codes of the validate command
+const (
+	exitValid   = 0
+	exitInvalid = 1
+)
+
+// validateData runs the validate command checking the data files for data quality problems, returning the process
+// exit code. The data files are read rather than a database so the data can be checked before it is imported
+func validateData(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	format := fs.String("format", display.FormatText, "report format: text or json")
+	resolveConfig := dataFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *format != display.FormatText && *format != display.FormatJSON {
+		fmt.Printf("Unknown format %q, expected text or json\n", *format)
+		return exitUsage
+	}
+	cfg, err := resolveConfig()
+	if err != nil {
+		fmt.Printf("Failed to read config: %s\n", err.Error())
+		return exitInvalid
+	}
+	report, err := checkData(cfg, strictLoad)
+	if err != nil {
+		fmt.Printf("Failed to load data: %s\n", err.Error())
+		return exitInvalid
+	}
+	return writeValidation(os.Stdout, *format, report)
+}
+
+// checkData reads the data files and checks them, records that cannot be read are reported as problems unless
+// strict, when the first of them fails the check
+func checkData(cfg config.Config, strict bool) (*validate.Report, error) {
+	report := &validate.Report{}
+	options := func(entity string) jsonstream.Options {
+		if strict {
+			return jsonstream.Options{}
+		}
+		return jsonstream.Options{Reject: report.Reject(entity)}
+	}
+	var orgList []organizations.Organization
+	err := organizations.StreamOrganizations(cfg.OrganizationsPath(), options("organizations"), func(org organizations.Organization) error {
+		orgList = append(orgList, org)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading organizations: %s", err)
+	}
+	var ticketList []tickets.Ticket
+	err = tickets.StreamTickets(cfg.TicketsPath(), options("tickets"), func(ticket tickets.Ticket) error {
+		ticketList = append(ticketList, ticket)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading tickets: %s", err)
+	}
+	var userList []users.User
+	err = users.StreamUsers(cfg.UsersPath(), options("users"), func(user users.User) error {
+		userList = append(userList, user)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading users: %s", err)
+	}
+	report.Check(orgList, ticketList, userList)
+	return report, nil
+}
+
+// writeValidation writes the report in the format, returning exitInvalid when the report holds errors
+func writeValidation(w io.Writer, format string, report *validate.Report) int {
+	write := report.WriteText
+	if format == display.FormatJSON {
+		write = report.WriteJSON
+	}
+	if err := write(w); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %s\n", err.Error())
+		return exitInvalid
+	}
+	if report.Failed() {
+		return exitInvalid
+	}
+	return exitValid
+}